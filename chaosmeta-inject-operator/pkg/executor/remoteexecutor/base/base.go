@@ -25,6 +25,8 @@ const (
 	SuccessStatus   RemoteExpStatus = "success"
 	ErrorStatus     RemoteExpStatus = "error"
 	DestroyedStatus RemoteExpStatus = "destroyed"
+	// RecoverVerifyFailedStatus the experiment is recovered, but the system is not the same as before inject
+	RecoverVerifyFailedStatus RemoteExpStatus = "recover_verify_failed"
 )
 
 func ConvertStatus(status RemoteExpStatus, phase v1alpha1.PhaseType) v1alpha1.StatusType {
@@ -37,7 +39,7 @@ func ConvertStatus(status RemoteExpStatus, phase v1alpha1.PhaseType) v1alpha1.St
 			return v1alpha1.SuccessStatusType
 		case ErrorStatus:
 			return v1alpha1.FailedStatusType
-		case DestroyedStatus:
+		case DestroyedStatus, RecoverVerifyFailedStatus:
 			return v1alpha1.SuccessStatusType
 		}
 	case v1alpha1.RecoverPhaseType:
@@ -46,6 +48,8 @@ func ConvertStatus(status RemoteExpStatus, phase v1alpha1.PhaseType) v1alpha1.St
 			return v1alpha1.SuccessStatusType
 		case DestroyedStatus:
 			return v1alpha1.SuccessStatusType
+		case RecoverVerifyFailedStatus:
+			return v1alpha1.FailedStatusType
 		}
 	}
 
@@ -64,6 +64,14 @@ func TestConvertStatus(t *testing.T) {
 			},
 			want: v1alpha1.SuccessStatusType,
 		},
+		{
+			name: "inject_recover_verify_failed",
+			args: args{
+				status: RecoverVerifyFailedStatus,
+				phase:  v1alpha1.InjectPhaseType,
+			},
+			want: v1alpha1.SuccessStatusType,
+		},
 		{
 			name: "recover_created",
 			args: args{
@@ -96,6 +104,14 @@ func TestConvertStatus(t *testing.T) {
 			},
 			want: v1alpha1.SuccessStatusType,
 		},
+		{
+			name: "recover_recover_verify_failed",
+			args: args{
+				status: RecoverVerifyFailedStatus,
+				phase:  v1alpha1.RecoverPhaseType,
+			},
+			want: v1alpha1.FailedStatusType,
+		},
 	}
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
@@ -47,6 +47,11 @@ MEM_FILL="chaosmeta_memfill"
 FD_FULL="chaosmeta_fd"
 NPROC="chaosmeta_nproc"
 NET_OCCUPY="chaosmeta_occupy"
+BLACK_HOLE="chaosmeta_blackhole"
+TC_SCHEDULE="chaosmeta_tcschedule"
+FILE_LOCK="chaosmeta_filelock"
+TCP_PROXY="chaosmeta_tcpproxy"
+GRPC_PROXY="chaosmeta_grpcproxy"
 JVM_AGENT="ChaosMetaJVMAgent"
 JVM_ATTACHER="ChaosMetaJVMAttacher"
 JVM_METHOD_RULE="ChaosMetaJVMMethodRule"
@@ -96,8 +101,13 @@ CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${DISK_BURN} ${PROJECT_DIR}/tools/${DISK_BURN}.go
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${MEM_FILL} ${PROJECT_DIR}/tools/${MEM_FILL}.go
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${NET_OCCUPY} ${PROJECT_DIR}/tools/${NET_OCCUPY}.go
+CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${BLACK_HOLE} ${PROJECT_DIR}/tools/${BLACK_HOLE}.go
+CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${TC_SCHEDULE} ${PROJECT_DIR}/tools/${TC_SCHEDULE}.go
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${FD_FULL} ${PROJECT_DIR}/tools/${FD_FULL}.go
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${NPROC} ${PROJECT_DIR}/tools/${NPROC}.go
+CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${FILE_LOCK} ${PROJECT_DIR}/tools/${FILE_LOCK}.go
+CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${TCP_PROXY} ${PROJECT_DIR}/tools/${TCP_PROXY}.go
+CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${GRPC_PROXY} ${PROJECT_DIR}/tools/${GRPC_PROXY}.go
 
 gcc ${EXEC_DIR}/execns/${TOOL_EXECNS}.c -o ${PACKAGE_DIR}/${OS_NAME}/tools/${TOOL_EXECNS}
 CGO_ENABLED=1 GOOS=${OS_NAME} GOARCH=${ARCH_NAME} ${GO_TOOL} build -o ${PACKAGE_DIR}/${OS_NAME}/tools/${DISK_EXEC} ${EXEC_DIR}/disk/${DISK_EXEC}.go
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package catalog
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+)
+
+// NewCatalogCommand print targets, faults and json schema of args of all registered injectors
+func NewCatalogCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "catalog",
+		Short: "print targets, faults and args json schema of all supported experiments",
+		Run: func(cmd *cobra.Command, args []string) {
+			ctx := utils.GetCtxWithTraceId(context.Background(), utils.TraceId)
+			catalog, err := injector.GetCatalog()
+			if err != nil {
+				errutil.SolveErr(ctx, errutil.InternalErr, fmt.Sprintf("get catalog error: %s", err.Error()))
+			}
+
+			reBytes, err := json.MarshalIndent(catalog, "", "  ")
+			if err != nil {
+				errutil.SolveErr(ctx, errutil.InternalErr, fmt.Sprintf("catalog change to string error: %s", err.Error()))
+			}
+
+			fmt.Println(string(reBytes))
+		},
+	}
+}
@@ -25,11 +25,14 @@ import (
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/diskio"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/dns"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/file"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/grpc"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/jvm"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/kernel"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/mem"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/middleware"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/network"
 	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/process"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
 )
 
 // NewInjectCommand injectCmd represents the inject command
@@ -45,10 +48,16 @@ func NewInjectCommand() *cobra.Command {
 	injectCmd.PersistentFlags().StringVarP(&args.Timeout, "timeout", "t", "", "experiment's duration, support unit: \"s、m、h\"(default s)")
 	injectCmd.PersistentFlags().StringVar(&args.Creator, "creator", "", "experiment's creator（default the cmd exec user）")
 
-	injectCmd.PersistentFlags().StringVar(&args.ContainerRuntime, "container-runtime", "", "if attack a container of local host, need to provide the container runtime of target container")
+	injectCmd.PersistentFlags().StringVar(&args.ContainerRuntime, "container-runtime", "", "if attack a container of local host, can provide the container runtime of target container（default detected by the existing runtime socket）")
 	injectCmd.PersistentFlags().StringVar(&args.ContainerId, "container-id", "", "if attack a container of local host, need to provide the container id of target container")
+	injectCmd.PersistentFlags().StringVar(&args.PodNamespace, "pod-namespace", "", "namespace of target pod（default \"default\"）")
+	injectCmd.PersistentFlags().StringVar(&args.PodName, "pod-name", "", "if attack a container of a kubernetes pod, can provide the pod name instead of \"container-id\"")
+	injectCmd.PersistentFlags().StringVar(&args.PodUid, "pod-uid", "", "if attack a container of a kubernetes pod, can provide the pod uid instead of \"pod-name\"")
+	injectCmd.PersistentFlags().StringVar(&args.ContainerName, "container-name", "", "name of target container in the pod, required if the pod has multiple containers")
 
 	injectCmd.PersistentFlags().StringVar(&args.Uid, "uid", "", "if not provide, it will automatically generate an uid")
+	injectCmd.PersistentFlags().BoolVar(&args.AllowOverlap, "allow-overlap", false, "inject even if the resources overlap with the running experiments, eg: the same cores、pids、paths")
+	output.AddFlag(injectCmd.PersistentFlags())
 	//var args = make([]string, 2)
 	//injectCmd.PersistentFlags().StringVarP(&args[0], "timeout", "t", "", "experiment's duration（default 0, means need to stop manually）")
 	//injectCmd.PersistentFlags().StringVar(&args[1], "creator", "", "experiment's creator（default the cmd exec user）")
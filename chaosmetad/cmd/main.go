@@ -19,12 +19,14 @@ package main
 import (
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/catalog"
 	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/inject"
 	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/query"
 	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/recover"
 	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/server"
 	"github.com/traas-stack/chaosmeta/chaosmetad/cmd/version"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/policy"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
 	"os"
@@ -40,7 +42,9 @@ func initRootCmd() {
 	rootCmd.PersistentFlags().StringVar(&log.Level, "log-level", "info", "value support: debug, info, warn, error")
 	rootCmd.PersistentFlags().StringVar(&log.Path, "log-path", "", "log file's path, eg: /tmp/chaosmetad.log")
 	rootCmd.PersistentFlags().StringVar(&utils.TraceId, "trace-id", "", "trace id")
+	rootCmd.PersistentFlags().StringVar(&policy.Path, "policy-path", "", "safety policy file's path（default chaosmetad_policy.json in the run path）")
 
+	rootCmd.AddCommand(catalog.NewCatalogCommand())
 	rootCmd.AddCommand(inject.NewInjectCommand())
 	rootCmd.AddCommand(query.NewQueryCommand())
 	rootCmd.AddCommand(recover.NewRecoverCommand())
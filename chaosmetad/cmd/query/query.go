@@ -20,6 +20,7 @@ import (
 	"context"
 	"fmt"
 	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/query"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 )
@@ -36,7 +37,9 @@ func NewQueryCommand() *cobra.Command {
 		Use:   "query",
 		Short: "experiment query command",
 		Run: func(cmd *cobra.Command, args []string) {
-			query.PrintExpByOption(utils.GetCtxWithTraceId(context.Background(), utils.TraceId), optionQuery, ifAll, format)
+			ctx := utils.GetCtxWithTraceId(context.Background(), utils.TraceId)
+			output.Init(ctx)
+			query.PrintExpByOption(ctx, optionQuery, ifAll, format)
 		},
 	}
 
@@ -49,6 +52,7 @@ func NewQueryCommand() *cobra.Command {
 	queryCmd.Flags().UintVarP(&optionQuery.Limit, "limit", "l", 10, "query experiment records with limit, eg: chaosmetad query -o 5 -l 5")
 	queryCmd.Flags().BoolVarP(&ifAll, "all", "a", false, "if show all")
 	queryCmd.Flags().StringVar(&format, "format", query.TableFormat, fmt.Sprintf("data show format, support: %s(default), %s", query.TableFormat, query.JsonFormat))
+	output.AddFlag(queryCmd.Flags())
 
 	return queryCmd
 }
@@ -21,6 +21,7 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
 )
@@ -32,14 +33,17 @@ func NewRecoverCommand() *cobra.Command {
 		Long:  "experiment recover command, usage: recover [uid]",
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := utils.GetCtxWithTraceId(context.Background(), utils.TraceId)
+			output.Init(ctx)
 			if len(args) != 1 {
-				errutil.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("please add target experiment's uid, eg: recover [uid]"))
+				output.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("please add target experiment's uid, eg: recover [uid]"))
 			}
 
 			code, msg := injector.ProcessRecover(ctx, args[0])
-			errutil.SolveErr(ctx, code, msg)
+			output.Solve(ctx, output.NewExpResult(ctx, code, msg, args[0]))
 		},
 	}
 
+	output.AddFlag(recoverCmd.Flags())
+
 	return recoverCmd
 }
@@ -18,7 +18,10 @@ package server
 
 import (
 	"context"
+	"crypto/tls"
+	"crypto/x509"
 	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
@@ -28,10 +31,14 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/spf13/cobra"
 )
 
+const experimentWatchInterval = time.Second
+
 func watchSignal(ctx context.Context) {
 	logger := log.GetLogger(ctx)
 	c := make(chan os.Signal)
@@ -53,51 +60,97 @@ func watchSignal(ctx context.Context) {
 // NewServerCommand serverCmd represents the server command
 func NewServerCommand() *cobra.Command {
 	var addr, port string
-	//var cert, key string
+	var cert, key, ca string
 	var isPprof bool
+	var authConf = &web.AuthConfig{}
 	cmd := &cobra.Command{
 		Use:   "server",
 		Short: "start up daemon service",
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := utils.GetCtxWithTraceId(context.Background(), "system")
 			go watchSignal(ctx)
+			go injector.WatchExperiment(ctx, experimentWatchInterval)
+			prometheus.MustRegister(injector.NewExperimentCollector(ctx))
+
+			if !authConf.IsEnabled() {
+				log.GetLogger(ctx).Warnf("no token is configured, authentication is disabled")
+			}
 
-			//if cert != "" && key != "" {
-			//	startHTTPSServer(addr, port, isPprof, cert, key)
-			//} else {
-			startHTTPService(ctx, addr, port, isPprof)
-			//}
+			if cert != "" || key != "" {
+				startHTTPSService(ctx, addr, port, isPprof, authConf, cert, key, ca)
+			} else {
+				if ca != "" {
+					errutil.SolveErr(ctx, errutil.BadArgsErr, "\"ca\" must be used with \"cert\" and \"key\"")
+				}
+				startHTTPService(ctx, addr, port, isPprof, authConf)
+			}
 		},
 	}
 
 	cmd.Flags().StringVarP(&addr, "addr", "a", "0.0.0.0", "service bind addr")
 	cmd.Flags().StringVarP(&port, "port", "p", "29595", "service bind port")
-	cmd.Flags().BoolVar(&isPprof, "enable-pprof", true, "if open pprof service")
-	//cmd.Flags().StringVarP(&cert, "cert", "c", "", "path to certificate file")
-	//cmd.Flags().StringVarP(&key, "key", "k", "", "path to private key file")
-	// HTTPS
-	//      --CA string            path to a PEM encoded CA's certificate file
-	//      --cert string          path to a PEM encoded certificate file
-	//   	--key string           path to a PEM encoded private key file
+	cmd.Flags().BoolVar(&isPprof, "enable-pprof", false, "if open pprof service")
+	cmd.Flags().StringVarP(&cert, "cert", "c", "", "path to a PEM encoded certificate file, provide with \"key\" to enable https")
+	cmd.Flags().StringVarP(&key, "key", "k", "", "path to a PEM encoded private key file, provide with \"cert\" to enable https")
+	cmd.Flags().StringVar(&ca, "ca", "", "path to a PEM encoded CA's certificate file, if provided, client certificate signed by it is required(mTLS)")
+	cmd.Flags().StringVar(&authConf.Token, "token", "", "bearer token with full permission, if token and read-only-token are both empty, authentication is disabled")
+	cmd.Flags().StringVar(&authConf.ReadOnlyToken, "read-only-token", "", "bearer token which can only access read apis, eg: query, version")
 	return cmd
 }
 
-func startHTTPService(ctx context.Context, addr string, port string, isPprof bool) {
+func startHTTPService(ctx context.Context, addr string, port string, isPprof bool, authConf *web.AuthConfig) {
 	logger := log.GetLogger(ctx)
-	logger.Infof("HTTP Service Listen on %s:%s, pprof: %t", addr, port, isPprof)
-	router := web.NewRouter(ctx, isPprof)
+	logger.Infof("HTTP Service Listen on %s:%s, pprof: %t, auth: %t", addr, port, isPprof, authConf.IsEnabled())
+	router := web.NewRouter(ctx, isPprof, authConf)
 
 	if err := http.ListenAndServe(fmt.Sprintf("%s:%s", addr, port), router); err != nil {
 		logger.Fatalf("start http service fail: %s", err.Error())
 	}
 }
 
-//func startHTTPSServer(addr string, port string, isPprof bool, cert, key string) {
-//	logger := tools.GetLogger()
-//	logger.Infof("HTTPS Service Listen on %s:%s, pprof: %t, cert: %s, key: %s", addr, port, isPprof, cert, key)
-//	router := web.NewRouter(isPprof)
-//
-//	if err := http.ListenAndServeTLS(fmt.Sprintf("%s:%s", addr, port), cert, key, router); err != nil {
-//		logger.Fatalf("start https service fail: %s", err.Error())
-//	}
-//}
+func startHTTPSService(ctx context.Context, addr string, port string, isPprof bool, authConf *web.AuthConfig, cert, key, ca string) {
+	logger := log.GetLogger(ctx)
+	if cert == "" || key == "" {
+		logger.Fatalf("\"cert\" and \"key\" must be provided together")
+	}
+
+	tlsConf, err := getTLSConfig(ca)
+	if err != nil {
+		logger.Fatalf("get tls config fail: %s", err.Error())
+	}
+
+	logger.Infof("HTTPS Service Listen on %s:%s, pprof: %t, auth: %t, cert: %s, key: %s, ca: %s", addr, port, isPprof, authConf.IsEnabled(), cert, key, ca)
+	server := &http.Server{
+		Addr:      fmt.Sprintf("%s:%s", addr, port),
+		Handler:   web.NewRouter(ctx, isPprof, authConf),
+		TLSConfig: tlsConf,
+	}
+
+	if err := server.ListenAndServeTLS(cert, key); err != nil {
+		logger.Fatalf("start https service fail: %s", err.Error())
+	}
+}
+
+func getTLSConfig(ca string) (*tls.Config, error) {
+	tlsConf := &tls.Config{
+		MinVersion: tls.VersionTLS12,
+	}
+
+	if ca == "" {
+		return tlsConf, nil
+	}
+
+	caBytes, err := os.ReadFile(ca)
+	if err != nil {
+		return nil, fmt.Errorf("read ca file[%s] error: %s", ca, err.Error())
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caBytes) {
+		return nil, fmt.Errorf("no valid certificate in ca file[%s]", ca)
+	}
+
+	tlsConf.ClientCAs = pool
+	tlsConf.ClientAuth = tls.RequireAndVerifyClientCert
+	return tlsConf, nil
+}
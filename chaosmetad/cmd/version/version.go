@@ -19,16 +19,31 @@ package version
 import (
 	"context"
 	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/version"
 )
 
 func NewVersionCommand() *cobra.Command {
-	return &cobra.Command{
+	versionCmd := &cobra.Command{
 		Use:   "version",
 		Short: "description of version",
 		Run: func(cmd *cobra.Command, args []string) {
-			version.PrintVersion(utils.GetCtxWithTraceId(context.Background(), utils.TraceId))
+			ctx := utils.GetCtxWithTraceId(context.Background(), utils.TraceId)
+			output.Init(ctx)
+			if output.IsJson() {
+				output.Solve(ctx, &output.Result{
+					Code:    errutil.NoErr,
+					Message: "success",
+					Data:    version.GetVersion(),
+				})
+			}
+
+			version.PrintVersion(ctx)
 		},
 	}
+
+	output.AddFlag(versionCmd.Flags())
+	return versionCmd
 }
@@ -16,7 +16,51 @@
 
 package base
 
+import "math"
+
+const (
+	// Unlimited in Resources means remove the limit when update
+	Unlimited = -1
+	// MaxMemoryLimit is the max page aligned memory limit, which is unlimited for the kernel
+	MaxMemoryLimit = math.MaxInt64 &^ 4095
+)
+
+// ContainerInfo is the brief information of a running container
+type ContainerInfo struct {
+	Id     string
+	Labels map[string]string
+}
+
 type SimpleProcess struct {
 	Pid  int
 	Cmd  string
 }
+
+// Resources is the resource limits of container, 0 means unchanged and Unlimited means remove the limit when update
+type Resources struct {
+	CpuPeriod  int64 `json:"cpu_period,omitempty"`
+	CpuQuota   int64 `json:"cpu_quota,omitempty"`
+	CpuShares  int64 `json:"cpu_shares,omitempty"`
+	Memory     int64 `json:"memory,omitempty"`
+	MemorySwap int64 `json:"memory_swap,omitempty"`
+}
+
+// GetDaemonMemoryLimit docker and pouch daemon treat memory 0 as unchanged and reject -1, so unlimited is MaxMemoryLimit for them
+func GetDaemonMemoryLimit(limit int64) int64 {
+	if limit == Unlimited {
+		return MaxMemoryLimit
+	}
+
+	return limit
+}
+
+// MatchLabels check if all the labels in target exist in labels with the same value
+func MatchLabels(labels, target map[string]string) bool {
+	for k, v := range target {
+		if value, ok := labels[k]; !ok || value != v {
+			return false
+		}
+	}
+
+	return true
+}
@@ -43,6 +43,9 @@ type Client interface {
 	CpFile(ctx context.Context, containerID, src, dst string) error
 	Exec(ctx context.Context, containerID, cmd string) (string, error)
 	GetAllPidList(ctx context.Context, containerID string) ([]base.SimpleProcess, error)
+	GetResourcesById(ctx context.Context, containerID string) (*base.Resources, error)
+	UpdateResourcesById(ctx context.Context, containerID string, resources *base.Resources) error
+	ListByLabels(ctx context.Context, labels map[string]string) ([]base.ContainerInfo, error)
 }
 
 func GetClient(ctx context.Context, cr string) (Client, error) {
@@ -28,6 +28,7 @@ import (
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/base"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
 	"os"
+	"strings"
 	"sync"
 	"syscall"
 	"time"
@@ -51,6 +52,11 @@ var (
 	mutex          sync.Mutex
 )
 
+// GetSocketPath return the path of the socket file used by client
+func GetSocketPath() string {
+	return defaultSocket
+}
+
 func GetClient(ctx context.Context) (d *Client, err error) {
 	defer func() {
 		if e := recover(); e != any(nil) {
@@ -256,6 +262,34 @@ func (d *Client) ListId(ctx context.Context) ([]string, error) {
 	return idList, nil
 }
 
+func (d *Client) ListByLabels(ctx context.Context, labels map[string]string) ([]base.ContainerInfo, error) {
+	var conditions []string
+	for k, v := range labels {
+		conditions = append(conditions, fmt.Sprintf("labels.%q==%q", k, v))
+	}
+
+	containerList, err := d.client.Containers(ctx, strings.Join(conditions, ","))
+	if err != nil {
+		return nil, fmt.Errorf("get container list error: %s", err.Error())
+	}
+
+	var re []base.ContainerInfo
+	for _, c := range containerList {
+		l, err := c.Labels(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get labels of container[%s] error: %s", c.ID(), err.Error())
+		}
+
+		if _, err := d.GetPidById(ctx, c.ID()); err != nil {
+			continue
+		}
+
+		re = append(re, base.ContainerInfo{Id: c.ID(), Labels: l})
+	}
+
+	return re, nil
+}
+
 func (d *Client) CpFile(ctx context.Context, containerID, src, dst string) error {
 	task, err := d.getContainerTask(ctx, containerID)
 	if err != nil {
@@ -310,3 +344,84 @@ func (d *Client) CpFile(ctx context.Context, containerID, src, dst string) error
 
 	return nil
 }
+
+// GetResourcesById get the resource limits from the spec of container
+func (d *Client) GetResourcesById(ctx context.Context, containerID string) (*base.Resources, error) {
+	container, err := d.client.LoadContainer(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("load container error: %s", err.Error())
+	}
+
+	spec, err := container.Spec(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("get spec of container error: %s", err.Error())
+	}
+
+	var re = &base.Resources{}
+	if spec.Linux == nil || spec.Linux.Resources == nil {
+		return re, nil
+	}
+
+	if cpu := spec.Linux.Resources.CPU; cpu != nil {
+		if cpu.Period != nil {
+			re.CpuPeriod = int64(*cpu.Period)
+		}
+		if cpu.Quota != nil {
+			re.CpuQuota = *cpu.Quota
+		}
+		if cpu.Shares != nil {
+			re.CpuShares = int64(*cpu.Shares)
+		}
+	}
+
+	if mem := spec.Linux.Resources.Memory; mem != nil {
+		if mem.Limit != nil {
+			re.Memory = *mem.Limit
+		}
+		if mem.Swap != nil {
+			re.MemorySwap = *mem.Swap
+		}
+	}
+
+	return re, nil
+}
+
+func (d *Client) UpdateResourcesById(ctx context.Context, containerID string, resources *base.Resources) error {
+	task, err := d.getContainerTask(ctx, containerID)
+	if err != nil {
+		return fmt.Errorf("get task of container error: %s", err.Error())
+	}
+
+	return task.Update(ctx, containerd.WithResources(getLinuxResources(resources)))
+}
+
+// getLinuxResources the limits of spec accept -1 as unlimited, which is written to cgroup by runc
+func getLinuxResources(resources *base.Resources) *specs.LinuxResources {
+	var r = &specs.LinuxResources{}
+	if resources.CpuPeriod != 0 || resources.CpuQuota != 0 || resources.CpuShares != 0 {
+		r.CPU = &specs.LinuxCPU{}
+		if resources.CpuPeriod != 0 {
+			period := uint64(resources.CpuPeriod)
+			r.CPU.Period = &period
+		}
+		if resources.CpuQuota != 0 {
+			r.CPU.Quota = &resources.CpuQuota
+		}
+		if resources.CpuShares != 0 {
+			shares := uint64(resources.CpuShares)
+			r.CPU.Shares = &shares
+		}
+	}
+
+	if resources.Memory != 0 || resources.MemorySwap != 0 {
+		r.Memory = &specs.LinuxMemory{}
+		if resources.Memory != 0 {
+			r.Memory.Limit = &resources.Memory
+		}
+		if resources.MemorySwap != 0 {
+			r.Memory.Swap = &resources.MemorySwap
+		}
+	}
+
+	return r
+}
@@ -0,0 +1,37 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package containerd
+
+import (
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/base"
+	"testing"
+)
+
+func TestGetLinuxResources(t *testing.T) {
+	r := getLinuxResources(&base.Resources{CpuQuota: base.Unlimited, Memory: base.Unlimited})
+	if r.CPU == nil || r.CPU.Quota == nil || *r.CPU.Quota != -1 || r.CPU.Shares != nil {
+		t.Errorf("expect cpu quota -1 only, get %+v", r.CPU)
+	}
+
+	if r.Memory == nil || r.Memory.Limit == nil || *r.Memory.Limit != -1 || r.Memory.Swap != nil {
+		t.Errorf("expect memory limit -1 only, get %+v", r.Memory)
+	}
+
+	if r := getLinuxResources(&base.Resources{CpuShares: 512}); r.Memory != nil || r.CPU == nil || *r.CPU.Shares != 512 {
+		t.Errorf("expect cpu shares 512 only, get %+v", r)
+	}
+}
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crclient
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/containerd"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/docker"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/pouch"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"os"
+	"strings"
+)
+
+const (
+	LabelPodName         = "io.kubernetes.pod.name"
+	LabelPodNamespace    = "io.kubernetes.pod.namespace"
+	LabelPodUid          = "io.kubernetes.pod.uid"
+	LabelContainerName   = "io.kubernetes.container.name"
+	LabelCriKind         = "io.cri-containerd.kind"
+	CriKindSandbox       = "sandbox"
+	SandboxContainerName = "POD"
+	DefaultPodNamespace  = "default"
+)
+
+// runtimeList is the order to detect runtime, containerd is first because docker's containers are also in containerd
+var runtimeList = []string{CrContainerd, CrDocker, CrPouch}
+
+// PodSelector select the container of a kubernetes pod, Uid has higher priority than Namespace and Name
+type PodSelector struct {
+	Namespace     string
+	Name          string
+	Uid           string
+	ContainerName string
+}
+
+func (s *PodSelector) String() string {
+	if s.Uid != "" {
+		return fmt.Sprintf("uid[%s]", s.Uid)
+	}
+
+	return fmt.Sprintf("%s/%s", s.Namespace, s.Name)
+}
+
+func (s *PodSelector) getLabels() map[string]string {
+	var labels = make(map[string]string)
+	if s.Uid != "" {
+		labels[LabelPodUid] = s.Uid
+	} else {
+		labels[LabelPodNamespace] = s.Namespace
+		labels[LabelPodName] = s.Name
+	}
+
+	if s.ContainerName != "" {
+		labels[LabelContainerName] = s.ContainerName
+	}
+
+	return labels
+}
+
+func GetSocketPath(cr string) string {
+	switch cr {
+	case CrDocker:
+		return docker.GetSocketPath()
+	case CrContainerd:
+		return containerd.GetSocketPath()
+	case CrPouch:
+		return pouch.GetSocketPath()
+	default:
+		return ""
+	}
+}
+
+// GetExistRuntimeList return the runtimes whose socket exists on the node
+func GetExistRuntimeList() []string {
+	var re []string
+	for _, cr := range runtimeList {
+		if _, err := os.Stat(GetSocketPath(cr)); err == nil {
+			re = append(re, cr)
+		}
+	}
+
+	return re
+}
+
+// getSearchRuntimeList return the runtimes to search, only cr is searched if provided
+func getSearchRuntimeList(cr string) ([]string, error) {
+	existList := GetExistRuntimeList()
+	if cr == "" {
+		if len(existList) == 0 {
+			var sockets []string
+			for _, unit := range runtimeList {
+				sockets = append(sockets, fmt.Sprintf("%s(%s)", unit, GetSocketPath(unit)))
+			}
+			return nil, fmt.Errorf("no container runtime socket found on the node, checked: %s", strings.Join(sockets, ", "))
+		}
+
+		return existList, nil
+	}
+
+	socket := GetSocketPath(cr)
+	if socket == "" {
+		return nil, fmt.Errorf("not support container runtime: %s", cr)
+	}
+
+	if _, err := os.Stat(socket); err != nil {
+		return nil, fmt.Errorf("socket of container runtime[%s] is not found: %s, runtimes found on the node: %v", cr, socket, existList)
+	}
+
+	return []string{cr}, nil
+}
+
+// FindRuntimeById return the runtime which the container belongs to
+func FindRuntimeById(ctx context.Context, containerID string) (string, error) {
+	crList, err := getSearchRuntimeList("")
+	if err != nil {
+		return "", err
+	}
+
+	logger := log.GetLogger(ctx)
+	for _, cr := range crList {
+		client, err := GetClient(ctx, cr)
+		if err != nil {
+			logger.Warnf("create %s client error: %s", cr, err.Error())
+			continue
+		}
+
+		if _, err := client.GetPidById(ctx, containerID); err == nil {
+			return cr, nil
+		}
+	}
+
+	return "", fmt.Errorf("container[%s] is not found in runtimes: %v", containerID, crList)
+}
+
+// FindContainerByPod return the runtime and the container id of the pod's container.
+// if cr is provided, only search in cr; the sandbox container is ignored
+func FindContainerByPod(ctx context.Context, cr string, selector *PodSelector) (string, string, error) {
+	crList, err := getSearchRuntimeList(cr)
+	if err != nil {
+		return "", "", err
+	}
+
+	logger := log.GetLogger(ctx)
+	for _, unitCr := range crList {
+		client, err := GetClient(ctx, unitCr)
+		if err != nil {
+			logger.Warnf("create %s client error: %s", unitCr, err.Error())
+			continue
+		}
+
+		containerList, err := client.ListByLabels(ctx, selector.getLabels())
+		if err != nil {
+			logger.Warnf("list containers of pod[%s] in %s error: %s", selector, unitCr, err.Error())
+			continue
+		}
+
+		var idList, nameList []string
+		for _, c := range containerList {
+			name := c.Labels[LabelContainerName]
+			if name == "" || name == SandboxContainerName || c.Labels[LabelCriKind] == CriKindSandbox {
+				continue
+			}
+
+			idList, nameList = append(idList, c.Id), append(nameList, name)
+		}
+
+		if len(idList) == 0 {
+			logger.Debugf("container of pod[%s] is not found in %s", selector, unitCr)
+			continue
+		}
+
+		if len(idList) > 1 {
+			return "", "", fmt.Errorf("pod[%s] has multiple containers: %v, please provide \"container-name\"", selector, nameList)
+		}
+
+		logger.Debugf("container of pod[%s]: %s/%s", selector, unitCr, idList[0])
+		return unitCr, idList[0], nil
+	}
+
+	if selector.ContainerName != "" {
+		return "", "", fmt.Errorf("container[%s] of pod[%s] is not found in runtimes: %v", selector.ContainerName, selector, crList)
+	}
+
+	return "", "", fmt.Errorf("container of pod[%s] is not found in runtimes: %v", selector, crList)
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package crclient
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestPodSelector_getLabels(t *testing.T) {
+	tests := []struct {
+		name     string
+		selector *PodSelector
+		want     map[string]string
+	}{
+		{
+			name:     "namespace and name",
+			selector: &PodSelector{Namespace: "default", Name: "nginx-0"},
+			want:     map[string]string{LabelPodNamespace: "default", LabelPodName: "nginx-0"},
+		},
+		{
+			name:     "uid first",
+			selector: &PodSelector{Namespace: "default", Name: "nginx-0", Uid: "6f1a", ContainerName: "nginx"},
+			want:     map[string]string{LabelPodUid: "6f1a", LabelContainerName: "nginx"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.selector.getLabels(); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getLabels() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_getSearchRuntimeList(t *testing.T) {
+	if _, err := getSearchRuntimeList("rkt"); err == nil {
+		t.Errorf("getSearchRuntimeList() expect error for not supported runtime")
+	}
+}
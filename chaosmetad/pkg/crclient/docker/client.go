@@ -20,6 +20,8 @@ import (
 	"context"
 	"fmt"
 	"github.com/docker/docker/api/types"
+	"github.com/docker/docker/api/types/container"
+	"github.com/docker/docker/api/types/filters"
 	dockerClient "github.com/docker/docker/client"
 	"github.com/docker/docker/pkg/archive"
 	"github.com/docker/docker/pkg/system"
@@ -30,6 +32,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 )
@@ -49,6 +52,11 @@ var (
 	mutex          sync.Mutex
 )
 
+// GetSocketPath return the path of the socket file used by client
+func GetSocketPath() string {
+	return strings.TrimPrefix(defaultSocket, "unix://")
+}
+
 func GetClient(ctx context.Context) (d *Client, err error) {
 	defer func() {
 		if e := recover(); e != any(nil) {
@@ -205,6 +213,25 @@ func (d *Client) ListId(ctx context.Context) ([]string, error) {
 	return idList, nil
 }
 
+func (d *Client) ListByLabels(ctx context.Context, labels map[string]string) ([]base.ContainerInfo, error) {
+	args := filters.NewArgs()
+	for k, v := range labels {
+		args.Add("label", fmt.Sprintf("%s=%s", k, v))
+	}
+
+	containerList, err := d.client.ContainerList(ctx, types.ContainerListOptions{Filters: args})
+	if err != nil {
+		return nil, fmt.Errorf("get container list error: %s", err.Error())
+	}
+
+	var re = make([]base.ContainerInfo, len(containerList))
+	for i, c := range containerList {
+		re[i] = base.ContainerInfo{Id: c.ID, Labels: c.Labels}
+	}
+
+	return re, nil
+}
+
 func (d *Client) CpFile(ctx context.Context, containerID, src, dst string) error {
 	dstInfo := archive.CopyInfo{Path: dst}
 	dstStat, err := d.client.ContainerStatPath(ctx, containerID, dst)
@@ -254,3 +281,38 @@ func (d *Client) CpFile(ctx context.Context, containerID, src, dst string) error
 		AllowOverwriteDirWithFile: true,
 	})
 }
+
+func (d *Client) GetResourcesById(ctx context.Context, containerID string) (*base.Resources, error) {
+	info, err := d.client.ContainerInspect(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("get meta data of container[%s] error: %s", containerID, err.Error())
+	}
+
+	r := info.HostConfig.Resources
+	return &base.Resources{
+		CpuPeriod:  r.CPUPeriod,
+		CpuQuota:   r.CPUQuota,
+		CpuShares:  r.CPUShares,
+		Memory:     r.Memory,
+		MemorySwap: r.MemorySwap,
+	}, nil
+}
+
+func (d *Client) UpdateResourcesById(ctx context.Context, containerID string, resources *base.Resources) error {
+	_, err := d.client.ContainerUpdate(ctx, containerID, container.UpdateConfig{
+		Resources: getUpdateResources(resources),
+	})
+
+	return err
+}
+
+// getUpdateResources cpu quota and memory swap accept -1 as unlimited, but memory does not
+func getUpdateResources(resources *base.Resources) container.Resources {
+	return container.Resources{
+		CPUPeriod:  resources.CpuPeriod,
+		CPUQuota:   resources.CpuQuota,
+		CPUShares:  resources.CpuShares,
+		Memory:     base.GetDaemonMemoryLimit(resources.Memory),
+		MemorySwap: resources.MemorySwap,
+	}
+}
@@ -30,6 +30,7 @@ import (
 	"os"
 	"path/filepath"
 	"strconv"
+	"strings"
 	"sync"
 )
 
@@ -46,6 +47,11 @@ var (
 	mutex          sync.Mutex
 )
 
+// GetSocketPath return the path of the socket file used by client
+func GetSocketPath() string {
+	return strings.TrimPrefix(defaultSocket, "unix://")
+}
+
 func GetClient(ctx context.Context) (d *Client, err error) {
 	defer func() {
 		if e := recover(); e != any(nil) {
@@ -181,6 +187,22 @@ func (d *Client) ListId(ctx context.Context) ([]string, error) {
 	return idList, nil
 }
 
+func (d *Client) ListByLabels(ctx context.Context, labels map[string]string) ([]base.ContainerInfo, error) {
+	containerList, err := d.client.ContainerList(ctx, types.ContainerListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("get container list error: %s", err.Error())
+	}
+
+	var re []base.ContainerInfo
+	for _, c := range containerList {
+		if base.MatchLabels(c.Labels, labels) {
+			re = append(re, base.ContainerInfo{Id: c.ID, Labels: c.Labels})
+		}
+	}
+
+	return re, nil
+}
+
 func (d *Client) UnPauseContainerById(ctx context.Context, containerID string) error {
 	return d.client.ContainerUnpause(ctx, containerID)
 }
@@ -241,3 +263,36 @@ func (d *Client) CpFile(ctx context.Context, containerID, src, dst string) error
 
 	return d.client.CopyToContainer(ctx, containerID, resolvedDstPath, content)
 }
+
+func (d *Client) GetResourcesById(ctx context.Context, containerID string) (*base.Resources, error) {
+	info, err := d.client.ContainerGet(ctx, containerID)
+	if err != nil {
+		return nil, fmt.Errorf("get meta data of container[%s] error: %s", containerID, err.Error())
+	}
+
+	r := info.HostConfig.Resources
+	return &base.Resources{
+		CpuPeriod:  r.CPUPeriod,
+		CpuQuota:   r.CPUQuota,
+		CpuShares:  r.CPUShares,
+		Memory:     r.Memory,
+		MemorySwap: r.MemorySwap,
+	}, nil
+}
+
+func (d *Client) UpdateResourcesById(ctx context.Context, containerID string, resources *base.Resources) error {
+	return d.client.ContainerUpdate(ctx, containerID, &types.UpdateConfig{
+		Resources: getUpdateResources(resources),
+	})
+}
+
+// getUpdateResources cpu quota and memory swap accept -1 as unlimited, but memory does not
+func getUpdateResources(resources *base.Resources) types.Resources {
+	return types.Resources{
+		CPUPeriod:  resources.CpuPeriod,
+		CPUQuota:   resources.CpuQuota,
+		CPUShares:  resources.CpuShares,
+		Memory:     base.GetDaemonMemoryLimit(resources.Memory),
+		MemorySwap: resources.MemorySwap,
+	}
+}
@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package pouch
+
+import (
+	"github.com/alibaba/pouch/apis/types"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/base"
+	"reflect"
+	"testing"
+)
+
+func TestGetUpdateResources(t *testing.T) {
+	tests := []struct {
+		name      string
+		resources *base.Resources
+		want      types.Resources
+	}{
+		{
+			name:      "limit",
+			resources: &base.Resources{CpuQuota: 50000, Memory: 1 << 30},
+			want:      types.Resources{CPUQuota: 50000, Memory: 1 << 30},
+		},
+		{
+			name:      "unlimited",
+			resources: &base.Resources{CpuQuota: base.Unlimited, Memory: base.Unlimited, MemorySwap: base.Unlimited},
+			want:      types.Resources{CPUQuota: -1, Memory: base.MaxMemoryLimit, MemorySwap: -1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := getUpdateResources(tt.resources); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("getUpdateResources() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
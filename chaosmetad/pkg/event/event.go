@@ -0,0 +1,188 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"sync"
+	"time"
+)
+
+const (
+	TypeStatus        = "status"
+	TypeProgress      = "progress"
+	TypeRecoverFailed = "recover_failed"
+
+	subscriberBufferSize = 128
+	// status records of finished experiments are kept for a while to drop the same status reported by storage watcher
+	statusRecordKeepTime = time.Hour
+)
+
+type Event struct {
+	Type    string `json:"type"`
+	Uid     string `json:"uid"`
+	Target  string `json:"target,omitempty"`
+	Fault   string `json:"fault,omitempty"`
+	Status  string `json:"status,omitempty"`
+	Message string `json:"message,omitempty"`
+	Time    string `json:"time"`
+}
+
+// Filter empty field means no limit
+type Filter struct {
+	Uid    string
+	Target string
+	Fault  string
+}
+
+func (f *Filter) match(e *Event) bool {
+	if f == nil {
+		return true
+	}
+
+	return (f.Uid == "" || f.Uid == e.Uid) &&
+		(f.Target == "" || f.Target == e.Target) &&
+		(f.Fault == "" || f.Fault == e.Fault)
+}
+
+type subscriber struct {
+	filter *Filter
+	ch     chan *Event
+}
+
+type statusRecord struct {
+	target, fault, status string
+	updateTime            time.Time
+}
+
+type Bus struct {
+	mutex       sync.RWMutex
+	nextId      int
+	subscribers map[int]*subscriber
+	statuses    map[string]*statusRecord
+}
+
+var globalBus = NewBus()
+
+func NewBus() *Bus {
+	return &Bus{
+		subscribers: make(map[int]*subscriber),
+		statuses:    make(map[string]*statusRecord),
+	}
+}
+
+func GetBus() *Bus {
+	return globalBus
+}
+
+// Subscribe return an event channel and a cancel function, the channel is closed after cancel
+func (b *Bus) Subscribe(filter *Filter) (<-chan *Event, func()) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	id := b.nextId
+	b.nextId++
+	sub := &subscriber{
+		filter: filter,
+		ch:     make(chan *Event, subscriberBufferSize),
+	}
+	b.subscribers[id] = sub
+
+	var once sync.Once
+	return sub.ch, func() {
+		once.Do(func() {
+			b.mutex.Lock()
+			delete(b.subscribers, id)
+			b.mutex.Unlock()
+			close(sub.ch)
+		})
+	}
+}
+
+func (b *Bus) HasSubscriber() bool {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	return len(b.subscribers) > 0
+}
+
+// Publish never blocks, a subscriber whose buffer is full will miss the event
+func (b *Bus) Publish(e *Event) {
+	if e.Time == "" {
+		e.Time = time.Now().Format(utils.TimeFormat)
+	}
+
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+	for _, sub := range b.subscribers {
+		if !sub.filter.match(e) {
+			continue
+		}
+
+		select {
+		case sub.ch <- e:
+		default:
+		}
+	}
+}
+
+// PublishStatus publish a status event only when the status of experiment changes.
+// The target and fault of the experiment are filled by the previous status event if they are empty
+func (b *Bus) PublishStatus(uid, target, fault, status, msg string) {
+	if uid == "" || status == "" {
+		return
+	}
+
+	b.mutex.Lock()
+	record := b.statuses[uid]
+	if record == nil {
+		record = &statusRecord{}
+		b.statuses[uid] = record
+	} else if record.status == status {
+		b.mutex.Unlock()
+		return
+	}
+
+	if target != "" {
+		record.target = target
+	}
+	if fault != "" {
+		record.fault = fault
+	}
+	record.status, record.updateTime = status, time.Now()
+	target, fault = record.target, record.fault
+	b.pruneStatuses()
+	b.mutex.Unlock()
+
+	b.Publish(&Event{
+		Type:    TypeStatus,
+		Uid:     uid,
+		Target:  target,
+		Fault:   fault,
+		Status:  status,
+		Message: msg,
+	})
+}
+
+func (b *Bus) pruneStatuses() {
+	expireTime := time.Now().Add(-statusRecordKeepTime)
+	for uid, record := range b.statuses {
+		if (record.status == utils.StatusDestroyed || record.status == utils.StatusError || record.status == utils.StatusRecoverVerifyFailed) &&
+			record.updateTime.Before(expireTime) {
+			delete(b.statuses, uid)
+		}
+	}
+}
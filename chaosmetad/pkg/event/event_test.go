@@ -0,0 +1,69 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package event
+
+import (
+	"testing"
+)
+
+func TestBus_PublishStatus(t *testing.T) {
+	bus := NewBus()
+	events, cancel := bus.Subscribe(&Filter{Target: "mem"})
+	defer cancel()
+
+	bus.PublishStatus("u1", "mem", "fill", "created", "")
+	bus.PublishStatus("u1", "", "", "created", "")
+	bus.PublishStatus("u1", "", "", "success", "")
+	bus.PublishStatus("u2", "cpu", "burn", "created", "")
+	bus.PublishStatus("u1", "", "", "destroyed", "")
+
+	var got []*Event
+	for len(events) > 0 {
+		got = append(got, <-events)
+	}
+
+	wantStatus := []string{"created", "success", "destroyed"}
+	if len(got) != len(wantStatus) {
+		t.Fatalf("PublishStatus() got %d events, want %d", len(got), len(wantStatus))
+	}
+
+	for i, e := range got {
+		if e.Uid != "u1" || e.Target != "mem" || e.Fault != "fill" || e.Type != TypeStatus || e.Status != wantStatus[i] {
+			t.Errorf("PublishStatus() event[%d] = %+v, want status %s of u1 mem fill", i, e, wantStatus[i])
+		}
+	}
+}
+
+func TestBus_Subscribe(t *testing.T) {
+	bus := NewBus()
+	events, cancel := bus.Subscribe(nil)
+	if !bus.HasSubscriber() {
+		t.Fatalf("HasSubscriber() = false after subscribe")
+	}
+
+	cancel()
+	cancel()
+	if bus.HasSubscriber() {
+		t.Errorf("HasSubscriber() = true after cancel")
+	}
+
+	if _, ok := <-events; ok {
+		t.Errorf("event channel is not closed after cancel")
+	}
+
+	bus.Publish(&Event{Type: TypeProgress, Uid: "u1"})
+}
@@ -31,6 +31,9 @@ import (
 
 const (
 	FillFileName = "chaosmeta_fill"
+
+	ModeBytes = "bytes"
+	ModeInode = "inode"
 )
 
 // [func] [fault] [level] [args]
@@ -58,23 +61,40 @@ func main() {
 	}
 }
 
+// getMode the mode args is optional and default is bytes
+func getMode(args []string, index int) string {
+	if len(args) > index && args[index] != "" {
+		return args[index]
+	}
+
+	return ModeBytes
+}
+
 func execValidator(ctx context.Context, args []string) error {
-	percentStr, bytes, dir := args[0], args[1], args[2]
+	percentStr, bytes, dir, mode := args[0], args[1], args[2], getMode(args, 3)
 	percent, err := strconv.Atoi(percentStr)
 	if err != nil {
 		return fmt.Errorf("percent is not a num")
 	}
 
+	if mode == ModeInode {
+		return validatorInodeFill(ctx, percent, dir)
+	}
+
 	return validatorDiskFill(ctx, percent, bytes, dir)
 }
 
 func execInject(ctx context.Context, args []string) error {
-	percentStr, bytes, dir, uid := args[0], args[1], args[2], args[3]
+	percentStr, bytes, dir, uid, mode := args[0], args[1], args[2], args[3], getMode(args, 4)
 	percent, err := strconv.Atoi(percentStr)
 	if err != nil {
 		return fmt.Errorf("pecent is not a num")
 	}
 
+	if mode == ModeInode {
+		return injectInodeFill(ctx, percent, dir, uid)
+	}
+
 	return injectDiskFill(ctx, percent, bytes, dir, uid)
 }
 
@@ -131,6 +151,46 @@ func injectDiskFill(ctx context.Context, percent int, bytes, dir, uid string) er
 	return nil
 }
 
+func validatorInodeFill(ctx context.Context, percent int, dir string) error {
+	if percent <= 0 || percent > 100 {
+		return fmt.Errorf("\"percent\"[%d] must be in (0,100] in mode \"%s\"", percent, ModeInode)
+	}
+
+	if err := filesys.CheckDirLocal(dir); err != nil {
+		return fmt.Errorf("\"dir\"[%s] check error: %s", dir, err.Error())
+	}
+
+	if _, err := disk.GetInodeFillCount(dir, percent); err != nil {
+		return fmt.Errorf("calculate fill inode count error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func getFillDirName(uid string) string {
+	return fmt.Sprintf("%s%s.inode", FillFileName, uid)
+}
+
+func injectInodeFill(ctx context.Context, percent int, dir, uid string) error {
+	logger := log.GetLogger(ctx)
+	fillDir := fmt.Sprintf("%s/%s", dir, getFillDirName(uid))
+	count, err := disk.GetInodeFillCount(dir, percent)
+	if err != nil {
+		return fmt.Errorf("calculate fill inode count error: %s", err.Error())
+	}
+
+	logger.Debugf("fill %d files in %s", count, fillDir)
+	if err := disk.RunFillInode(ctx, count, fillDir); err != nil {
+		if err := disk.RemoveFillInode(ctx, fillDir); err != nil {
+			logger.Warnf("run failed and delete fill dir error: %s", err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+// recoverDiskFill clear the fill file of bytes mode and the fill dir of inode mode
 func recoverDiskFill(ctx context.Context, dir, uid string) error {
 	fillFile := fmt.Sprintf("%s/%s", dir, getFillFileName(uid))
 	isExist, err := filesys.ExistPathLocal(fillFile)
@@ -139,7 +199,19 @@ func recoverDiskFill(ctx context.Context, dir, uid string) error {
 	}
 
 	if isExist {
-		return os.Remove(fillFile)
+		if err := os.Remove(fillFile); err != nil {
+			return err
+		}
+	}
+
+	fillDir := fmt.Sprintf("%s/%s", dir, getFillDirName(uid))
+	isExist, err = filesys.ExistPathLocal(fillDir)
+	if err != nil {
+		return fmt.Errorf("check dir[%s] exist error: %s", fillDir, err.Error())
+	}
+
+	if isExist {
+		return disk.RemoveFillInode(ctx, fillDir)
 	}
 
 	return nil
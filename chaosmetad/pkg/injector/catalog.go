@@ -0,0 +1,221 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/version"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	JSONSchemaVersion  = "http://json-schema.org/draft-07/schema#"
+	FlagAnnotationEnum = "chaosmeta_enum"
+)
+
+type Catalog struct {
+	Version string          `json:"version"`
+	Targets []TargetCatalog `json:"targets"`
+}
+
+type TargetCatalog struct {
+	Name   string         `json:"name"`
+	Faults []FaultCatalog `json:"faults"`
+}
+
+type FaultCatalog struct {
+	Name string      `json:"name"`
+	Args *JSONSchema `json:"args"`
+}
+
+type JSONSchema struct {
+	Schema      string                 `json:"$schema,omitempty"`
+	Title       string                 `json:"title,omitempty"`
+	Description string                 `json:"description,omitempty"`
+	Type        string                 `json:"type"`
+	Properties  map[string]*JSONSchema `json:"properties,omitempty"`
+	Default     interface{}            `json:"default,omitempty"`
+	Enum        []string               `json:"enum,omitempty"`
+	// Flag and Shorthand are the cobra flag of the arg in command line
+	Flag      string `json:"x-flag,omitempty"`
+	Shorthand string `json:"x-shorthand,omitempty"`
+}
+
+// SetFlagEnum declare the optional values of a flag, they are shown as "enum" in the catalog
+func SetFlagEnum(cmd *cobra.Command, name string, values ...string) {
+	_ = cmd.Flags().SetAnnotation(name, FlagAnnotationEnum, values)
+}
+
+// intPtrValue is the flag value of an optional int arg, nil means not provided, so that 0 can be a valid value
+type intPtrValue struct {
+	p **int
+}
+
+func (v *intPtrValue) String() string {
+	if *v.p == nil {
+		return ""
+	}
+
+	return strconv.Itoa(**v.p)
+}
+
+func (v *intPtrValue) Set(s string) error {
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		return err
+	}
+
+	*v.p = &n
+	return nil
+}
+
+func (v *intPtrValue) Type() string {
+	return "int"
+}
+
+// IntPtrVarP define an optional int flag, p is set only when the flag is provided
+func IntPtrVarP(cmd *cobra.Command, p **int, name, shorthand, usage string) {
+	cmd.Flags().VarP(&intPtrValue{p: p}, name, shorthand, usage)
+}
+
+// GetCatalog generate the catalog from registered injectors and their cobra flags
+func GetCatalog() (*Catalog, error) {
+	targets := GetTargets()
+	sort.Strings(targets)
+
+	catalog := &Catalog{
+		Version: version.GetVersion().Version,
+		Targets: make([]TargetCatalog, 0, len(targets)),
+	}
+
+	for _, target := range targets {
+		faults := GetFaultsByTarget(target)
+		sort.Strings(faults)
+
+		targetCatalog := TargetCatalog{
+			Name:   target,
+			Faults: make([]FaultCatalog, 0, len(faults)),
+		}
+
+		for _, fault := range faults {
+			schema, err := GetArgsSchema(target, fault)
+			if err != nil {
+				return nil, fmt.Errorf("get args schema of target[%s] fault[%s] error: %s", target, fault, err.Error())
+			}
+
+			targetCatalog.Faults = append(targetCatalog.Faults, FaultCatalog{
+				Name: fault,
+				Args: schema,
+			})
+		}
+
+		catalog.Targets = append(catalog.Targets, targetCatalog)
+	}
+
+	return catalog, nil
+}
+
+// GetArgsSchema generate the json schema of injector's args. The property name is the json tag of args field,
+// and the flag bound to the field provides description and enum. Default values are the values after SetDefault
+func GetArgsSchema(target, fault string) (*JSONSchema, error) {
+	i, err := NewInjector(target, fault)
+	if err != nil {
+		return nil, err
+	}
+
+	cmd := &cobra.Command{Use: fault}
+	i.SetOption(cmd)
+	i.SetDefault()
+
+	schema := &JSONSchema{
+		Schema:      JSONSchemaVersion,
+		Title:       fmt.Sprintf("%s %s", target, fault),
+		Description: fmt.Sprintf("args of %s experiment for %s", fault, target),
+		Type:        "object",
+		Properties:  make(map[string]*JSONSchema),
+	}
+
+	argsValue := reflect.ValueOf(i.GetArgs())
+	if argsValue.Kind() != reflect.Ptr || argsValue.Elem().Kind() != reflect.Struct {
+		return schema, nil
+	}
+	argsValue = argsValue.Elem()
+
+	flagByAddr := make(map[uintptr]*pflag.Flag)
+	cmd.Flags().VisitAll(func(f *pflag.Flag) {
+		if v, ok := f.Value.(*intPtrValue); ok {
+			flagByAddr[reflect.ValueOf(v.p).Pointer()] = f
+		} else if v := reflect.ValueOf(f.Value); v.Kind() == reflect.Ptr {
+			flagByAddr[v.Pointer()] = f
+		}
+	})
+
+	for index := 0; index < argsValue.NumField(); index++ {
+		field, value := argsValue.Type().Field(index), argsValue.Field(index)
+		name := strings.Split(field.Tag.Get("json"), ",")[0]
+		if name == "" || name == "-" {
+			continue
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+
+		property := &JSONSchema{
+			Type: getJSONType(fieldType.Kind()),
+		}
+
+		if !value.IsZero() {
+			property.Default = reflect.Indirect(value).Interface()
+		}
+
+		if f := flagByAddr[value.Addr().Pointer()]; f != nil {
+			property.Description = f.Usage
+			property.Flag = f.Name
+			property.Shorthand = f.Shorthand
+			property.Enum = f.Annotations[FlagAnnotationEnum]
+		}
+
+		schema.Properties[name] = property
+	}
+
+	return schema, nil
+}
+
+func getJSONType(kind reflect.Kind) string {
+	switch kind {
+	case reflect.Bool:
+		return "boolean"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return "integer"
+	case reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Slice, reflect.Array:
+		return "array"
+	case reflect.Map, reflect.Struct:
+		return "object"
+	default:
+		return "string"
+	}
+}
@@ -0,0 +1,85 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/spf13/cobra"
+)
+
+type catalogTestInjector struct {
+	BaseInjector
+	Args catalogTestArgs
+}
+
+type catalogTestArgs struct {
+	Mode    string `json:"mode"`
+	Count   int    `json:"count,omitempty"`
+	Force   bool   `json:"force,omitempty"`
+	Level   *int   `json:"level,omitempty"`
+	Ignored string `json:"-"`
+}
+
+func (i *catalogTestInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *catalogTestInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", "test mode")
+	SetFlagEnum(cmd, "mode", "a", "b")
+	cmd.Flags().IntVar(&i.Args.Count, "count", 3, "test count")
+	cmd.Flags().BoolVarP(&i.Args.Force, "force", "f", false, "test force")
+	IntPtrVarP(cmd, &i.Args.Level, "level", "l", "test level")
+}
+
+func (i *catalogTestInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = "a"
+	}
+}
+
+func TestGetArgsSchema(t *testing.T) {
+	Register("catalogtest", "fault", func() IInjector { return &catalogTestInjector{} })
+	defer delete(constructorScheme, getInjectorKey("catalogtest", "fault"))
+
+	schema, err := GetArgsSchema("catalogtest", "fault")
+	if err != nil {
+		t.Fatalf("GetArgsSchema() error = %v", err)
+	}
+
+	want := map[string]*JSONSchema{
+		"mode":  {Type: "string", Default: "a", Enum: []string{"a", "b"}, Description: "test mode", Flag: "mode", Shorthand: "m"},
+		"count": {Type: "integer", Default: 3, Description: "test count", Flag: "count"},
+		"force": {Type: "boolean", Description: "test force", Flag: "force", Shorthand: "f"},
+		"level": {Type: "integer", Description: "test level", Flag: "level", Shorthand: "l"},
+	}
+
+	if !reflect.DeepEqual(schema.Properties, want) {
+		for k, v := range schema.Properties {
+			t.Logf("%s: %+v", k, v)
+		}
+		t.Errorf("GetArgsSchema() properties not as expected")
+	}
+
+	if _, err := GetArgsSchema("catalogtest", "unknown"); err == nil {
+		t.Errorf("GetArgsSchema() of unknown fault should return error")
+	}
+}
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/metrics"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+)
+
+// IMetric is implemented by injectors which can report runtime values of a running experiment, eg: "fill_bytes" of "disk fill"
+type IMetric interface {
+	GetMetrics(ctx context.Context) (map[string]float64, error)
+}
+
+// ExperimentCollector collect the active experiments from storage and the runtime values of their injectors when scraped
+type ExperimentCollector struct {
+	ctx context.Context
+}
+
+func NewExperimentCollector(ctx context.Context) *ExperimentCollector {
+	return &ExperimentCollector{ctx: ctx}
+}
+
+func (c *ExperimentCollector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- metrics.ActiveExperimentsDesc
+	ch <- metrics.InjectorRuntimeDesc
+}
+
+func (c *ExperimentCollector) Collect(ch chan<- prometheus.Metric) {
+	logger := log.GetLogger(c.ctx)
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		logger.Warnf("collect metrics: connect db error: %s", err.Error())
+		return
+	}
+
+	exps, err := db.QueryByStatus(utils.StatusSuccess)
+	if err != nil {
+		logger.Warnf("collect metrics: query active experiments error: %s", err.Error())
+		return
+	}
+
+	type faultKey struct {
+		target, fault string
+	}
+	activeCount := make(map[faultKey]int)
+	for _, exp := range exps {
+		activeCount[faultKey{exp.Target, exp.Fault}]++
+
+		values, err := getMetrics(c.ctx, exp)
+		if err != nil {
+			logger.Debugf("collect metrics: get runtime values of experiment[%s] error: %s", exp.Uid, err.Error())
+			continue
+		}
+
+		for name, value := range values {
+			ch <- prometheus.MustNewConstMetric(metrics.InjectorRuntimeDesc, prometheus.GaugeValue, value, exp.Uid, exp.Target, exp.Fault, name)
+		}
+	}
+
+	for k, count := range activeCount {
+		ch <- prometheus.MustNewConstMetric(metrics.ActiveExperimentsDesc, prometheus.GaugeValue, float64(count), k.target, k.fault)
+	}
+}
+
+func getMetrics(ctx context.Context, exp *storage.Experiment) (map[string]float64, error) {
+	i, err := newInjectorByExp(exp)
+	if err != nil {
+		return nil, err
+	}
+
+	m, ok := i.(IMetric)
+	if !ok {
+		return nil, nil
+	}
+
+	return m.GetMetrics(ctx)
+}
@@ -0,0 +1,233 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+)
+
+const conflictLockFile = "chaosmetad_inject.lock"
+
+// lockConflict serialize the conflict check and the insert of experiments between processes, return the unlock function
+func lockConflict() (func(), error) {
+	path := filepath.Join(utils.GetRunPath(), conflictLockFile)
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("open lock file[%s] error: %s", path, err.Error())
+	}
+
+	if err := syscall.Flock(int(f.Fd()), syscall.LOCK_EX); err != nil {
+		_ = f.Close()
+		return nil, fmt.Errorf("lock file[%s] error: %s", path, err.Error())
+	}
+
+	return func() {
+		_ = syscall.Flock(int(f.Fd()), syscall.LOCK_UN)
+		_ = f.Close()
+	}, nil
+}
+
+// checkConflict compare the resources of the experiment with the running experiments and the ones being injected.
+// the overlaps are rejected unless "allow-overlap" is provided, and the suspected overlaps are only warned
+func checkConflict(ctx context.Context, i IInjector) error {
+	logger := log.GetLogger(ctx)
+	r, ok := i.(IResource)
+	if !ok {
+		return nil
+	}
+
+	res, err := r.GetResource(ctx)
+	if err != nil {
+		logger.Warnf("get resource of experiment error, skip conflict check: %s", err.Error())
+		return nil
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		return fmt.Errorf("connect db error: %s", err.Error())
+	}
+
+	var exps []*storage.Experiment
+	for _, status := range []string{utils.StatusSuccess, utils.StatusCreated} {
+		re, err := db.QueryByStatus(status)
+		if err != nil {
+			return fmt.Errorf("query %s experiments error: %s", status, err.Error())
+		}
+		exps = append(exps, re...)
+	}
+
+	var (
+		info                = i.GetInfo()
+		conflicts, warnings []string
+	)
+
+	for _, exp := range exps {
+		if exp.Uid == info.Uid {
+			continue
+		}
+
+		expRes, err := getExpResource(ctx, exp)
+		if err != nil {
+			logger.Debugf("get resource of experiment[%s] error, skip: %s", exp.Uid, err.Error())
+			continue
+		}
+
+		if expRes == nil {
+			continue
+		}
+
+		sameScope := exp.ContainerRuntime == info.ContainerRuntime && exp.ContainerId == info.ContainerId
+		overlaps, suspects := getOverlap(res, expRes, sameScope)
+		expStr := fmt.Sprintf("experiment[%s](%s %s)", exp.Uid, exp.Target, exp.Fault)
+		if len(overlaps) > 0 {
+			conflicts = append(conflicts, fmt.Sprintf("%s: %s", expStr, strings.Join(overlaps, ", ")))
+		}
+
+		if len(suspects) > 0 {
+			warnings = append(warnings, fmt.Sprintf("%s: %s", expStr, strings.Join(suspects, ", ")))
+		}
+	}
+
+	if len(warnings) > 0 {
+		logger.Warnf("may overlap with running experiments: %s", strings.Join(warnings, "; "))
+	}
+
+	if len(conflicts) == 0 {
+		return nil
+	}
+
+	if info.AllowOverlap {
+		logger.Warnf("overlap with running experiments, allowed by \"allow-overlap\": %s", strings.Join(conflicts, "; "))
+		return nil
+	}
+
+	return fmt.Errorf("overlap with running experiments: %s. if want to inject anyway, please provide \"--allow-overlap\"", strings.Join(conflicts, "; "))
+}
+
+func getExpResource(ctx context.Context, exp *storage.Experiment) (*Resource, error) {
+	i, err := NewInjector(exp.Target, exp.Fault)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := i.LoadInjector(exp, i.GetArgs(), i.GetRuntime()); err != nil {
+		return nil, err
+	}
+
+	r, ok := i.(IResource)
+	if !ok {
+		return nil, nil
+	}
+
+	return r.GetResource(ctx)
+}
+
+// getOverlap return the overlapped resources and the suspected ones of two experiments.
+// cores are resources of host, the others are compared only in the same scope.
+// devices are compared in scope too, because the io of a device is limited in the cgroup of each target
+func getOverlap(a, b *Resource, sameScope bool) (overlaps, suspects []string) {
+	if cores := intersectInt(a.Cores, b.Cores); len(cores) > 0 {
+		overlaps = append(overlaps, fmt.Sprintf("cores %v", cores))
+	}
+
+	if !sameScope {
+		return
+	}
+
+	if devices := intersectStr(a.Devices, b.Devices); len(devices) > 0 {
+		overlaps = append(overlaps, fmt.Sprintf("devices %v", devices))
+	}
+
+	// no cores means all the cores of target
+	if a.CpuPercent > 0 && b.CpuPercent > 0 && (len(a.Cores) == 0 || len(b.Cores) == 0) {
+		overlaps = append(overlaps, "cpu")
+	}
+
+	if a.MemPercent > 0 && b.MemPercent > 0 {
+		overlaps = append(overlaps, "memory")
+	}
+
+	if a.DiskPercent > 0 && b.DiskPercent > 0 {
+		overlaps = append(overlaps, "disk")
+	}
+
+	if pids := intersectInt(a.Pids, b.Pids); len(pids) > 0 {
+		overlaps = append(overlaps, fmt.Sprintf("pids %v", pids))
+	}
+
+	if interfaces := intersectStr(a.Interfaces, b.Interfaces); len(interfaces) > 0 {
+		overlaps = append(overlaps, fmt.Sprintf("interfaces %v", interfaces))
+	}
+
+	for _, pathA := range a.Paths {
+		for _, pathB := range b.Paths {
+			pathA, pathB = filepath.Clean(pathA), filepath.Clean(pathB)
+			if pathA == pathB {
+				overlaps = append(overlaps, fmt.Sprintf("path %s", pathA))
+			} else if isSubPath(pathA, pathB) || isSubPath(pathB, pathA) {
+				suspects = append(suspects, fmt.Sprintf("paths %s and %s", pathA, pathB))
+			}
+		}
+	}
+
+	if keys := intersectStr(a.ProcessKeys, b.ProcessKeys); len(keys) > 0 {
+		suspects = append(suspects, fmt.Sprintf("process keys %v", keys))
+	}
+
+	return
+}
+
+func isSubPath(path, parent string) bool {
+	return strings.HasPrefix(path, strings.TrimSuffix(parent, "/")+"/")
+}
+
+func intersectInt(a, b []int) []int {
+	var re []int
+	for _, x := range a {
+		for _, y := range b {
+			if x == y {
+				re = append(re, x)
+				break
+			}
+		}
+	}
+
+	return re
+}
+
+func intersectStr(a, b []string) []string {
+	var re []string
+	for _, x := range a {
+		if utils.StrListContain(b, x) {
+			re = append(re, x)
+		}
+	}
+
+	return re
+}
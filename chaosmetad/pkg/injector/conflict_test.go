@@ -0,0 +1,161 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestGetOverlap(t *testing.T) {
+	for _, c := range []struct {
+		name      string
+		a, b      *Resource
+		sameScope bool
+		overlaps  []string
+		suspects  []string
+	}{
+		{
+			name:     "burn on the same cores in different containers",
+			a:        &Resource{CpuPercent: 50, Cores: []int{0, 1}},
+			b:        &Resource{CpuPercent: 50, Cores: []int{1, 2}},
+			overlaps: []string{"cores [1]"},
+		},
+		{
+			name:      "burn on different cores",
+			a:         &Resource{CpuPercent: 50, Cores: []int{0}},
+			b:         &Resource{CpuPercent: 50, Cores: []int{1}},
+			sameScope: true,
+		},
+		{
+			name:      "mem fill on mem oom",
+			a:         &Resource{MemPercent: 50},
+			b:         &Resource{MemPercent: 100},
+			sameScope: true,
+			overlaps:  []string{"memory"},
+		},
+		{
+			name: "mem fill in different containers",
+			a:    &Resource{MemPercent: 50},
+			b:    &Resource{MemPercent: 100},
+		},
+		{
+			name:      "diskio limit on a hanged pid",
+			a:         &Resource{Pids: []int{100, 101}, Devices: []string{"8:0"}},
+			b:         &Resource{Pids: []int{101}, Devices: []string{"8:16"}},
+			sameScope: true,
+			overlaps:  []string{"pids [101]"},
+		},
+		{
+			name:      "diskio on the same device",
+			a:         &Resource{Pids: []int{100}, Devices: []string{"8:0"}},
+			b:         &Resource{Pids: []int{101}, Devices: []string{"8:0", "8:16"}},
+			sameScope: true,
+			overlaps:  []string{"devices [8:0]"},
+		},
+		{
+			name: "diskio on the same device in different containers",
+			a:    &Resource{Pids: []int{100}, Devices: []string{"8:0"}},
+			b:    &Resource{Pids: []int{101}, Devices: []string{"8:0"}},
+		},
+		{
+			name:      "same and nested paths",
+			a:         &Resource{Paths: []string{"/tmp/a/", "/tmp/b/c"}, ProcessKeys: []string{"java"}},
+			b:         &Resource{Paths: []string{"/tmp/a", "/tmp/b"}, ProcessKeys: []string{"java"}},
+			sameScope: true,
+			overlaps:  []string{"path /tmp/a"},
+			suspects:  []string{"paths /tmp/b/c and /tmp/b", "process keys [java]"},
+		},
+		{
+			name:      "same interface",
+			a:         &Resource{Interfaces: []string{"eth0"}},
+			b:         &Resource{Interfaces: []string{"eth0", "lo"}},
+			sameScope: true,
+			overlaps:  []string{"interfaces [eth0]"},
+		},
+	} {
+		overlaps, suspects := getOverlap(c.a, c.b, c.sameScope)
+		if !reflect.DeepEqual(overlaps, c.overlaps) || !reflect.DeepEqual(suspects, c.suspects) {
+			t.Errorf("%s: expected overlaps %v and suspects %v, get %v and %v", c.name, c.overlaps, c.suspects, overlaps, suspects)
+		}
+	}
+}
+
+type conflictTestInjector struct {
+	BaseInjector
+	Args    struct{}
+	Runtime struct{}
+}
+
+func (i *conflictTestInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *conflictTestInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *conflictTestInjector) Inject(ctx context.Context) error {
+	return nil
+}
+
+// GetResource is slow, so the concurrent conflict checks overlap without the lock
+func (i *conflictTestInjector) GetResource(ctx context.Context) (*Resource, error) {
+	time.Sleep(50 * time.Millisecond)
+	return &Resource{Cores: []int{0}}, nil
+}
+
+func TestProcessInject_Concurrent(t *testing.T) {
+	Register("conflicttest", "fault", func() IInjector { return &conflictTestInjector{} })
+	defer delete(constructorScheme, getInjectorKey("conflicttest", "fault"))
+
+	storage.SetExperimentStore(storage.NewMemoryStore())
+	defer storage.SetExperimentStore(nil)
+
+	var (
+		wg    sync.WaitGroup
+		codes = make([]int, 5)
+	)
+	for n := range codes {
+		wg.Add(1)
+		go func(n int) {
+			defer wg.Done()
+			i, _ := NewInjector("conflicttest", "fault")
+			i.SetCommonArgs(&BaseInfo{Target: "conflicttest", Fault: "fault"})
+			codes[n], _ = ProcessInject(context.Background(), i)
+		}(n)
+	}
+	wg.Wait()
+
+	var success int
+	for _, code := range codes {
+		if code == errutil.NoErr {
+			success++
+		} else if code != errutil.ConflictErr {
+			t.Errorf("expect conflict error, get code %d", code)
+		}
+	}
+
+	if success != 1 {
+		t.Errorf("expect only one experiment injected, get %d: %v", success, codes)
+	}
+}
@@ -19,10 +19,25 @@ package container
 const (
 	TargetContainer = "container"
 
-	FaultContainerKill    = "kill"
-	FaultContainerRestart = "restart"
-	FaultContainerPause   = "pause"
-	FaultContainerRm      = "rm"
+	FaultContainerKill     = "kill"
+	FaultContainerRestart  = "restart"
+	FaultContainerPause    = "pause"
+	FaultContainerRm       = "rm"
+	FaultContainerPullFail = "pullfail"
+	FaultContainerUpdate   = "update"
+
+	ModeFail = "fail"
+	ModeHang = "hang"
+
+	BlackHoleKey       = "chaosmeta_blackhole"
+	DefaultBlackHoleIp = "127.0.0.254"
+	HostsFile          = "/etc/hosts"
+	DockerHubRegistry  = "docker.io"
 
 	DefaultWaitTime = 10
+
+	DefaultCpuShares = 1024
 )
+
+// DockerHubHosts are the hosts used when pulling images from docker.io
+var DockerHubHosts = []string{"registry-1.docker.io", "auth.docker.io", "production.cloudflare.docker.com"}
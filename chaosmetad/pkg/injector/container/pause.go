@@ -52,11 +52,16 @@ func (i *PauseInjector) SetOption(cmd *cobra.Command) {
 }
 
 func (i *PauseInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	// the runtime and id are resolved from pod information or found by id in the base validator
 	if i.Info.ContainerRuntime == "" || i.Info.ContainerId == "" {
-		return fmt.Errorf("please provide container runtime and id")
+		return fmt.Errorf("please provide container id or pod information")
 	}
 
-	return i.BaseInjector.Validator(ctx)
+	return nil
 }
 
 func (i *PauseInjector) Inject(ctx context.Context) error {
@@ -0,0 +1,228 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"net"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+func init() {
+	injector.Register(TargetContainer, FaultContainerPullFail, func() injector.IInjector { return &PullFailInjector{} })
+}
+
+var hostnameRegexp = regexp.MustCompile(`^[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?(\.[a-zA-Z0-9]([a-zA-Z0-9-]*[a-zA-Z0-9])?)*$`)
+
+// PullFailInjector make image pulls from target registries fail or hang on the node:
+// the registry hosts are resolved to a local black-hole endpoint by /etc/hosts
+type PullFailInjector struct {
+	injector.BaseInjector
+	Args    PullFailArgs
+	Runtime PullFailRuntime
+}
+
+type PullFailArgs struct {
+	Registry string `json:"registry"`
+	Mode     string `json:"mode"`
+	Ip       string `json:"ip,omitempty"`
+}
+
+type PullFailRuntime struct {
+	Hosts []string `json:"hosts,omitempty"`
+	Ports []int    `json:"ports,omitempty"`
+}
+
+func (i *PullFailInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *PullFailInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *PullFailInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = ModeFail
+	}
+
+	if i.Args.Ip == "" {
+		i.Args.Ip = DefaultBlackHoleIp
+	}
+}
+
+func (i *PullFailInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Registry, "registry", "r", "", fmt.Sprintf("target registries, support port, eg: \"%s,harbor.example.com:5000\". \"%s\" means %v", DockerHubRegistry, DockerHubRegistry, DockerHubHosts))
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("pull fail mode, support: %s(connection reset)、%s(connection never response)（default %s）", ModeFail, ModeHang, ModeFail))
+	injector.SetFlagEnum(cmd, "mode", ModeFail, ModeHang)
+	cmd.Flags().StringVarP(&i.Args.Ip, "ip", "i", "", fmt.Sprintf("local ip of the black-hole endpoint（default %s）", DefaultBlackHoleIp))
+}
+
+func (i *PullFailInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Info.ContainerId != "" {
+		return fmt.Errorf("%s is a node level fault, not support container", FaultContainerPullFail)
+	}
+
+	if i.Args.Mode != ModeFail && i.Args.Mode != ModeHang {
+		return fmt.Errorf("\"mode\" is not support: %s, only support: %s、%s", i.Args.Mode, ModeFail, ModeHang)
+	}
+
+	if ip := net.ParseIP(i.Args.Ip); ip == nil || !ip.IsLoopback() {
+		return fmt.Errorf("\"ip\"[%s] must be a loopback ip", i.Args.Ip)
+	}
+
+	if _, _, err := getRegistryHostsAndPorts(i.Args.Registry); err != nil {
+		return fmt.Errorf("\"registry\"[%s] is invalid: %s", i.Args.Registry, err.Error())
+	}
+
+	isExist, err := process.ExistProcessByKey(ctx, BlackHoleKey)
+	if err != nil {
+		return fmt.Errorf("check process exist by key[%s] error: %s", BlackHoleKey, err.Error())
+	}
+
+	if isExist {
+		return fmt.Errorf("has other %s experiment is running", FaultContainerPullFail)
+	}
+
+	return nil
+}
+
+func (i *PullFailInjector) Inject(ctx context.Context) error {
+	logger := log.GetLogger(ctx)
+	i.Runtime.Hosts, i.Runtime.Ports, _ = getRegistryHostsAndPorts(i.Args.Registry)
+
+	var timeout int64
+	if i.Info.Timeout != "" {
+		timeout, _ = utils.GetTimeSecond(i.Info.Timeout)
+	}
+
+	ports := make([]string, len(i.Runtime.Ports))
+	for j, port := range i.Runtime.Ports {
+		ports[j] = strconv.Itoa(port)
+	}
+
+	cmd := fmt.Sprintf("%s %s %s %s %s %d", utils.GetToolPath(BlackHoleKey), i.Info.Uid, i.Args.Mode, i.Args.Ip, strings.Join(ports, ","), timeout)
+	if _, err := cmdexec.StartBashCmdAndWaitPid(ctx, cmd, 0); err != nil {
+		return fmt.Errorf("start black-hole endpoint error: %s", err.Error())
+	}
+
+	if _, err := cmdexec.RunBashCmdWithOutput(ctx, getHostsAddCmd(i.Info.Uid, i.Args.Ip, i.Runtime.Hosts)); err != nil {
+		if err := i.Recover(ctx); err != nil {
+			logger.Warnf("undo error: %s", err.Error())
+		}
+
+		return fmt.Errorf("add registry hosts to %s error: %s", HostsFile, err.Error())
+	}
+
+	return nil
+}
+
+func (i *PullFailInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if _, err := cmdexec.RunBashCmdWithOutput(ctx, getHostsDeleteCmd(i.Info.Uid)); err != nil {
+		return fmt.Errorf("delete registry hosts from %s error: %s", HostsFile, err.Error())
+	}
+
+	return process.CheckExistAndKillByKey(ctx, fmt.Sprintf("%s %s", BlackHoleKey, i.Info.Uid))
+}
+
+// getRegistryHostsAndPorts return the hosts and ports to hijack, use 443 and 80 if the registry has no port
+func getRegistryHostsAndPorts(registryStr string) ([]string, []int, error) {
+	registryStr = strings.TrimSpace(registryStr)
+	if registryStr == "" {
+		return nil, nil, fmt.Errorf("is empty")
+	}
+
+	var (
+		hostMap = make(map[string]bool)
+		portMap = make(map[int]bool)
+		hosts   []string
+		ports   []int
+	)
+
+	for _, registry := range strings.Split(registryStr, ",") {
+		registry = strings.TrimSpace(registry)
+		host, portStr, err := net.SplitHostPort(registry)
+		if err != nil {
+			host = registry
+			portMap[443], portMap[80] = true, true
+		} else {
+			port, err := strconv.Atoi(portStr)
+			if err != nil || port <= 0 || port > 65535 {
+				return nil, nil, fmt.Errorf("port of registry[%s] is invalid", registry)
+			}
+			portMap[port] = true
+		}
+
+		if !hostnameRegexp.MatchString(host) {
+			return nil, nil, fmt.Errorf("host of registry[%s] is invalid", registry)
+		}
+
+		targetHosts := []string{host}
+		if host == DockerHubRegistry {
+			targetHosts = append(targetHosts, DockerHubHosts...)
+		}
+
+		for _, h := range targetHosts {
+			if !hostMap[h] {
+				hostMap[h] = true
+				hosts = append(hosts, h)
+			}
+		}
+	}
+
+	for port := range portMap {
+		ports = append(ports, port)
+	}
+	sort.Ints(ports)
+
+	return hosts, ports, nil
+}
+
+func getHostsFlag(uid string) string {
+	return fmt.Sprintf("# ChaosMeta-%s-%s", FaultContainerPullFail, uid)
+}
+
+// getHostsAddCmd insert in first line to take precedence over the existing records
+func getHostsAddCmd(uid, ip string, hosts []string) string {
+	return fmt.Sprintf("sed '1s/^/%s %s %s\\n/' %s > %s.chaosmeta && cat %s.chaosmeta > %s && rm -rf %s.chaosmeta",
+		ip, strings.Join(hosts, " "), getHostsFlag(uid), HostsFile, HostsFile, HostsFile, HostsFile, HostsFile)
+}
+
+func getHostsDeleteCmd(uid string) string {
+	return fmt.Sprintf("sed '/%s/d' %s > %s.chaosmeta && cat %s.chaosmeta > %s && rm -rf %s.chaosmeta",
+		getHostsFlag(uid), HostsFile, HostsFile, HostsFile, HostsFile, HostsFile)
+}
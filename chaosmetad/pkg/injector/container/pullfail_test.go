@@ -0,0 +1,51 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package container
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_getRegistryHostsAndPorts(t *testing.T) {
+	tests := []struct {
+		name      string
+		registry  string
+		wantHosts []string
+		wantPorts []int
+		wantErr   bool
+	}{
+		{name: "empty", registry: " ", wantErr: true},
+		{name: "default ports", registry: "harbor.example.com", wantHosts: []string{"harbor.example.com"}, wantPorts: []int{80, 443}},
+		{name: "with port", registry: "harbor.example.com:5000, reg.io", wantHosts: []string{"harbor.example.com", "reg.io"}, wantPorts: []int{80, 443, 5000}},
+		{name: "docker hub", registry: "docker.io", wantHosts: append([]string{"docker.io"}, DockerHubHosts...), wantPorts: []int{80, 443}},
+		{name: "invalid port", registry: "reg.io:abc", wantErr: true},
+		{name: "invalid host", registry: "reg io", wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			hosts, ports, err := getRegistryHostsAndPorts(tt.registry)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("getRegistryHostsAndPorts() error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			if !reflect.DeepEqual(hosts, tt.wantHosts) || !reflect.DeepEqual(ports, tt.wantPorts) {
+				t.Errorf("getRegistryHostsAndPorts() = %v, %v, want %v, %v", hosts, ports, tt.wantHosts, tt.wantPorts)
+			}
+		})
+	}
+}
@@ -0,0 +1,185 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package container
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient/base"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+)
+
+func init() {
+	injector.Register(TargetContainer, FaultContainerUpdate, func() injector.IInjector { return &UpdateInjector{} })
+}
+
+type UpdateInjector struct {
+	injector.BaseInjector
+	Args    UpdateArgs
+	Runtime UpdateRuntime
+}
+
+type UpdateArgs struct {
+	CpuQuota  int64  `json:"cpu_quota,omitempty"`
+	CpuShares int64  `json:"cpu_shares,omitempty"`
+	Memory    string `json:"memory,omitempty"`
+}
+
+type UpdateRuntime struct {
+	Origin *base.Resources `json:"origin,omitempty"`
+}
+
+func (i *UpdateInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *UpdateInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *UpdateInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().Int64VarP(&i.Args.CpuQuota, "cpu-quota", "q", 0, "new cpu cfs quota(us) of container, eg: \"50000\" means 0.5 core when cpu period is 100000us")
+	cmd.Flags().Int64VarP(&i.Args.CpuShares, "cpu-shares", "s", 0, "new cpu shares of container")
+	cmd.Flags().StringVarP(&i.Args.Memory, "memory", "m", "", "new memory limit of container, support unit: KB/MB/GB/TB（default KB）")
+}
+
+func (i *UpdateInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	// the runtime and id are resolved from pod information or found by id in the base validator
+	if i.Info.ContainerRuntime == "" || i.Info.ContainerId == "" {
+		return fmt.Errorf("please provide container id or pod information")
+	}
+
+	if i.Args.CpuQuota == 0 && i.Args.CpuShares == 0 && i.Args.Memory == "" {
+		return fmt.Errorf("must provide at least one args of: cpu-quota、cpu-shares、memory")
+	}
+
+	if i.Args.CpuQuota != 0 && i.Args.CpuQuota < 1000 {
+		return fmt.Errorf("\"cpu-quota\"[%d] can not less than 1000", i.Args.CpuQuota)
+	}
+
+	if i.Args.CpuShares != 0 && i.Args.CpuShares < 2 {
+		return fmt.Errorf("\"cpu-shares\"[%d] can not less than 2", i.Args.CpuShares)
+	}
+
+	if i.Args.Memory != "" {
+		if _, err := getMemoryBytes(i.Args.Memory); err != nil {
+			return fmt.Errorf("\"memory\"[%s] is invalid: %s", i.Args.Memory, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *UpdateInjector) Inject(ctx context.Context) error {
+	client, err := crclient.GetClient(ctx, i.Info.ContainerRuntime)
+	if err != nil {
+		return fmt.Errorf("get %s client error: %s", i.Info.ContainerRuntime, err.Error())
+	}
+
+	i.Runtime.Origin, err = client.GetResourcesById(ctx, i.Info.ContainerId)
+	if err != nil {
+		return fmt.Errorf("get resources of container[%s] error: %s", i.Info.ContainerId, err.Error())
+	}
+	log.GetLogger(ctx).Debugf("origin resources: %+v", *i.Runtime.Origin)
+
+	var target = &base.Resources{
+		CpuQuota:  i.Args.CpuQuota,
+		CpuShares: i.Args.CpuShares,
+	}
+
+	if i.Args.Memory != "" {
+		target.Memory, _ = getMemoryBytes(i.Args.Memory)
+	}
+
+	if err := client.UpdateResourcesById(ctx, i.Info.ContainerId, target); err != nil {
+		return fmt.Errorf("update resources of container[%s] error: %s", i.Info.ContainerId, err.Error())
+	}
+
+	return nil
+}
+
+// Recover only restore the limits changed by the experiment, the limits not set originally are restored to unlimited
+func (i *UpdateInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.Origin == nil {
+		return nil
+	}
+
+	client, err := crclient.GetClient(ctx, i.Info.ContainerRuntime)
+	if err != nil {
+		return fmt.Errorf("get %s client error: %s", i.Info.ContainerRuntime, err.Error())
+	}
+
+	var (
+		origin = i.Runtime.Origin
+		target = &base.Resources{}
+	)
+
+	if i.Args.CpuQuota != 0 {
+		target.CpuQuota = getLimitOrUnlimited(origin.CpuQuota)
+	}
+
+	if i.Args.CpuShares != 0 {
+		target.CpuShares = origin.CpuShares
+		if target.CpuShares == 0 {
+			target.CpuShares = DefaultCpuShares
+		}
+	}
+
+	if i.Args.Memory != "" {
+		target.Memory = getLimitOrUnlimited(origin.Memory)
+		target.MemorySwap = getLimitOrUnlimited(origin.MemorySwap)
+	}
+
+	if err := client.UpdateResourcesById(ctx, i.Info.ContainerId, target); err != nil {
+		return fmt.Errorf("restore resources of container[%s] error: %s", i.Info.ContainerId, err.Error())
+	}
+
+	return nil
+}
+
+func getMemoryBytes(memory string) (int64, error) {
+	kBytes, err := utils.GetKBytes(memory)
+	if err != nil {
+		return -1, err
+	}
+
+	if kBytes <= 0 {
+		return -1, fmt.Errorf("must larger than 0")
+	}
+
+	return kBytes * 1024, nil
+}
+
+func getLimitOrUnlimited(limit int64) int64 {
+	if limit <= 0 {
+		return base.Unlimited
+	}
+
+	return limit
+}
@@ -126,13 +126,9 @@ func (i *BurnInjector) Validator(ctx context.Context) error {
 func (i *BurnInjector) Inject(ctx context.Context) error {
 	logger := log.GetLogger(ctx)
 
-	var coreList []int
-	cpuList, _ := getAllCpuList(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
-
-	if i.Args.List != "" {
-		coreList, _ = utils.GetNumArrByList(i.Args.List)
-	} else {
-		coreList = utils.GetNumArrByCount(i.Args.Count, cpuList)
+	coreList, err := i.getCoreList(ctx)
+	if err != nil {
+		return err
 	}
 
 	logger.Debugf("burn core list: %v", coreList)
@@ -161,6 +157,24 @@ func (i *BurnInjector) Inject(ctx context.Context) error {
 	return nil
 }
 
+// getCoreList return the cores to burn, "list" first, otherwise the first "count" cores of target
+func (i *BurnInjector) getCoreList(ctx context.Context) ([]int, error) {
+	if i.Args.List != "" {
+		return utils.GetNumArrByList(i.Args.List)
+	}
+
+	cpuList, err := getAllCpuList(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+	if err != nil {
+		return nil, fmt.Errorf("get all available cpu list error: %s", err.Error())
+	}
+
+	if i.Args.Count <= 0 || i.Args.Count > len(cpuList) {
+		return cpuList, nil
+	}
+
+	return utils.GetNumArrByCount(i.Args.Count, cpuList), nil
+}
+
 func (i *BurnInjector) Recover(ctx context.Context) error {
 	if i.BaseInjector.Recover(ctx) == nil {
 		return nil
@@ -169,6 +183,18 @@ func (i *BurnInjector) Recover(ctx context.Context) error {
 	return process.CheckExistAndKillByKey(ctx, fmt.Sprintf("%s %s", CpuBurnKey, i.Info.Uid))
 }
 
+func (i *BurnInjector) GetMetrics(ctx context.Context) (map[string]float64, error) {
+	pidList, err := process.GetProcessByKey(ctx, "", "", fmt.Sprintf("%s %s", CpuBurnKey, i.Info.Uid))
+	if err != nil {
+		return nil, fmt.Errorf("get burn process error: %s", err.Error())
+	}
+
+	return map[string]float64{
+		"burn_cores":   float64(len(pidList)),
+		"burn_percent": float64(i.Args.Percent),
+	}, nil
+}
+
 //func (i *BurnInjector) DelayRecover(ctx context.Context, timeout int64) error {
 //	return nil
 //}
@@ -205,3 +231,25 @@ func getCpuList(ctx context.Context, path string) ([]int, error) {
 
 	return cpuList, nil
 }
+
+func (i *BurnInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	coreList, err := i.getCoreList(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	// percent is of each burned core, the node-wide percent is scaled by the cores of node
+	nodeCpuList, err := getAllCpuList(ctx, "", "")
+	if err != nil {
+		return nil, fmt.Errorf("get cpu list of node error: %s", err.Error())
+	}
+
+	if len(nodeCpuList) == 0 {
+		return nil, fmt.Errorf("cpu list of node is empty")
+	}
+
+	return &injector.Resource{
+		CpuPercent: float64(i.Args.Percent) * float64(len(coreList)) / float64(len(nodeCpuList)),
+		Cores:      coreList,
+	}, nil
+}
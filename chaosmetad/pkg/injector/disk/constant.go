@@ -19,9 +19,17 @@ package disk
 const (
 	TargetDisk = "disk"
 
-	FaultDiskFill = "fill"
+	FaultDiskFill     = "fill"
+	FaultDiskReadonly = "readonly"
+	FaultDiskUnmount  = "unmount"
 
 	DefaultDir    = "/tmp"
 
+	ModeBytes = "bytes"
+	ModeInode = "inode"
+
 	DiskFillExec = "chaosmeta_diskfill"
+	FillFileName = "chaosmeta_fill"
+
+	UnmountBackupDir = "/tmp/chaosmeta_unmount"
 )
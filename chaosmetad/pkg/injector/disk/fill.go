@@ -21,10 +21,14 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	udisk "github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/disk"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"os"
+	"path/filepath"
 )
 
 func init() {
@@ -41,9 +45,13 @@ type FillArgs struct {
 	Percent int    `json:"percent,omitempty"`
 	Bytes   string `json:"bytes,omitempty"`
 	Dir     string `json:"dir,omitempty"`
+	Mode    string `json:"mode,omitempty"`
+	Mount   string `json:"mount,omitempty"`
 }
 
 type FillRuntime struct {
+	Device     string `json:"device,omitempty"`
+	MountPoint string `json:"mount_point,omitempty"`
 }
 
 func (i *FillInjector) GetArgs() interface{} {
@@ -58,16 +66,27 @@ func (i *FillInjector) SetDefault() {
 	i.BaseInjector.SetDefault()
 
 	if i.Args.Dir == "" {
-		i.Args.Dir = DefaultDir
+		if i.Args.Mount != "" {
+			i.Args.Dir = i.Args.Mount
+		} else {
+			i.Args.Dir = DefaultDir
+		}
+	}
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = ModeBytes
 	}
 }
 
 func (i *FillInjector) SetOption(cmd *cobra.Command) {
 	// i.BaseInjector.SetOption(cmd)
 
-	cmd.Flags().IntVarP(&i.Args.Percent, "percent", "p", 0, "disk fill target percent, an integer in (0,100] without \"%\", eg: \"30\" means \"30%\"")
+	cmd.Flags().IntVarP(&i.Args.Percent, "percent", "p", 0, "disk fill target percent of bytes or inodes, an integer in (0,100] without \"%\", eg: \"30\" means \"30%\"")
 	cmd.Flags().StringVarP(&i.Args.Bytes, "bytes", "b", "", "disk fill bytes to add, support unit: KB/MB/GB/TB（default KB）")
-	cmd.Flags().StringVarP(&i.Args.Dir, "dir", "d", "", fmt.Sprintf("disk fill target dir（default %s）", DefaultDir))
+	cmd.Flags().StringVarP(&i.Args.Dir, "dir", "d", "", fmt.Sprintf("disk fill target dir（default \"mount\" if provided, otherwise %s）", DefaultDir))
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("disk fill mode, support: %s(fill bytes by a big file)、%s(fill inodes by empty files, only support \"percent\")（default %s）", ModeBytes, ModeInode, ModeBytes))
+	injector.SetFlagEnum(cmd, "mode", ModeBytes, ModeInode)
+	cmd.Flags().StringVarP(&i.Args.Mount, "mount", "M", "", "disk fill target mount point, eg: /var/lib/docker. \"dir\" must be on this filesystem if provided")
 }
 
 func (i *FillInjector) getCmdExecutor(method, args string) *cmdexec.CmdExecutor {
@@ -91,11 +110,41 @@ func (i *FillInjector) Validator(ctx context.Context) error {
 		return fmt.Errorf("\"dir\" must provide absolute path")
 	}
 
-	return i.getCmdExecutor(utils.MethodValidator, fmt.Sprintf("%d '%s' %s", i.Args.Percent, i.Args.Bytes, i.Args.Dir)).ExecTool(ctx)
+	if i.Args.Mode != ModeBytes && i.Args.Mode != ModeInode {
+		return fmt.Errorf("\"mode\" is not support: %s, only support: %s、%s", i.Args.Mode, ModeBytes, ModeInode)
+	}
+
+	if i.Args.Mode == ModeInode && i.Args.Bytes != "" {
+		return fmt.Errorf("\"bytes\" is not support in mode \"%s\", please use \"percent\"", ModeInode)
+	}
+
+	if i.Args.Mount != "" {
+		if !filesys.IfPathAbs(ctx, i.Args.Mount) {
+			return fmt.Errorf("\"mount\" must provide absolute path")
+		}
+
+		_, mountPoint, err := udisk.GetMountInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Dir)
+		if err != nil {
+			return fmt.Errorf("get mount info of \"dir\"[%s] error: %s", i.Args.Dir, err.Error())
+		}
+
+		if filepath.Clean(i.Args.Mount) != mountPoint {
+			return fmt.Errorf("\"dir\"[%s] is on mount point[%s], not on \"mount\"[%s]", i.Args.Dir, mountPoint, i.Args.Mount)
+		}
+	}
+
+	return i.getCmdExecutor(utils.MethodValidator, fmt.Sprintf("%d '%s' %s %s", i.Args.Percent, i.Args.Bytes, i.Args.Dir, i.Args.Mode)).ExecTool(ctx)
 }
 
 func (i *FillInjector) Inject(ctx context.Context) error {
-	return i.getCmdExecutor(utils.MethodInject, fmt.Sprintf("%d '%s' %s %s", i.Args.Percent, i.Args.Bytes, i.Args.Dir, i.Info.Uid)).ExecTool(ctx)
+	var err error
+	i.Runtime.Device, i.Runtime.MountPoint, err = udisk.GetMountInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Dir)
+	if err != nil {
+		return fmt.Errorf("get mount info of \"dir\"[%s] error: %s", i.Args.Dir, err.Error())
+	}
+	log.GetLogger(ctx).Infof("fill %s of device[%s] mounted on [%s]", i.Args.Mode, i.Runtime.Device, i.Runtime.MountPoint)
+
+	return i.getCmdExecutor(utils.MethodInject, fmt.Sprintf("%d '%s' %s %s %s", i.Args.Percent, i.Args.Bytes, i.Args.Dir, i.Info.Uid, i.Args.Mode)).ExecTool(ctx)
 }
 
 func (i *FillInjector) Recover(ctx context.Context) error {
@@ -105,3 +154,38 @@ func (i *FillInjector) Recover(ctx context.Context) error {
 
 	return i.getCmdExecutor(utils.MethodRecover, fmt.Sprintf("%s %s", i.Args.Dir, i.Info.Uid)).ExecTool(ctx)
 }
+
+// GetMetrics only support host and bytes mode, the fill file of container is in the mount namespace of container
+func (i *FillInjector) GetMetrics(ctx context.Context) (map[string]float64, error) {
+	if i.Info.ContainerRuntime != "" || i.Args.Mode == ModeInode {
+		return nil, nil
+	}
+
+	fillFile := fmt.Sprintf("%s/%s%s.dat", i.Args.Dir, FillFileName, i.Info.Uid)
+	fileInfo, err := os.Stat(fillFile)
+	if err != nil {
+		return nil, fmt.Errorf("get fill file[%s] info error: %s", fillFile, err.Error())
+	}
+
+	return map[string]float64{
+		"fill_bytes": float64(fileInfo.Size()),
+	}, nil
+}
+
+// GetResource only calculate the disk percent of "bytes" on host, the dir of container is in the mount namespace of container
+func (i *FillInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	if i.Args.Percent != 0 {
+		return &injector.Resource{DiskPercent: float64(i.Args.Percent)}, nil
+	}
+
+	if i.Info.ContainerRuntime != "" || i.Args.Mode == ModeInode {
+		return nil, nil
+	}
+
+	fillPercent, err := udisk.GetFillPercent(i.Args.Dir, i.Args.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("get disk percent after fill error: %s", err.Error())
+	}
+
+	return &injector.Resource{DiskPercent: fillPercent}, nil
+}
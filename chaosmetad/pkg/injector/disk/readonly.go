@@ -0,0 +1,163 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	udisk "github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/disk"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"path/filepath"
+)
+
+func init() {
+	injector.Register(TargetDisk, FaultDiskReadonly, func() injector.IInjector { return &ReadonlyInjector{} })
+}
+
+// ReadonlyInjector bind-remount the path read-only in the mount namespace of target,
+// a bind mount is created first if the path is not a mount point
+type ReadonlyInjector struct {
+	injector.BaseInjector
+	Args    ReadonlyArgs
+	Runtime ReadonlyRuntime
+}
+
+type ReadonlyArgs struct {
+	Path string `json:"path"`
+}
+
+type ReadonlyRuntime struct {
+	Options     string `json:"options,omitempty"`
+	BindCreated bool   `json:"bind_created,omitempty"`
+}
+
+func (i *ReadonlyInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *ReadonlyInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *ReadonlyInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Path, "path", "p", "", "target path to make read-only, a mount point or a dir")
+}
+
+func (i *ReadonlyInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Path == "" {
+		return fmt.Errorf("\"path\" is empty")
+	}
+
+	if !filesys.IfPathAbs(ctx, i.Args.Path) {
+		return fmt.Errorf("\"path\" must provide absolute path")
+	}
+	i.Args.Path = filepath.Clean(i.Args.Path)
+
+	exist, err := filesys.ExistPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("check path[%s] exist error: %s", i.Args.Path, err.Error())
+	}
+
+	if !exist {
+		return fmt.Errorf("path[%s] is not exist", i.Args.Path)
+	}
+
+	options, err := udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	if udisk.IsReadOnlyOptions(options) {
+		return fmt.Errorf("mount point[%s] is already read-only", i.Args.Path)
+	}
+
+	return nil
+}
+
+func (i *ReadonlyInjector) Inject(ctx context.Context) error {
+	logger := log.GetLogger(ctx)
+	options, err := udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	if options == "" {
+		if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, fmt.Sprintf("mount --bind %s %s", i.Args.Path, i.Args.Path), []string{namespace.MNT}); err != nil {
+			return fmt.Errorf("bind mount %s error: %s", i.Args.Path, err.Error())
+		}
+		i.Runtime.BindCreated = true
+
+		if options, err = udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path); err != nil {
+			if err := i.Recover(ctx); err != nil {
+				logger.Warnf("undo error: %s", err.Error())
+			}
+			return err
+		}
+	}
+	i.Runtime.Options = options
+	logger.Debugf("origin mount options of %s: %s", i.Args.Path, options)
+
+	if err := udisk.RemountBind(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path, udisk.ReplaceRwOptions(options, "ro")); err != nil {
+		if i.Runtime.BindCreated {
+			if err := i.Recover(ctx); err != nil {
+				logger.Warnf("undo error: %s", err.Error())
+			}
+		}
+		return fmt.Errorf("remount %s read-only error: %s", i.Args.Path, err.Error())
+	}
+
+	return nil
+}
+
+func (i *ReadonlyInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.BindCreated {
+		if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, fmt.Sprintf("umount %s || umount -l %s", i.Args.Path, i.Args.Path), []string{namespace.MNT}); err != nil {
+			return fmt.Errorf("umount bind mount %s error: %s", i.Args.Path, err.Error())
+		}
+
+		return nil
+	}
+
+	if i.Runtime.Options == "" {
+		return nil
+	}
+
+	if err := udisk.RemountBind(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path, i.Runtime.Options); err != nil {
+		return fmt.Errorf("restore mount options[%s] of %s error: %s", i.Runtime.Options, i.Args.Path, err.Error())
+	}
+
+	return nil
+}
+
+func (i *ReadonlyInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
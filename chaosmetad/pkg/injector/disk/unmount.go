@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	udisk "github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/disk"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"path/filepath"
+)
+
+func init() {
+	injector.Register(TargetDisk, FaultDiskUnmount, func() injector.IInjector { return &UnmountInjector{} })
+}
+
+// UnmountInjector lazily detach the mount point in the mount namespace of target.
+// the mount is kept by a bind mount in backup dir, so that it can be mounted back on recover
+type UnmountInjector struct {
+	injector.BaseInjector
+	Args    UnmountArgs
+	Runtime UnmountRuntime
+}
+
+type UnmountArgs struct {
+	Path string `json:"path"`
+}
+
+type UnmountRuntime struct {
+	Options string `json:"options,omitempty"`
+	Backup  string `json:"backup,omitempty"`
+}
+
+func (i *UnmountInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *UnmountInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *UnmountInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Path, "path", "p", "", "target mount point to detach")
+}
+
+func (i *UnmountInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Path == "" {
+		return fmt.Errorf("\"path\" is empty")
+	}
+
+	if !filesys.IfPathAbs(ctx, i.Args.Path) {
+		return fmt.Errorf("\"path\" must provide absolute path")
+	}
+	i.Args.Path = filepath.Clean(i.Args.Path)
+
+	if i.Args.Path == "/" {
+		return fmt.Errorf("not support to unmount \"/\"")
+	}
+
+	options, err := udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	if options == "" {
+		return fmt.Errorf("path[%s] is not a mount point", i.Args.Path)
+	}
+
+	return nil
+}
+
+func (i *UnmountInjector) Inject(ctx context.Context) error {
+	var err error
+	i.Runtime.Options, err = udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	i.Runtime.Backup = fmt.Sprintf("%s_%s", UnmountBackupDir, i.Info.Uid)
+	cmd := fmt.Sprintf("mkdir -p %s && mount --rbind %s %s && umount -l %s", i.Runtime.Backup, i.Args.Path, i.Runtime.Backup, i.Args.Path)
+	if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, cmd, []string{namespace.MNT}); err != nil {
+		if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, getBackupClearCmd(i.Runtime.Backup), []string{namespace.MNT}); err != nil {
+			log.GetLogger(ctx).Warnf("clear backup mount %s error: %s", i.Runtime.Backup, err.Error())
+		}
+		return fmt.Errorf("unmount %s error: %s", i.Args.Path, err.Error())
+	}
+
+	return nil
+}
+
+func (i *UnmountInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.Backup == "" {
+		return nil
+	}
+
+	options, err := udisk.GetMountOptions(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return err
+	}
+
+	if options == "" {
+		cmd := fmt.Sprintf("mount --rbind %s %s", i.Runtime.Backup, i.Args.Path)
+		if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, cmd, []string{namespace.MNT}); err != nil {
+			return fmt.Errorf("mount %s back to %s error: %s", i.Runtime.Backup, i.Args.Path, err.Error())
+		}
+	}
+
+	if err := udisk.RemountBind(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path, i.Runtime.Options); err != nil {
+		return fmt.Errorf("restore mount options[%s] of %s error: %s", i.Runtime.Options, i.Args.Path, err.Error())
+	}
+
+	if _, err := cmdexec.ExecCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, getBackupClearCmd(i.Runtime.Backup), []string{namespace.MNT}); err != nil {
+		return fmt.Errorf("clear backup mount %s error: %s", i.Runtime.Backup, err.Error())
+	}
+
+	return nil
+}
+
+func (i *UnmountInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
+
+func getBackupClearCmd(backup string) string {
+	return fmt.Sprintf("if [ -d %s ]; then (umount -l %s || true) && rmdir %s; fi", backup, backup, backup)
+}
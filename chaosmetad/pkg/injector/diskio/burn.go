@@ -75,6 +75,7 @@ func (i *BurnInjector) SetDefault() {
 func (i *BurnInjector) SetOption(cmd *cobra.Command) {
 	//// i.BaseInjector.SetOption(cmd)
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("disk IO mode, support: %s、%s（default %s）", ModeRead, ModeWrite, ModeRead))
+	injector.SetFlagEnum(cmd, "mode", ModeRead, ModeWrite)
 	cmd.Flags().StringVarP(&i.Args.Block, "block", "b", "", fmt.Sprintf("disk IO block size（default %s）, support unit: KB/MB（default KB）", DefaultBlockSize))
 	cmd.Flags().StringVarP(&i.Args.Dir, "dir", "d", "", fmt.Sprintf("disk IO burn directory（default %s）", DefaultDir))
 }
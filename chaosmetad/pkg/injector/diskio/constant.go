@@ -35,6 +35,12 @@ const (
 	ModeWrite       = "write"
 	ModeAll         = "all"
 
+	FaultDiskIODelay    = "delay"
+	DelayModeDelay      = "delay"
+	DelayModeError      = "error"
+	DmNamePrefix        = "chaosmeta_diskio_"
+	DefaultDownInterval = 1
+
 	TmpCgroup = "/user.slice"
 
 	DiskIOExec = "chaosmeta_diskio"
@@ -0,0 +1,297 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package diskio
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/disk"
+	"strings"
+	"time"
+)
+
+func init() {
+	injector.Register(TargetDiskIO, FaultDiskIODelay, func() injector.IInjector { return &DelayInjector{} })
+}
+
+// DelayInjector add latency to every I/O of a block device with dm-delay, or fail the I/O with dm-flakey in error mode.
+// the device-mapper is global, so "device" is a block device path of the host.
+// if the device is a device-mapper device(eg: lvm), its table is swapped in place so that the existing users are affected,
+// otherwise a new device "/dev/mapper/chaosmeta_diskio_[uid]" is created on it, which is used instead of the device
+type DelayInjector struct {
+	injector.BaseInjector
+	Args    DelayArgs
+	Runtime DelayRuntime
+}
+
+type DelayArgs struct {
+	Device       string `json:"device"`
+	Mode         string `json:"mode"`
+	ReadDelay    string `json:"read_delay,omitempty"`
+	WriteDelay   string `json:"write_delay,omitempty"`
+	ErrorIO      string `json:"error_io,omitempty"`
+	UpInterval   int64  `json:"up_interval,omitempty"`
+	DownInterval int64  `json:"down_interval,omitempty"`
+}
+
+type DelayRuntime struct {
+	Name   string `json:"name"`
+	Target string `json:"target,omitempty"`
+	Table  string `json:"table,omitempty"`
+}
+
+func (i *DelayInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *DelayInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *DelayInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = DelayModeDelay
+	}
+
+	if i.Args.Mode == DelayModeError {
+		if i.Args.ErrorIO == "" {
+			i.Args.ErrorIO = ModeAll
+		}
+
+		if i.Args.DownInterval == 0 {
+			i.Args.DownInterval = DefaultDownInterval
+		}
+	}
+}
+
+func (i *DelayInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Device, "device", "d", "", "target block device path of the host, eg: /dev/loop0、/dev/mapper/vg-data")
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s(dm-delay)、%s(dm-flakey)（default %s）", DelayModeDelay, DelayModeError, DelayModeDelay))
+	injector.SetFlagEnum(cmd, "mode", DelayModeDelay, DelayModeError)
+	cmd.Flags().StringVar(&i.Args.ReadDelay, "read-delay", "", fmt.Sprintf("mode %s: delay of every read, the unit is milliseconds at least, eg: 50ms、1s", DelayModeDelay))
+	cmd.Flags().StringVar(&i.Args.WriteDelay, "write-delay", "", fmt.Sprintf("mode %s: delay of every write, the unit is milliseconds at least, eg: 50ms、1s", DelayModeDelay))
+	cmd.Flags().StringVar(&i.Args.ErrorIO, "error-io", "", fmt.Sprintf("mode %s: target IO to fail, support: %s、%s、%s（default %s）", DelayModeError, ModeAll, ModeRead, ModeWrite, ModeAll))
+	injector.SetFlagEnum(cmd, "error-io", ModeAll, ModeRead, ModeWrite)
+	cmd.Flags().Int64Var(&i.Args.UpInterval, "up-interval", 0, fmt.Sprintf("mode %s: seconds of the I/O succeed in a cycle, 0 means always fail", DelayModeError))
+	cmd.Flags().Int64Var(&i.Args.DownInterval, "down-interval", 0, fmt.Sprintf("mode %s: seconds of the I/O fail in a cycle（default %d）", DelayModeError, DefaultDownInterval))
+}
+
+func getDelayMs(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d.Milliseconds() <= 0 {
+		return 0, fmt.Errorf("\"%s\"[%s] is not a valid duration of at least 1ms", name, value)
+	}
+
+	return d.Milliseconds(), nil
+}
+
+func (i *DelayInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Device == "" {
+		return fmt.Errorf("\"device\" must provide")
+	}
+
+	dmName, err := disk.GetDmName(i.Args.Device)
+	if err != nil {
+		return fmt.Errorf("\"device\"[%s] is invalid: %s", i.Args.Device, err.Error())
+	}
+
+	if strings.HasPrefix(dmName, DmNamePrefix) {
+		return fmt.Errorf("\"device\"[%s] is created by other experiment, please inject to the origin device", i.Args.Device)
+	}
+
+	if !cmdexec.SupportCmd("dmsetup") {
+		return fmt.Errorf("not support command \"dmsetup\"")
+	}
+
+	if dmName != "" {
+		table, err := disk.GetDmTable(ctx, dmName)
+		if err != nil {
+			return fmt.Errorf("get table of device[%s] error: %s", dmName, err.Error())
+		}
+
+		if err := checkSwapTable(dmName, table); err != nil {
+			return err
+		}
+	}
+
+	var target string
+	switch i.Args.Mode {
+	case DelayModeDelay:
+		target = disk.DmTargetDelay
+		if i.Args.ReadDelay == "" && i.Args.WriteDelay == "" {
+			return fmt.Errorf("must provide at least one args of: read-delay、write-delay")
+		}
+
+		if _, err := getDelayMs("read-delay", i.Args.ReadDelay); err != nil {
+			return err
+		}
+
+		if _, err := getDelayMs("write-delay", i.Args.WriteDelay); err != nil {
+			return err
+		}
+	case DelayModeError:
+		target = disk.DmTargetFlakey
+		if i.Args.ErrorIO != ModeAll && i.Args.ErrorIO != ModeRead && i.Args.ErrorIO != ModeWrite {
+			return fmt.Errorf("\"error-io\" is not support: %s, only support: %s、%s、%s", i.Args.ErrorIO, ModeAll, ModeRead, ModeWrite)
+		}
+
+		if i.Args.UpInterval < 0 || i.Args.DownInterval <= 0 {
+			return fmt.Errorf("\"up-interval\" must be larger than or equal to 0, and \"down-interval\" must be larger than 0")
+		}
+	default:
+		return fmt.Errorf("\"mode\" is not support: %s, only support: %s、%s", i.Args.Mode, DelayModeDelay, DelayModeError)
+	}
+
+	support, err := disk.SupportDmTarget(ctx, target)
+	if err != nil {
+		return fmt.Errorf("check device-mapper target error: %s", err.Error())
+	}
+
+	if !support {
+		return fmt.Errorf("device-mapper target \"%s\" is not supported by the kernel, try \"modprobe dm_%s\"", target, target)
+	}
+
+	return nil
+}
+
+// getTable return the fault table on the device
+func (i *DelayInjector) getTable(dev string, sectors int64) string {
+	if i.Args.Mode == DelayModeDelay {
+		readDelay, _ := getDelayMs("read-delay", i.Args.ReadDelay)
+		writeDelay, _ := getDelayMs("write-delay", i.Args.WriteDelay)
+		return disk.GetDelayTable(dev, sectors, readDelay, writeDelay)
+	}
+
+	var features []string
+	if i.Args.ErrorIO == ModeRead {
+		features = append(features, disk.FlakeyErrorReads)
+	} else if i.Args.ErrorIO == ModeWrite {
+		features = append(features, disk.FlakeyErrorWrites)
+	}
+
+	return disk.GetFlakeyTable(dev, sectors, i.Args.UpInterval, i.Args.DownInterval, features...)
+}
+
+// checkSwapTable only the stateless targets can be moved to a new device, the targets holding metadata or exclusive
+// access of the underlying devices(eg: crypt、thin-pool、raid、snapshot) can not be loaded twice at the same time
+func checkSwapTable(dmName, table string) error {
+	targets, err := disk.GetTableTargets(table)
+	if err != nil {
+		return fmt.Errorf("parse table of device[%s] error: %s", dmName, err.Error())
+	}
+
+	for _, target := range targets {
+		if target != disk.DmTargetLinear && target != disk.DmTargetStriped {
+			return fmt.Errorf("device-mapper target \"%s\" of device[%s] is not support, only support: %s、%s", target, dmName, disk.DmTargetLinear, disk.DmTargetStriped)
+		}
+	}
+
+	return nil
+}
+
+func (i *DelayInjector) Inject(ctx context.Context) error {
+	logger := log.GetLogger(ctx)
+	sectors, err := disk.GetBlockSectors(ctx, i.Args.Device)
+	if err != nil {
+		return fmt.Errorf("get size of device[%s] error: %s", i.Args.Device, err.Error())
+	}
+
+	dmName, _ := disk.GetDmName(i.Args.Device)
+	name := DmNamePrefix + i.Info.Uid
+	if dmName == "" {
+		if err := disk.CreateDm(ctx, name, i.getTable(i.Args.Device, sectors)); err != nil {
+			return fmt.Errorf("create device[%s] error: %s", name, err.Error())
+		}
+		i.Runtime.Name = name
+		logger.Infof("use device[%s] to access the injected device", disk.GetDmPath(name))
+
+		return nil
+	}
+
+	// the origin table is moved to a new device, and the target device is reloaded to inject on the new device
+	table, err := disk.GetDmTable(ctx, dmName)
+	if err != nil {
+		return fmt.Errorf("get table of device[%s] error: %s", dmName, err.Error())
+	}
+
+	if err := checkSwapTable(dmName, table); err != nil {
+		return err
+	}
+
+	if err := disk.CreateDm(ctx, name, table); err != nil {
+		return fmt.Errorf("create device[%s] with origin table error: %s", name, err.Error())
+	}
+	i.Runtime.Name, i.Runtime.Target, i.Runtime.Table = name, dmName, table
+
+	devNum, err := disk.GetDmDevNum(ctx, name)
+	if err == nil {
+		err = disk.ReloadDm(ctx, dmName, i.getTable(devNum, sectors))
+	}
+
+	if err != nil {
+		if err := disk.RemoveDm(ctx, name); err != nil {
+			logger.Warnf("undo: remove device[%s] error: %s", name, err.Error())
+		}
+
+		return fmt.Errorf("reload device[%s] error: %s", dmName, err.Error())
+	}
+
+	return nil
+}
+
+func (i *DelayInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.Target != "" {
+		if err := disk.ReloadDm(ctx, i.Runtime.Target, i.Runtime.Table); err != nil {
+			return fmt.Errorf("reload origin table of device[%s] error: %s", i.Runtime.Target, err.Error())
+		}
+	}
+
+	if i.Runtime.Name != "" {
+		if err := disk.RemoveDm(ctx, i.Runtime.Name); err != nil {
+			return fmt.Errorf("remove device[%s] error: %s, please make sure it is not in use", i.Runtime.Name, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *DelayInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	devNum, err := disk.GetDevNum(i.Args.Device)
+	if err != nil {
+		return nil, fmt.Errorf("get device number of %s error: %s", i.Args.Device, err.Error())
+	}
+
+	return &injector.Resource{Devices: []string{devNum}}, nil
+}
@@ -76,6 +76,7 @@ func (i *HangInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Key, "key", "k", "", "the key used to grep to get target process, the effect is equivalent to \"ps -ef | grep [key]\". if \"pid-list\" provided, \"key\" will be ignored")
 	cmd.Flags().StringVarP(&i.Args.DevList, "dev-list", "d", "", "target dev list, dev represent format: \"major-dev-num:minor-dev-num\",  use \"lsblk -a | grep disk\" to get dev num, eg:\"8:0,9:1\"\"")
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("target IO mode to hang, support: %s、%s、%s（default %s）", ModeAll, ModeRead, ModeWrite, ModeAll))
+	injector.SetFlagEnum(cmd, "mode", ModeAll, ModeRead, ModeWrite)
 }
 
 func (i *HangInjector) Validator(ctx context.Context) error {
@@ -208,3 +209,17 @@ func (i *HangInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *HangInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	pidList, err := process.GetPidListByListStrAndKey(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.PidList, i.Args.Key)
+	if err != nil {
+		return nil, fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	r := &injector.Resource{Pids: pidList, Devices: strings.Split(i.Args.DevList, ",")}
+	if i.Args.PidList == "" {
+		r.ProcessKeys = []string{i.Args.Key}
+	}
+
+	return r, nil
+}
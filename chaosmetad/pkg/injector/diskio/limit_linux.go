@@ -210,3 +210,17 @@ func (i *LimitInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *LimitInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	pidList, err := process.GetPidListByListStrAndKey(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.PidList, i.Args.Key)
+	if err != nil {
+		return nil, fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	r := &injector.Resource{Pids: pidList, Devices: strings.Split(i.Args.DevList, ",")}
+	if i.Args.PidList == "" {
+		r.ProcessKeys = []string{i.Args.Key}
+	}
+
+	return r, nil
+}
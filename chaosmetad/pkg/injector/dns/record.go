@@ -64,6 +64,7 @@ func (i *RecordInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Domain, "domain", "d", "", "dns record's domain")
 	cmd.Flags().StringVarP(&i.Args.Ip, "ip", "i", "", "dns record's ip")
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s, %s", ModeAdd, ModeDelete))
+	injector.SetFlagEnum(cmd, "mode", ModeAdd, ModeDelete)
 }
 
 // Validator delete: cannot delete records that have been deleted
@@ -100,6 +101,12 @@ func (i *RecordInjector) Inject(ctx context.Context) error {
 	return err
 }
 
+func (i *RecordInjector) GetVerifyScope(ctx context.Context) (*injector.VerifyScope, error) {
+	return &injector.VerifyScope{
+		Files: []string{ConfRecord},
+	}, nil
+}
+
 func (i *RecordInjector) Recover(ctx context.Context) error {
 	if i.BaseInjector.Recover(ctx) == nil {
 		return nil
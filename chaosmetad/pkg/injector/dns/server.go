@@ -62,6 +62,7 @@ func (i *ServerInjector) SetDefault() {
 func (i *ServerInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Ip, "ip", "i", "", "dns server's ip")
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s, %s", ModeAdd, ModeDelete))
+	injector.SetFlagEnum(cmd, "mode", ModeAdd, ModeDelete)
 }
 
 // Validator delete: cannot delete records that have been deleted
@@ -94,6 +95,12 @@ func (i *ServerInjector) Inject(ctx context.Context) error {
 	return err
 }
 
+func (i *ServerInjector) GetVerifyScope(ctx context.Context) (*injector.VerifyScope, error) {
+	return &injector.VerifyScope{
+		Files: []string{ConfServer},
+	}, nil
+}
+
 func (i *ServerInjector) Recover(ctx context.Context) error {
 	if i.BaseInjector.Recover(ctx) == nil {
 		return nil
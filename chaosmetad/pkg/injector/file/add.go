@@ -161,3 +161,9 @@ func (i *AddInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *AddInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
@@ -148,3 +148,9 @@ func (i *AppendInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *AppendInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
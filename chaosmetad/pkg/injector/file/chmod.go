@@ -116,3 +116,9 @@ func (i *ChmodInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *ChmodInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
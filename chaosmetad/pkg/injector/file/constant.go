@@ -33,15 +33,35 @@ const (
 	FaultFileDelete = "del"
 
 	FaultFileChmod = "chmod"
+
+	FaultFileCorrupt = "corrupt"
+
+	FaultFileLock = "lock"
 	//FileExec       = "chaosmeta_file"
 
 	BackUpDir = "/tmp/chaosmeta_backup_file"
+
+	ModeBitFlip      = "bitflip"
+	ModeZero         = "zero"
+	ModeTruncate     = "truncate"
+	CorruptBackupDir = "chaosmeta_corrupt"
+
+	LockTypeFlock     = "flock"
+	LockTypeFcntl     = "fcntl"
+	LockModeShared    = "shared"
+	LockModeExclusive = "exclusive"
+	FileLockKey       = "chaosmeta_filelock"
 )
 
 func getAppendFlag(uid string) string {
 	return fmt.Sprintf(" %s-%s", utils.RootName, uid)
 }
 
+// getCorruptBackupFile the backup of corrupt is in the run path, so it is also available for the file of container
+func getCorruptBackupFile(uid string) string {
+	return fmt.Sprintf("%s/%s/%s.gz", utils.GetRunPath(), CorruptBackupDir, uid)
+}
+
 func getBackupDir(uid string) string {
 	return fmt.Sprintf("%s%s", BackUpDir, uid)
 }
@@ -0,0 +1,451 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"compress/gzip"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
+	"io"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+const corruptBufSize = 32 * 1024
+
+func init() {
+	injector.Register(TargetFile, FaultFileCorrupt, func() injector.IInjector { return &CorruptInjector{} })
+}
+
+type CorruptInjector struct {
+	injector.BaseInjector
+	Args    CorruptArgs
+	Runtime CorruptRuntime
+}
+
+type CorruptArgs struct {
+	Path   string `json:"path"`
+	Mode   string `json:"mode"`
+	Offset string `json:"offset,omitempty"`
+	Length string `json:"length,omitempty"`
+	Count  int    `json:"count,omitempty"`
+}
+
+// CorruptRuntime only the original bytes of Ranges are saved in Backup
+type CorruptRuntime struct {
+	Backup   string      `json:"backup,omitempty"`
+	Ranges   []ByteRange `json:"ranges,omitempty"`
+	Size     int64       `json:"size"`
+	Checksum string      `json:"checksum,omitempty"`
+}
+
+type ByteRange struct {
+	Offset int64 `json:"offset"`
+	Length int64 `json:"length"`
+}
+
+func (i *CorruptInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *CorruptInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *CorruptInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Path, "path", "p", "", "file path, include dir and file name")
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("corrupt mode, support: %s(flip random bits)、%s(set a range to zero)、%s(truncate at \"offset\")（default %s）", ModeBitFlip, ModeZero, ModeTruncate, ModeBitFlip))
+	injector.SetFlagEnum(cmd, "mode", ModeBitFlip, ModeZero, ModeTruncate)
+	cmd.Flags().StringVarP(&i.Args.Offset, "offset", "o", "", "start offset of the corrupt range, support unit: B/KB/MB/GB/TB（default 0, unit B）")
+	cmd.Flags().StringVarP(&i.Args.Length, "length", "l", "", "length of the corrupt range, support unit: B/KB/MB/GB/TB（default to the end of file, unit B）")
+	cmd.Flags().IntVarP(&i.Args.Count, "count", "c", 0, fmt.Sprintf("count of bits to flip, only for mode \"%s\"（default 1）", ModeBitFlip))
+}
+
+func (i *CorruptInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = ModeBitFlip
+	}
+
+	if i.Args.Mode == ModeBitFlip && i.Args.Count == 0 {
+		i.Args.Count = 1
+	}
+}
+
+func (i *CorruptInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Path == "" {
+		return fmt.Errorf("\"path\" is empty")
+	}
+
+	if !filesys.IfPathAbs(ctx, i.Args.Path) {
+		return fmt.Errorf("\"path\" must provide absolute path")
+	}
+
+	if i.Args.Mode != ModeBitFlip && i.Args.Mode != ModeZero && i.Args.Mode != ModeTruncate {
+		return fmt.Errorf("\"mode\" is not support: %s, only support: %s、%s、%s", i.Args.Mode, ModeBitFlip, ModeZero, ModeTruncate)
+	}
+
+	if i.Args.Mode != ModeBitFlip && i.Args.Count != 0 {
+		return fmt.Errorf("\"count\" is only support in mode \"%s\"", ModeBitFlip)
+	}
+
+	if i.Args.Mode == ModeTruncate && i.Args.Length != "" {
+		return fmt.Errorf("\"length\" is not support in mode \"%s\"", ModeTruncate)
+	}
+
+	if i.Args.Count < 0 {
+		return fmt.Errorf("\"count\" can not be less than 0")
+	}
+
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	fileInfo, err := os.Stat(hostPath)
+	if err != nil {
+		return fmt.Errorf("get info of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return fmt.Errorf("file[%s] is not a regular file", i.Args.Path)
+	}
+
+	offset, length, err := i.getRange(fileInfo.Size())
+	if err != nil {
+		return err
+	}
+
+	if i.Args.Mode == ModeBitFlip && int64(i.Args.Count) > length*8 {
+		return fmt.Errorf("\"count\"[%d] is more than the bits of the range[%d, %d)", i.Args.Count, offset, offset+length)
+	}
+
+	return nil
+}
+
+// getRange return the offset and length of the range to corrupt
+func (i *CorruptInjector) getRange(size int64) (int64, int64, error) {
+	var offset, length int64
+	var err error
+	if i.Args.Offset != "" {
+		if offset, err = utils.GetBytes(i.Args.Offset); err != nil {
+			return -1, -1, fmt.Errorf("\"offset\"[%s] is invalid: %s", i.Args.Offset, err.Error())
+		}
+	}
+
+	if offset >= size {
+		return -1, -1, fmt.Errorf("\"offset\"[%d] must be less than the file size[%d]", offset, size)
+	}
+
+	if i.Args.Length == "" {
+		return offset, size - offset, nil
+	}
+
+	if length, err = utils.GetBytes(i.Args.Length); err != nil {
+		return -1, -1, fmt.Errorf("\"length\"[%s] is invalid: %s", i.Args.Length, err.Error())
+	}
+
+	if length <= 0 {
+		return -1, -1, fmt.Errorf("\"length\" must be larger than 0")
+	}
+
+	if offset+length > size {
+		return -1, -1, fmt.Errorf("range[%d, %d) is out of the file size[%d]", offset, offset+length, size)
+	}
+
+	return offset, length, nil
+}
+
+func (i *CorruptInjector) Inject(ctx context.Context) error {
+	logger := log.GetLogger(ctx)
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	f, err := os.OpenFile(hostPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open file[%s] error: %s", i.Args.Path, err.Error())
+	}
+	defer f.Close()
+
+	fileInfo, err := f.Stat()
+	if err != nil {
+		return fmt.Errorf("get info of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	offset, length, err := i.getRange(fileInfo.Size())
+	if err != nil {
+		return err
+	}
+
+	checksum, err := getChecksum(f)
+	if err != nil {
+		return fmt.Errorf("get checksum of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	var flipMasks map[int64]byte
+	switch i.Args.Mode {
+	case ModeBitFlip:
+		flipMasks = getFlipMasks(rand.New(rand.NewSource(time.Now().UnixNano())), offset, length, i.Args.Count)
+		i.Runtime.Ranges = getByteRanges(flipMasks)
+	case ModeZero:
+		i.Runtime.Ranges = []ByteRange{{Offset: offset, Length: length}}
+	case ModeTruncate:
+		i.Runtime.Ranges = []ByteRange{{Offset: offset, Length: fileInfo.Size() - offset}}
+	}
+
+	i.Runtime.Backup, i.Runtime.Size, i.Runtime.Checksum = getCorruptBackupFile(i.Info.Uid), fileInfo.Size(), checksum
+	if err := backupRanges(f, i.Runtime.Ranges, i.Runtime.Backup); err != nil {
+		if err := os.Remove(i.Runtime.Backup); err != nil && !os.IsNotExist(err) {
+			logger.Warnf("undo: remove backup file[%s] error: %s", i.Runtime.Backup, err.Error())
+		}
+		return fmt.Errorf("backup file[%s] error: %s", i.Args.Path, err.Error())
+	}
+	logger.Debugf("backup %d ranges of file[%s] to %s", len(i.Runtime.Ranges), i.Args.Path, i.Runtime.Backup)
+
+	if err := corruptFile(f, i.Args.Mode, offset, length, flipMasks); err != nil {
+		if err := restoreFile(f, &i.Runtime); err != nil {
+			logger.Warnf("undo: restore file[%s] error: %s", i.Args.Path, err.Error())
+		}
+		return fmt.Errorf("corrupt file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	return nil
+}
+
+func (i *CorruptInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.Backup == "" {
+		return nil
+	}
+
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	f, err := os.OpenFile(hostPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("open file[%s] error: %s", i.Args.Path, err.Error())
+	}
+	defer f.Close()
+
+	if err := restoreFile(f, &i.Runtime); err != nil {
+		return fmt.Errorf("restore file[%s] error: %s, backup is kept in: %s", i.Args.Path, err.Error(), i.Runtime.Backup)
+	}
+
+	if err := os.Remove(i.Runtime.Backup); err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("remove backup file[%s] error: %s", i.Runtime.Backup, err.Error())
+	}
+
+	return nil
+}
+
+func (i *CorruptInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
+
+// getFlipMasks choose count different bits in the range, return the xor mask of each byte offset
+func getFlipMasks(r *rand.Rand, offset, length int64, count int) map[int64]byte {
+	var (
+		masks  = make(map[int64]byte)
+		chosen = make(map[int64]bool)
+	)
+
+	for len(chosen) < count {
+		bit := r.Int63n(length * 8)
+		if chosen[bit] {
+			continue
+		}
+
+		chosen[bit] = true
+		masks[offset+bit/8] |= 1 << (bit % 8)
+	}
+
+	return masks
+}
+
+// getByteRanges merge the continuous byte offsets to ranges
+func getByteRanges(masks map[int64]byte) []ByteRange {
+	offsets := make([]int64, 0, len(masks))
+	for offset := range masks {
+		offsets = append(offsets, offset)
+	}
+	sort.Slice(offsets, func(i, j int) bool { return offsets[i] < offsets[j] })
+
+	var ranges []ByteRange
+	for _, offset := range offsets {
+		if len(ranges) != 0 {
+			last := &ranges[len(ranges)-1]
+			if last.Offset+last.Length == offset {
+				last.Length++
+				continue
+			}
+		}
+
+		ranges = append(ranges, ByteRange{Offset: offset, Length: 1})
+	}
+
+	return ranges
+}
+
+func getChecksum(f *os.File) (string, error) {
+	h := sha256.New()
+	if _, err := io.Copy(h, io.NewSectionReader(f, 0, 1<<62)); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+// backupRanges save the original bytes of ranges in order to a gzip file
+func backupRanges(f *os.File, ranges []ByteRange, backupFile string) error {
+	if err := os.MkdirAll(filepath.Dir(backupFile), 0755); err != nil {
+		return fmt.Errorf("create backup dir error: %s", err.Error())
+	}
+
+	bf, err := os.OpenFile(backupFile, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+	if err != nil {
+		return fmt.Errorf("create backup file error: %s", err.Error())
+	}
+	defer bf.Close()
+
+	gw := gzip.NewWriter(bf)
+	for _, unit := range ranges {
+		if _, err := io.Copy(gw, io.NewSectionReader(f, unit.Offset, unit.Length)); err != nil {
+			return fmt.Errorf("backup range[%d, %d) error: %s", unit.Offset, unit.Offset+unit.Length, err.Error())
+		}
+	}
+
+	if err := gw.Close(); err != nil {
+		return fmt.Errorf("close gzip writer error: %s", err.Error())
+	}
+
+	return bf.Sync()
+}
+
+func corruptFile(f *os.File, mode string, offset, length int64, flipMasks map[int64]byte) error {
+	switch mode {
+	case ModeBitFlip:
+		var b = make([]byte, 1)
+		for unitOffset, mask := range flipMasks {
+			if _, err := f.ReadAt(b, unitOffset); err != nil {
+				return fmt.Errorf("read byte at %d error: %s", unitOffset, err.Error())
+			}
+
+			b[0] ^= mask
+			if _, err := f.WriteAt(b, unitOffset); err != nil {
+				return fmt.Errorf("write byte at %d error: %s", unitOffset, err.Error())
+			}
+		}
+	case ModeZero:
+		var zero = make([]byte, corruptBufSize)
+		for end := offset + length; offset < end; {
+			n := end - offset
+			if n > corruptBufSize {
+				n = corruptBufSize
+			}
+
+			if _, err := f.WriteAt(zero[:n], offset); err != nil {
+				return fmt.Errorf("write zero at %d error: %s", offset, err.Error())
+			}
+			offset += n
+		}
+	case ModeTruncate:
+		if err := f.Truncate(offset); err != nil {
+			return fmt.Errorf("truncate at %d error: %s", offset, err.Error())
+		}
+	default:
+		return fmt.Errorf("not support mode: %s", mode)
+	}
+
+	return f.Sync()
+}
+
+// restoreFile write back the original bytes of ranges and check the checksum
+func restoreFile(f *os.File, r *CorruptRuntime) error {
+	bf, err := os.Open(r.Backup)
+	if err != nil {
+		return fmt.Errorf("open backup file error: %s", err.Error())
+	}
+	defer bf.Close()
+
+	gr, err := gzip.NewReader(bf)
+	if err != nil {
+		return fmt.Errorf("create gzip reader error: %s", err.Error())
+	}
+	defer gr.Close()
+
+	if err := f.Truncate(r.Size); err != nil {
+		return fmt.Errorf("truncate to size[%d] error: %s", r.Size, err.Error())
+	}
+
+	var buf = make([]byte, corruptBufSize)
+	for _, unit := range r.Ranges {
+		for offset, end := unit.Offset, unit.Offset+unit.Length; offset < end; {
+			n := end - offset
+			if n > corruptBufSize {
+				n = corruptBufSize
+			}
+
+			if _, err := io.ReadFull(gr, buf[:n]); err != nil {
+				return fmt.Errorf("read backup of range[%d, %d) error: %s", unit.Offset, end, err.Error())
+			}
+
+			if _, err := f.WriteAt(buf[:n], offset); err != nil {
+				return fmt.Errorf("write at %d error: %s", offset, err.Error())
+			}
+			offset += n
+		}
+	}
+
+	if err := f.Sync(); err != nil {
+		return fmt.Errorf("sync error: %s", err.Error())
+	}
+
+	checksum, err := getChecksum(f)
+	if err != nil {
+		return fmt.Errorf("get checksum error: %s", err.Error())
+	}
+
+	if checksum != r.Checksum {
+		return fmt.Errorf("checksum[%s] is not equal to the original checksum[%s]", checksum, r.Checksum)
+	}
+
+	return nil
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"bytes"
+	"context"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func Test_getByteRanges(t *testing.T) {
+	got := getByteRanges(map[int64]byte{9: 1, 3: 2, 4: 8, 5: 1, 20: 4})
+	want := []ByteRange{{Offset: 3, Length: 3}, {Offset: 9, Length: 1}, {Offset: 20, Length: 1}}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getByteRanges() = %v, want %v", got, want)
+	}
+}
+
+func Test_getFlipMasks(t *testing.T) {
+	masks := getFlipMasks(rand.New(rand.NewSource(1)), 100, 4, 32)
+	if len(masks) != 4 {
+		t.Fatalf("getFlipMasks() len = %d, want 4", len(masks))
+	}
+
+	for offset := int64(100); offset < 104; offset++ {
+		if masks[offset] != 0xff {
+			t.Errorf("getFlipMasks() mask of %d = %x, want ff", offset, masks[offset])
+		}
+	}
+}
+
+func TestCorruptInjector_InjectAndRecover(t *testing.T) {
+	original := bytes.Repeat([]byte("chaosmeta corrupt test\n"), 1000)
+	tests := []struct {
+		name string
+		args CorruptArgs
+	}{
+		{name: "bitflip", args: CorruptArgs{Mode: ModeBitFlip, Offset: "100", Length: "1kb", Count: 50}},
+		{name: "zero", args: CorruptArgs{Mode: ModeZero, Offset: "10", Length: "20000"}},
+		{name: "truncate", args: CorruptArgs{Mode: ModeTruncate, Offset: "1000"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data")
+			if err := os.WriteFile(path, original, 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			i := &CorruptInjector{Args: tt.args}
+			i.Args.Path = path
+			i.Info.Uid = utils.NewUid()
+			i.SetDefault()
+			ctx := context.Background()
+			if err := i.Validator(ctx); err != nil {
+				t.Fatalf("Validator() error = %v", err)
+			}
+
+			if err := i.Inject(ctx); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+
+			if content, _ := os.ReadFile(path); bytes.Equal(content, original) {
+				t.Fatalf("file is not corrupted")
+			}
+
+			i.Info.Status = utils.StatusSuccess
+			if err := i.Recover(ctx); err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+
+			if content, _ := os.ReadFile(path); !bytes.Equal(content, original) {
+				t.Errorf("file is not restored")
+			}
+
+			if _, err := os.Stat(i.Runtime.Backup); !os.IsNotExist(err) {
+				t.Errorf("backup file[%s] is not removed", i.Runtime.Backup)
+			}
+		})
+	}
+}
@@ -107,3 +107,9 @@ func (i *DeleteInjector) Recover(ctx context.Context) error {
 
 	return filesys.RemoveRF(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, getBackupDir(i.Info.Uid))
 }
+
+func (i *DeleteInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
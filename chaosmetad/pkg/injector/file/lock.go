@@ -0,0 +1,212 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"os"
+)
+
+func init() {
+	injector.Register(TargetFile, FaultFileLock, func() injector.IInjector { return &LockInjector{} })
+}
+
+type LockInjector struct {
+	injector.BaseInjector
+	Args    LockArgs
+	Runtime LockRuntime
+}
+
+type LockArgs struct {
+	Path   string `json:"path"`
+	Type   string `json:"type"`
+	Mode   string `json:"mode"`
+	Start  int64  `json:"start,omitempty"`
+	Length int64  `json:"length,omitempty"`
+	Wait   string `json:"wait,omitempty"`
+}
+
+type LockRuntime struct {
+	Created bool `json:"created,omitempty"`
+}
+
+func (i *LockInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *LockInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *LockInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Path, "path", "p", "", "file path to lock, include dir and file name, it will be created if not exist")
+	cmd.Flags().StringVarP(&i.Args.Type, "type", "T", "", fmt.Sprintf("lock type, support: %s(lock the whole file)、%s(lock a range by posix record lock)（default %s）", LockTypeFlock, LockTypeFcntl, LockTypeFlock))
+	injector.SetFlagEnum(cmd, "type", LockTypeFlock, LockTypeFcntl)
+	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("lock mode, support: %s、%s（default %s）", LockModeShared, LockModeExclusive, LockModeExclusive))
+	injector.SetFlagEnum(cmd, "mode", LockModeShared, LockModeExclusive)
+	cmd.Flags().Int64VarP(&i.Args.Start, "start", "s", 0, fmt.Sprintf("start offset of the lock range, only for type \"%s\"", LockTypeFcntl))
+	cmd.Flags().Int64VarP(&i.Args.Length, "length", "l", 0, fmt.Sprintf("length of the lock range, only for type \"%s\"（default 0, means to the end of file）", LockTypeFcntl))
+	cmd.Flags().StringVarP(&i.Args.Wait, "wait", "w", "", "max time to wait if the lock is held by other process, support unit: \"s、m、h\"(default s)（default 0, means fail immediately）")
+}
+
+func (i *LockInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Type == "" {
+		i.Args.Type = LockTypeFlock
+	}
+
+	if i.Args.Mode == "" {
+		i.Args.Mode = LockModeExclusive
+	}
+}
+
+func (i *LockInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Path == "" {
+		return fmt.Errorf("\"path\" is empty")
+	}
+
+	if !filesys.IfPathAbs(ctx, i.Args.Path) {
+		return fmt.Errorf("\"path\" must provide absolute path")
+	}
+
+	if i.Args.Type != LockTypeFlock && i.Args.Type != LockTypeFcntl {
+		return fmt.Errorf("\"type\" is not support: %s, only support: %s、%s", i.Args.Type, LockTypeFlock, LockTypeFcntl)
+	}
+
+	if i.Args.Mode != LockModeShared && i.Args.Mode != LockModeExclusive {
+		return fmt.Errorf("\"mode\" is not support: %s, only support: %s、%s", i.Args.Mode, LockModeShared, LockModeExclusive)
+	}
+
+	if i.Args.Type == LockTypeFlock && (i.Args.Start != 0 || i.Args.Length != 0) {
+		return fmt.Errorf("\"start\" and \"length\" are only support in type \"%s\"", LockTypeFcntl)
+	}
+
+	if i.Args.Start < 0 || i.Args.Length < 0 {
+		return fmt.Errorf("\"start\" and \"length\" can not be less than 0")
+	}
+
+	if i.Args.Wait != "" {
+		if _, err := utils.GetTimeSecond(i.Args.Wait); err != nil {
+			return fmt.Errorf("\"wait\" is invalid: %s", err.Error())
+		}
+	}
+
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	fileInfo, err := os.Stat(hostPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil
+		}
+		return fmt.Errorf("get info of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	if !fileInfo.Mode().IsRegular() {
+		return fmt.Errorf("file[%s] is not a regular file", i.Args.Path)
+	}
+
+	return nil
+}
+
+func getFileLockKey(uid string) string {
+	return fmt.Sprintf("%s %s", FileLockKey, uid)
+}
+
+// Inject the holder process locks the file of container by the path under "/proc/[pid]/root", which is the same inode in the mount namespace of container
+func (i *LockInjector) Inject(ctx context.Context) error {
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	f, err := os.OpenFile(hostPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err == nil {
+		i.Runtime.Created = true
+		_ = f.Close()
+	} else if !os.IsExist(err) {
+		return fmt.Errorf("create file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	var wait, timeout int64
+	if i.Args.Wait != "" {
+		wait, _ = utils.GetTimeSecond(i.Args.Wait)
+	}
+
+	if i.Info.Timeout != "" {
+		timeout, _ = utils.GetTimeSecond(i.Info.Timeout)
+	}
+
+	if _, err := cmdexec.StartBashCmdAndWaitPid(ctx, fmt.Sprintf("%s %s '%s' %s %s %d %d %d %d", utils.GetToolPath(FileLockKey), i.Info.Uid,
+		hostPath, i.Args.Type, i.Args.Mode, i.Args.Start, i.Args.Length, wait, timeout), 0); err != nil {
+		if err := i.release(ctx, hostPath); err != nil {
+			log.GetLogger(ctx).Warnf("undo: release lock error: %s", err.Error())
+		}
+		return fmt.Errorf("start lock holder error: %s", err.Error())
+	}
+
+	return nil
+}
+
+func (i *LockInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	hostPath, err := filesys.GetHostPath(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Path)
+	if err != nil {
+		return fmt.Errorf("get path of file[%s] error: %s", i.Args.Path, err.Error())
+	}
+
+	return i.release(ctx, hostPath)
+}
+
+// release kill the holder process, the lock is released when the process exits
+func (i *LockInjector) release(ctx context.Context, hostPath string) error {
+	if err := process.CheckExistAndKillByKey(ctx, getFileLockKey(i.Info.Uid)); err != nil {
+		return fmt.Errorf("kill lock holder process error: %s", err.Error())
+	}
+
+	if i.Runtime.Created {
+		if err := os.Remove(hostPath); err != nil && !os.IsNotExist(err) {
+			return fmt.Errorf("remove created file[%s] error: %s", i.Args.Path, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *LockInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Path},
+	}, nil
+}
@@ -0,0 +1,114 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package file
+
+import (
+	"context"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"syscall"
+	"testing"
+)
+
+// buildLockTool build the lock holder tool to the tool path of the test binary
+func buildLockTool(t *testing.T) {
+	if _, err := exec.LookPath("go"); err != nil {
+		t.Skip("go is not found, skip building tool")
+	}
+
+	if err := os.MkdirAll(utils.GetToolDir(), 0755); err != nil {
+		t.Fatalf("create tool dir error: %s", err.Error())
+	}
+
+	out, err := exec.Command("go", "build", "-o", utils.GetToolPath(FileLockKey), "../../../tools/chaosmeta_filelock.go").CombinedOutput()
+	if err != nil {
+		t.Fatalf("build tool error: %s, output: %s", err.Error(), string(out))
+	}
+}
+
+// tryLock lock the file by another fd of this process, which conflicts with the lock of holder process
+func tryLock(path, lockType string) error {
+	f, err := os.OpenFile(path, os.O_RDWR, 0)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	if lockType == LockTypeFlock {
+		return syscall.Flock(int(f.Fd()), syscall.LOCK_EX|syscall.LOCK_NB)
+	}
+
+	return syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, &syscall.Flock_t{Type: syscall.F_WRLCK})
+}
+
+func TestLockInjector_InjectAndRecover(t *testing.T) {
+	buildLockTool(t)
+	tests := []struct {
+		name  string
+		args  LockArgs
+		exist bool
+	}{
+		{name: "flock exist file", args: LockArgs{Type: LockTypeFlock}, exist: true},
+		{name: "fcntl created file", args: LockArgs{Type: LockTypeFcntl}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			path := filepath.Join(t.TempDir(), "data")
+			if tt.exist {
+				if err := os.WriteFile(path, []byte("chaosmeta lock test\n"), 0644); err != nil {
+					t.Fatal(err)
+				}
+			}
+
+			i := &LockInjector{Args: tt.args}
+			i.Args.Path = path
+			i.Info.Uid = utils.NewUid()
+			i.SetDefault()
+			ctx := context.Background()
+			if err := i.Validator(ctx); err != nil {
+				t.Fatalf("Validator() error = %v", err)
+			}
+
+			if err := i.Inject(ctx); err != nil {
+				t.Fatalf("Inject() error = %v", err)
+			}
+
+			if i.Runtime.Created == tt.exist {
+				t.Errorf("Runtime.Created = %v, want %v", i.Runtime.Created, !tt.exist)
+			}
+
+			if err := tryLock(path, tt.args.Type); err != syscall.EWOULDBLOCK && err != syscall.EAGAIN && err != syscall.EACCES {
+				t.Errorf("lock after inject: expect EWOULDBLOCK or EAGAIN, get: %v", err)
+			}
+
+			i.Info.Status = utils.StatusSuccess
+			if err := i.Recover(ctx); err != nil {
+				t.Fatalf("Recover() error = %v", err)
+			}
+
+			if tt.exist {
+				if err := tryLock(path, tt.args.Type); err != nil {
+					t.Errorf("lock after recover error: %v", err)
+				}
+			} else if _, err := os.Stat(path); !os.IsNotExist(err) {
+				t.Errorf("created file is not removed after recover: %v", err)
+			}
+		})
+	}
+}
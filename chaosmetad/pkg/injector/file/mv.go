@@ -118,3 +118,9 @@ func (i *MvInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *MvInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Paths: []string{i.Args.Src, i.Args.Dst},
+	}, nil
+}
@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"time"
+)
+
+func init() {
+	injector.Register(TargetGrpc, FaultDelay, func() injector.IInjector { return &DelayInjector{} })
+}
+
+// DelayInjector hold the matched calls for a while before sending them to the server
+type DelayInjector struct {
+	injector.BaseInjector
+	Args    DelayArgs
+	Runtime DelayRuntime
+}
+
+type DelayArgs struct {
+	Latency string `json:"latency"`
+	FilterArgs
+}
+
+type DelayRuntime struct {
+	FilterRuntime
+}
+
+func (i *DelayInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *DelayInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *DelayInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Latency, "latency", "l", "", "delay of the calls, eg: 100ms、2s")
+	setFilterOption(cmd, &i.Args.FilterArgs)
+}
+
+func (i *DelayInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+	i.Args.FilterArgs.setDefault(i.Info.Uid)
+}
+
+func (i *DelayInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Latency == "" {
+		return fmt.Errorf("\"latency\" must provide")
+	}
+
+	if d, err := time.ParseDuration(i.Args.Latency); err != nil || d.Milliseconds() <= 0 {
+		return fmt.Errorf("\"latency\"[%s] is not a valid duration of at least 1ms", i.Args.Latency)
+	}
+
+	return i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+}
+
+func (i *DelayInjector) Inject(ctx context.Context) error {
+	d, _ := time.ParseDuration(i.Args.Latency)
+	rule := i.Args.FilterArgs.getRule()
+	rule.Delay = d.Milliseconds()
+
+	return startProxy(ctx, &i.Info, &i.Args.FilterArgs, rule, &i.Runtime.FilterRuntime)
+}
+
+func (i *DelayInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return stopProxy(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
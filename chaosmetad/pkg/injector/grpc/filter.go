@@ -0,0 +1,151 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/grpcproxy"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+)
+
+// FilterArgs select the grpc calls to a server port, all the provided conditions must be matched.
+// the new connections to the port are redirected to a h2c proxy in the net namespace of target, the established connections are not affected
+type FilterArgs struct {
+	Port      int    `json:"port"`
+	Method    string `json:"method,omitempty"`
+	Header    string `json:"header,omitempty"`
+	Percent   int    `json:"percent"`
+	ProxyPort int    `json:"proxy_port,omitempty"`
+}
+
+type FilterRuntime struct {
+	Rules []string `json:"rules,omitempty"`
+}
+
+func setFilterOption(cmd *cobra.Command, args *FilterArgs) {
+	cmd.Flags().IntVarP(&args.Port, "port", "p", 0, "listen port of the target grpc server")
+	cmd.Flags().StringVarP(&args.Method, "method", "m", "", "filter condition: \":path\" of the call, format: /[service]/[method], \"/[service]/*\" means all the methods of the service. eg: /helloworld.Greeter/SayHello")
+	cmd.Flags().StringVarP(&args.Header, "header", "H", "", "filter condition: metadata of the call, format: [key]=[value], multiple are separated by \",\". eg: x-user=test,x-env=gray")
+	cmd.Flags().IntVarP(&args.Percent, "percent", "P", 0, fmt.Sprintf("percent of the matched calls to inject, an integer in (0,100]（default %d）", DefaultPercent))
+	cmd.Flags().IntVar(&args.ProxyPort, "proxy-port", 0, fmt.Sprintf("listen port of the proxy（default a port in [%d, %d) chosen by uid）", net.ProxyPortBase, net.ProxyPortBase+net.ProxyPortRange))
+}
+
+func (args *FilterArgs) setDefault(uid string) {
+	if args.Percent == 0 {
+		args.Percent = DefaultPercent
+	}
+
+	if args.ProxyPort == 0 {
+		args.ProxyPort = net.GetProxyPort(uid)
+	}
+}
+
+func (args *FilterArgs) validate(ctx context.Context, cr, cId string) error {
+	if args.Port <= 0 || args.Port > 65535 {
+		return fmt.Errorf("\"port\"[%d] must be in (0, 65535]", args.Port)
+	}
+
+	if err := grpcproxy.CheckMethod(args.Method); err != nil {
+		return fmt.Errorf("\"method\" is invalid: %s", err.Error())
+	}
+
+	if _, err := grpcproxy.ParseHeaders(args.Header); err != nil {
+		return fmt.Errorf("\"header\" is invalid: %s", err.Error())
+	}
+
+	if args.Percent <= 0 || args.Percent > 100 {
+		return fmt.Errorf("\"percent\"[%d] must be in (0,100]", args.Percent)
+	}
+
+	if args.ProxyPort <= 0 || args.ProxyPort > 65535 || args.ProxyPort == args.Port {
+		return fmt.Errorf("\"proxy-port\"[%d] must be in (0, 65535] and different from \"port\"", args.ProxyPort)
+	}
+
+	if !cmdexec.SupportCmd("iptables") {
+		return fmt.Errorf("not support command \"iptables\"")
+	}
+
+	if err := net.CheckPortFree(ctx, cr, cId, args.ProxyPort); err != nil {
+		return fmt.Errorf("check \"proxy-port\" error: %s, please provide another one", err.Error())
+	}
+
+	return nil
+}
+
+// getRule return the rule of the proxy without fault, the injector set the fault to it
+func (args *FilterArgs) getRule() *grpcproxy.Rule {
+	headers, _ := grpcproxy.ParseHeaders(args.Header)
+	return &grpcproxy.Rule{
+		Method:  args.Method,
+		Headers: headers,
+		Percent: args.Percent,
+		Code:    grpcproxy.CodeNone,
+	}
+}
+
+func getProxyKey(uid string) string {
+	return fmt.Sprintf("%s %s", ProxyKey, uid)
+}
+
+// getRedirectRule only the connections to the local addresses are redirected, not the ones forwarded to other pods of node
+func getRedirectRule(port, proxyPort int) string {
+	return fmt.Sprintf("%s -t nat -p tcp --dport %d -m addrtype --dst-type LOCAL -j REDIRECT --to-ports %d", net.ChainPrerouting, port, proxyPort)
+}
+
+// startProxy start the proxy with the rule, and redirect the incoming connections of the server port to it
+func startProxy(ctx context.Context, info *injector.BaseInfo, args *FilterArgs, rule *grpcproxy.Rule, runtime *FilterRuntime) error {
+	ruleBytes, err := json.Marshal(rule)
+	if err != nil {
+		return fmt.Errorf("marshal rule error: %s", err.Error())
+	}
+
+	var timeout int64
+	if info.Timeout != "" {
+		timeout, _ = utils.GetTimeSecond(info.Timeout)
+	}
+
+	cmd := fmt.Sprintf("%s %s %d %d %s %d", utils.GetToolPath(ProxyKey), info.Uid, args.ProxyPort, args.Port,
+		base64.StdEncoding.EncodeToString(ruleBytes), timeout)
+	if err := cmdexec.WaitCommonWithNS(ctx, info.ContainerRuntime, info.ContainerId, cmd, []string{namespace.NET}); err != nil {
+		return process.KillByKeyWithErr(ctx, getProxyKey(info.Uid), fmt.Sprintf("start proxy error: %s", err.Error()))
+	}
+
+	rules := []string{getRedirectRule(args.Port, args.ProxyPort)}
+	if err := net.AddIptablesRules(ctx, info.ContainerRuntime, info.ContainerId, rules); err != nil {
+		return process.KillByKeyWithErr(ctx, getProxyKey(info.Uid), fmt.Sprintf("add redirect rule error: %s", err.Error()))
+	}
+	runtime.Rules = rules
+
+	return nil
+}
+
+func stopProxy(ctx context.Context, info *injector.BaseInfo, runtime *FilterRuntime) error {
+	if err := net.DeleteIptablesRules(ctx, info.ContainerRuntime, info.ContainerId, runtime.Rules); err != nil {
+		return fmt.Errorf("delete redirect rule error: %s", err.Error())
+	}
+
+	return process.CheckExistAndKillByKey(ctx, getProxyKey(info.Uid))
+}
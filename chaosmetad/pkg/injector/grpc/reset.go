@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+)
+
+func init() {
+	injector.Register(TargetGrpc, FaultReset, func() injector.IInjector { return &ResetInjector{} })
+}
+
+// ResetInjector reset the http2 streams of the matched calls, the connections are kept
+type ResetInjector struct {
+	injector.BaseInjector
+	Args    ResetArgs
+	Runtime ResetRuntime
+}
+
+type ResetArgs struct {
+	FilterArgs
+}
+
+type ResetRuntime struct {
+	FilterRuntime
+}
+
+func (i *ResetInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *ResetInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *ResetInjector) SetOption(cmd *cobra.Command) {
+	setFilterOption(cmd, &i.Args.FilterArgs)
+}
+
+func (i *ResetInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+	i.Args.FilterArgs.setDefault(i.Info.Uid)
+}
+
+func (i *ResetInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	return i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+}
+
+func (i *ResetInjector) Inject(ctx context.Context) error {
+	rule := i.Args.FilterArgs.getRule()
+	rule.Reset = true
+
+	return startProxy(ctx, &i.Info, &i.Args.FilterArgs, rule, &i.Runtime.FilterRuntime)
+}
+
+func (i *ResetInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return stopProxy(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
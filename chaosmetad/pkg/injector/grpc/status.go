@@ -0,0 +1,97 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpc
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/grpcproxy"
+)
+
+func init() {
+	injector.Register(TargetGrpc, FaultStatus, func() injector.IInjector { return &StatusInjector{} })
+}
+
+// StatusInjector reply the matched calls with the grpc status directly, the calls are not sent to the server
+type StatusInjector struct {
+	injector.BaseInjector
+	Args    StatusArgs
+	Runtime StatusRuntime
+}
+
+type StatusArgs struct {
+	Code    string `json:"code"`
+	Message string `json:"message,omitempty"`
+	FilterArgs
+}
+
+type StatusRuntime struct {
+	FilterRuntime
+}
+
+func (i *StatusInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *StatusInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *StatusInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Code, "code", "c", "", "grpc status code to reply, support the name or number. eg: UNAVAILABLE、14")
+	cmd.Flags().StringVarP(&i.Args.Message, "message", "M", "", "grpc status message to reply")
+	setFilterOption(cmd, &i.Args.FilterArgs)
+}
+
+func (i *StatusInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+	i.Args.FilterArgs.setDefault(i.Info.Uid)
+}
+
+func (i *StatusInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Code == "" {
+		return fmt.Errorf("\"code\" must provide")
+	}
+
+	if _, err := grpcproxy.ParseCode(i.Args.Code); err != nil {
+		return fmt.Errorf("\"code\" is invalid: %s", err.Error())
+	}
+
+	return i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+}
+
+func (i *StatusInjector) Inject(ctx context.Context) error {
+	rule := i.Args.FilterArgs.getRule()
+	rule.Code, _ = grpcproxy.ParseCode(i.Args.Code)
+	rule.Message = i.Args.Message
+
+	return startProxy(ctx, &i.Info, &i.Args.FilterArgs, rule, &i.Runtime.FilterRuntime)
+}
+
+func (i *StatusInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return stopProxy(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
@@ -22,7 +22,11 @@ import (
 	"fmt"
 	"github.com/spf13/cobra"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/event"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/metrics"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/policy"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
@@ -30,6 +34,7 @@ import (
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/user"
 	"runtime/debug"
 	"strings"
+	"time"
 )
 
 type IInjector interface {
@@ -38,6 +43,7 @@ type IInjector interface {
 	LoadInjector(exp *storage.Experiment, argsVar, rVar interface{}) error
 	DelayRecover(ctx context.Context, timeout int64) error
 
+	GetInfo() *BaseInfo
 	GetArgs() interface{}
 	GetRuntime() interface{}
 
@@ -46,6 +52,7 @@ type IInjector interface {
 	Validator(ctx context.Context) error
 	Inject(ctx context.Context) error
 	Recover(ctx context.Context) error
+	Verify(ctx context.Context) error
 }
 
 /*=======================================Base Injector===================================================*/
@@ -68,9 +75,23 @@ type BaseInfo struct {
 	// container information
 	ContainerId      string `json:"container_id"`
 	ContainerRuntime string `json:"container_runtime"`
+	// pod information, used to find the target container
+	PodNamespace  string `json:"pod_namespace,omitempty"`
+	PodName       string `json:"pod_name,omitempty"`
+	PodUid        string `json:"pod_uid,omitempty"`
+	ContainerName string `json:"container_name,omitempty"`
+	// inject even if the resources overlap with running experiments
+	AllowOverlap bool `json:"allow_overlap,omitempty"`
+	// verify information, the snapshot before inject and the drift found after recover
+	Snapshot string `json:"snapshot,omitempty"`
+	Drift    string `json:"drift,omitempty"`
 	//ContainerNs      []string `json:"container_ns"`
 }
 
+func (i *BaseInjector) GetInfo() *BaseInfo {
+	return &i.Info
+}
+
 func (i *BaseInjector) GetArgs() interface{} {
 	var empty interface{}
 	return empty
@@ -113,6 +134,26 @@ func (i *BaseInjector) SetCommonArgs(info *BaseInfo) {
 	if info.ContainerId != "" {
 		i.Info.ContainerId = info.ContainerId
 	}
+
+	if info.PodNamespace != "" {
+		i.Info.PodNamespace = info.PodNamespace
+	}
+
+	if info.PodName != "" {
+		i.Info.PodName = info.PodName
+	}
+
+	if info.PodUid != "" {
+		i.Info.PodUid = info.PodUid
+	}
+
+	if info.ContainerName != "" {
+		i.Info.ContainerName = info.ContainerName
+	}
+
+	if info.AllowOverlap {
+		i.Info.AllowOverlap = info.AllowOverlap
+	}
 }
 
 func (i *BaseInjector) SetOption(cmd *cobra.Command) {
@@ -125,14 +166,43 @@ func (i *BaseInjector) Inject(ctx context.Context) error {
 	return fmt.Errorf("not implemented")
 }
 
+// Recover an experiment whose verify failed is recovered already, only Verify is run again when retry
 func (i *BaseInjector) Recover(ctx context.Context) error {
-	if i.Info.Status == utils.StatusDestroyed || i.Info.Status == utils.StatusError {
+	if i.Info.Status == utils.StatusDestroyed || i.Info.Status == utils.StatusError || i.Info.Status == utils.StatusRecoverVerifyFailed {
 		return nil
 	}
 
 	return fmt.Errorf("not implemented")
 }
 
+// Verify compare the system with the snapshot taken before inject, the drift is saved in info.
+// a snapshot can not be taken now is not a drift, eg: the container is deleted
+func (i *BaseInjector) Verify(ctx context.Context) error {
+	if i.Info.Snapshot == "" {
+		return nil
+	}
+
+	before := &Snapshot{}
+	if err := json.Unmarshal([]byte(i.Info.Snapshot), before); err != nil {
+		return fmt.Errorf("load snapshot error: %s", err.Error())
+	}
+
+	now, err := TakeSnapshot(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, before.scope())
+	if err != nil {
+		log.GetLogger(ctx).Warnf("take snapshot error, skip verify: %s", err.Error())
+		return nil
+	}
+
+	drifts := before.Diff(now)
+	if len(drifts) == 0 {
+		return nil
+	}
+
+	driftBytes, _ := json.Marshal(drifts)
+	i.Info.Drift = string(driftBytes)
+	return fmt.Errorf("system is not the same as before inject: %s", strings.Join(drifts, "; "))
+}
+
 func (i *BaseInjector) SetDefault() {
 	if i.Info.Creator == "" {
 		i.Info.Creator = user.GetUser()
@@ -146,12 +216,52 @@ func (i *BaseInjector) SetDefault() {
 		i.Info.Status = utils.StatusCreated
 	}
 
-	if i.Info.ContainerId != "" && i.Info.ContainerRuntime == "" {
-		i.Info.ContainerRuntime = crclient.CrDocker
+	if i.Info.PodName != "" && i.Info.PodNamespace == "" {
+		i.Info.PodNamespace = crclient.DefaultPodNamespace
+	}
+}
+
+// resolveContainer find the container runtime and container id by pod information or container id
+func (i *BaseInjector) resolveContainer(ctx context.Context) error {
+	if i.Info.PodName == "" && i.Info.PodUid == "" {
+		if i.Info.PodNamespace != "" || i.Info.ContainerName != "" {
+			return fmt.Errorf("\"pod-name\" or \"pod-uid\" is empty")
+		}
+
+		if i.Info.ContainerId != "" && i.Info.ContainerRuntime == "" {
+			cr, err := crclient.FindRuntimeById(ctx, i.Info.ContainerId)
+			if err != nil {
+				return fmt.Errorf("find container runtime error: %s", err.Error())
+			}
+			i.Info.ContainerRuntime = cr
+		}
+
+		return nil
+	}
+
+	if i.Info.ContainerId != "" {
+		return fmt.Errorf("\"container-id\" and pod information can not be provided at the same time")
+	}
+
+	cr, cId, err := crclient.FindContainerByPod(ctx, i.Info.ContainerRuntime, &crclient.PodSelector{
+		Namespace:     i.Info.PodNamespace,
+		Name:          i.Info.PodName,
+		Uid:           i.Info.PodUid,
+		ContainerName: i.Info.ContainerName,
+	})
+	if err != nil {
+		return fmt.Errorf("find container of pod error: %s", err.Error())
 	}
+
+	i.Info.ContainerRuntime, i.Info.ContainerId = cr, cId
+	return nil
 }
 
 func (i *BaseInjector) Validator(ctx context.Context) error {
+	if err := i.resolveContainer(ctx); err != nil {
+		return err
+	}
+
 	if i.Info.ContainerRuntime != "" {
 		if i.Info.ContainerId == "" {
 			return fmt.Errorf("\"container-id\" is empty")
@@ -204,6 +314,8 @@ func (i *BaseInjector) LoadInjector(exp *storage.Experiment, argsVar, rVar inter
 	i.Info.Timeout = exp.Timeout
 	i.Info.ContainerRuntime = exp.ContainerRuntime
 	i.Info.ContainerId = exp.ContainerId
+	i.Info.Snapshot = exp.Snapshot
+	i.Info.Drift = exp.Drift
 
 	return nil
 }
@@ -230,6 +342,8 @@ func (i *BaseInjector) OptionToExp(args, r interface{}) (*storage.Experiment, er
 		Runtime:          string(runtimeByte),
 		ContainerRuntime: i.Info.ContainerRuntime,
 		ContainerId:      i.Info.ContainerId,
+		Snapshot:         i.Info.Snapshot,
+		Drift:            i.Info.Drift,
 	}
 
 	return exp, nil
@@ -238,7 +352,10 @@ func (i *BaseInjector) OptionToExp(args, r interface{}) (*storage.Experiment, er
 /*=======================================Main Process===================================================*/
 
 func ProcessInject(ctx context.Context, i IInjector) (code int, msg string) {
-	logger := log.GetLogger(ctx)
+	logger, startTime := log.GetLogger(ctx), time.Now()
+	defer func() {
+		metrics.ObserveInject(i.GetInfo().Target, i.GetInfo().Fault, code, startTime)
+	}()
 	defer func() {
 		if err := recover(); err != any(nil) {
 			logger.Debug(string(debug.Stack()))
@@ -252,18 +369,24 @@ func ProcessInject(ctx context.Context, i IInjector) (code int, msg string) {
 		return errutil.BadArgsErr, fmt.Sprintf("args error: %s", err.Error())
 	}
 
-	db, err := storage.GetExperimentStore()
-	if err != nil {
-		return errutil.DBErr, fmt.Sprintf("connect db error: %s", err.Error())
+	if err := checkPolicy(ctx, i); err != nil {
+		return errutil.PolicyErr, fmt.Sprintf("rejected by policy[%s]: %s", policy.GetPath(), err.Error())
 	}
 
-	exp, err := i.OptionToExp(i.GetArgs(), i.GetRuntime())
+	unlock, err := lockConflict()
 	if err != nil {
-		return errutil.BadArgsErr, fmt.Sprintf("create experiment error: %s", err.Error())
+		return errutil.InternalErr, fmt.Sprintf("lock for conflict check error: %s", err.Error())
 	}
 
-	if err := db.Insert(exp); err != nil {
-		return errutil.DBErr, fmt.Sprintf("insert new experiment error: %s", err.Error())
+	exp, code, msg := checkAndInsert(ctx, i)
+	unlock()
+	if code != errutil.NoErr {
+		return code, msg
+	}
+
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		return errutil.DBErr, fmt.Sprintf("connect db error: %s", err.Error())
 	}
 
 	logger.Infof("uid: %s", exp.Uid)
@@ -300,9 +423,43 @@ func ProcessInject(ctx context.Context, i IInjector) (code int, msg string) {
 	return errutil.NoErr, "success"
 }
 
+// checkAndInsert run in the lock of conflict check, so that the overlapped experiments injected at the same time are rejected
+func checkAndInsert(ctx context.Context, i IInjector) (exp *storage.Experiment, code int, msg string) {
+	if err := checkConflict(ctx, i); err != nil {
+		return nil, errutil.ConflictErr, fmt.Sprintf("rejected by conflict check: %s", err.Error())
+	}
+
+	if err := takeSnapshot(ctx, i); err != nil {
+		log.GetLogger(ctx).Warnf("take snapshot error, the experiment will not be verified after recover: %s", err.Error())
+	}
+
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		return nil, errutil.DBErr, fmt.Sprintf("connect db error: %s", err.Error())
+	}
+
+	exp, err = i.OptionToExp(i.GetArgs(), i.GetRuntime())
+	if err != nil {
+		return nil, errutil.BadArgsErr, fmt.Sprintf("create experiment error: %s", err.Error())
+	}
+
+	if err := db.Insert(exp); err != nil {
+		return nil, errutil.DBErr, fmt.Sprintf("insert new experiment error: %s", err.Error())
+	}
+
+	return exp, errutil.NoErr, "success"
+}
+
 func ProcessRecover(ctx context.Context, uid string) (code int, msg string) {
-	logger := log.GetLogger(ctx)
+	var (
+		logger        = log.GetLogger(ctx)
+		startTime     = time.Now()
+		target, fault string
+	)
 
+	defer func() {
+		metrics.ObserveRecover(target, fault, code, startTime)
+	}()
 	defer func() {
 		if err := recover(); err != any(nil) {
 			logger.Debug(string(debug.Stack()))
@@ -321,6 +478,7 @@ func ProcessRecover(ctx context.Context, uid string) (code int, msg string) {
 	if err != nil {
 		return errutil.DBErr, fmt.Sprintf("query experiment by uid[%s] error: %s", uid, err.Error())
 	}
+	target, fault = exp.Target, exp.Fault
 
 	i, err := NewInjector(exp.Target, exp.Fault)
 	if err != nil {
@@ -331,12 +489,34 @@ func ProcessRecover(ctx context.Context, uid string) (code int, msg string) {
 		return errutil.InternalErr, fmt.Sprintf("load experiment to injector error: %s", err.Error())
 	}
 
+	// the status is checked before recover, only the experiment recovered now or failed in verify last time is verified
+	status := exp.Status
 	if err := i.Recover(ctx); err != nil {
-		return errutil.RecoverErr, fmt.Sprintf("recover error: %s", err.Error())
+		errMsg := fmt.Sprintf("recover error: %s", err.Error())
+		event.GetBus().Publish(&event.Event{
+			Type:    event.TypeRecoverFailed,
+			Uid:     exp.Uid,
+			Target:  exp.Target,
+			Fault:   exp.Fault,
+			Status:  exp.Status,
+			Message: errMsg,
+		})
+		return errutil.RecoverErr, errMsg
 	}
 
 	logger.Info("recover success")
 
+	if status == utils.StatusSuccess || status == utils.StatusRecoverVerifyFailed {
+		if err := i.Verify(ctx); err != nil {
+			errMsg := fmt.Sprintf("verify after recover error: %s", err.Error())
+			if err := db.Update(&storage.Experiment{Uid: uid, Status: utils.StatusRecoverVerifyFailed, Error: errMsg, Drift: i.GetInfo().Drift}); err != nil {
+				logger.Warnf("update status[%s] for experiment[%s] error: %s", utils.StatusRecoverVerifyFailed, uid, err.Error())
+			}
+
+			return errutil.RecoverErr, errMsg
+		}
+	}
+
 	if err := db.UpdateStatus(uid, utils.StatusDestroyed); err != nil {
 		logger.Warnf("update status[%s] for experiment[%s] error: %s", utils.StatusDestroyed, uid, err.Error())
 	}
@@ -367,13 +547,18 @@ func NewCmdByTargetAndFault(target, fault string, infoArgs *BaseInfo) *cobra.Com
 		Short: fmt.Sprintf("create %s experiment for %s", fault, target),
 		Run: func(cmd *cobra.Command, args []string) {
 			ctx := utils.GetCtxWithTraceId(context.Background(), utils.TraceId)
+			output.Init(ctx)
 			if len(args) != 0 {
-				errutil.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("unknown args: %s, please add -h to get more info", args))
+				output.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("unknown args: %s, please add -h to get more info", args))
 			}
 
 			i.SetCommonArgs(infoArgs)
 			code, msg := ProcessInject(ctx, i)
-			errutil.SolveErr(ctx, code, msg)
+			if code == errutil.BadArgsErr || code == errutil.PolicyErr || code == errutil.ConflictErr {
+				// experiment is not created, the uid may belong to another experiment
+				output.Solve(ctx, &output.Result{Code: code, Message: msg, Uid: i.GetInfo().Uid})
+			}
+			output.Solve(ctx, output.NewExpResult(ctx, code, msg, i.GetInfo().Uid))
 		},
 	}
 
@@ -27,6 +27,7 @@ const (
 	ModeFdFill        = "fill"
 	FileMaxPath       = "/proc/sys/fs/file-max"
 	FileNrPath        = "/proc/sys/fs/file-nr"
+	FileMaxSysctl     = "fs.file-max"
 
 	FaultKernelNproc = "nproc"
 	NprocKey         = "chaosmeta_nproc"
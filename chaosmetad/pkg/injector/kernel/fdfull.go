@@ -67,18 +67,20 @@ func (i *FdfullInjector) SetDefault() {
 
 func (i *FdfullInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("mode to make full of fd. \"%s\"(default): change config of max fd, \"%s\": add fd to max of os", ModeFileMax, ModeFdFill))
+	injector.SetFlagEnum(cmd, "mode", ModeFileMax, ModeFdFill)
 	cmd.Flags().IntVarP(&i.Args.Count, "count", "c", 0, fmt.Sprintf("count of fd to fill, args of \"%s\" mode（default 0, means add to max）, you can check by \"cat %s\"", ModeFdFill, FileNrPath))
 }
 
 func (i *FdfullInjector) Validator(ctx context.Context) error {
-	if i.Info.ContainerId != "" || i.Info.ContainerRuntime != "" {
-		return fmt.Errorf("fault \"fdfull\" not support in container")
-	}
-
 	if err := i.BaseInjector.Validator(ctx); err != nil {
 		return err
 	}
 
+	// the container is resolved from pod information in the base validator
+	if i.Info.ContainerId != "" || i.Info.ContainerRuntime != "" {
+		return fmt.Errorf("fault \"fdfull\" not support in container")
+	}
+
 	if i.Args.Mode != ModeFdFill && i.Args.Mode != ModeFileMax {
 		return fmt.Errorf(fmt.Sprintf("\"mode\" not support: %s, only support: %s, %s", i.Args.Mode, ModeFdFill, ModeFileMax))
 	}
@@ -177,6 +179,16 @@ func getFdfullKey(uid string) string {
 	return fmt.Sprintf("%s %s", FdFullKey, uid)
 }
 
+func (i *FdfullInjector) GetVerifyScope(ctx context.Context) (*injector.VerifyScope, error) {
+	if i.Args.Mode != ModeFileMax {
+		return nil, nil
+	}
+
+	return &injector.VerifyScope{
+		Sysctls: []string{FileMaxSysctl},
+	}, nil
+}
+
 func (i *FdfullInjector) Recover(ctx context.Context) error {
 	if i.BaseInjector.Recover(ctx) == nil {
 		return nil
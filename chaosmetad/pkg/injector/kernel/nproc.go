@@ -65,14 +65,15 @@ func (i *NprocInjector) SetOption(cmd *cobra.Command) {
 }
 
 func (i *NprocInjector) Validator(ctx context.Context) error {
-	if i.Info.ContainerId != "" || i.Info.ContainerRuntime != "" {
-		return fmt.Errorf("fault \"nproc\" not support in container")
-	}
-
 	if err := i.BaseInjector.Validator(ctx); err != nil {
 		return err
 	}
 
+	// the container is resolved from pod information in the base validator
+	if i.Info.ContainerId != "" || i.Info.ContainerRuntime != "" {
+		return fmt.Errorf("fault \"nproc\" not support in container")
+	}
+
 	if i.Args.Count < 0 {
 		return fmt.Errorf("\"count\" must larger than 0")
 	}
@@ -19,6 +19,7 @@ package mem
 import (
 	"context"
 	"fmt"
+	gprocess "github.com/shirou/gopsutil/process"
 	"github.com/spf13/cobra"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
@@ -28,6 +29,7 @@ import (
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/memory"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"os"
 )
 
 func init() {
@@ -76,6 +78,7 @@ func (i *FillInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().IntVarP(&i.Args.Percent, "percent", "p", 0, "mem fill target percent, an integer in (0,100] without \"%\", eg: \"30\" means \"30%\"")
 	cmd.Flags().StringVarP(&i.Args.Bytes, "bytes", "b", "", "mem fill bytes to add, support unit: KB/MB/GB/TB（default KB）")
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("mem fill mode, support: %s、%s（default %s）", ModeRam, ModeCache, ModeCache))
+	injector.SetFlagEnum(cmd, "mode", ModeRam, ModeCache)
 }
 
 // Validator percent > bytes
@@ -181,3 +184,66 @@ func (i *FillInjector) Recover(ctx context.Context) error {
 		return process.CheckExistAndKillByKey(ctx, fmt.Sprintf("%s %s", MemFillKey, i.Info.Uid))
 	}
 }
+
+func (i *FillInjector) GetProgress(ctx context.Context) (string, error) {
+	usedPercent, err := memory.GetMemUsedPercent(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+	if err != nil {
+		return "", fmt.Errorf("get mem used percent error: %s", err.Error())
+	}
+
+	if i.Args.Percent > 0 {
+		return fmt.Sprintf("mem usage: %.0f%%, target: %d%%", usedPercent, i.Args.Percent), nil
+	}
+
+	return fmt.Sprintf("mem usage: %.0f%%", usedPercent), nil
+}
+
+func (i *FillInjector) GetMetrics(ctx context.Context) (map[string]float64, error) {
+	if i.Args.Mode == ModeCache {
+		fillFile := fmt.Sprintf("%s/%s", getFillDir(i.Info.Uid), TmpFsFile)
+		fileInfo, err := os.Stat(fillFile)
+		if err != nil {
+			return nil, fmt.Errorf("get fill file[%s] info error: %s", fillFile, err.Error())
+		}
+
+		return map[string]float64{
+			"fill_bytes": float64(fileInfo.Size()),
+		}, nil
+	}
+
+	pidList, err := process.GetProcessByKey(ctx, "", "", fmt.Sprintf("%s %s", MemFillKey, i.Info.Uid))
+	if err != nil {
+		return nil, fmt.Errorf("get fill process error: %s", err.Error())
+	}
+
+	var rss uint64
+	for _, pid := range pidList {
+		p, err := gprocess.NewProcess(int32(pid))
+		if err != nil {
+			continue
+		}
+
+		memInfo, err := p.MemoryInfo()
+		if err != nil {
+			continue
+		}
+		rss += memInfo.RSS
+	}
+
+	return map[string]float64{
+		"fill_bytes": float64(rss),
+	}, nil
+}
+
+func (i *FillInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	if i.Args.Percent != 0 {
+		return &injector.Resource{MemPercent: float64(i.Args.Percent)}, nil
+	}
+
+	fillPercent, err := memory.GetFillPercent(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("get mem percent after fill error: %s", err.Error())
+	}
+
+	return &injector.Resource{MemPercent: fillPercent}, nil
+}
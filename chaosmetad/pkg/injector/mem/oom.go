@@ -71,6 +71,7 @@ func (i *OOMInjector) SetDefault() {
 func (i *OOMInjector) SetOption(cmd *cobra.Command) {
 	// i.BaseInjector.SetOption(cmd)
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("mem fill mode, support: %s、%s（default %s）", ModeRam, ModeCache, ModeCache))
+	injector.SetFlagEnum(cmd, "mode", ModeRam, ModeCache)
 }
 
 func (i *OOMInjector) Validator(ctx context.Context) error {
@@ -159,3 +160,9 @@ func (i *OOMInjector) Recover(ctx context.Context) error {
 		return process.CheckExistAndKillByKey(ctx, fmt.Sprintf("%s %s", MemFillKey, i.Info.Uid))
 	}
 }
+
+func (i *OOMInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		MemPercent: 100,
+	}, nil
+}
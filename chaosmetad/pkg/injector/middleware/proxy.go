@@ -0,0 +1,239 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package middleware
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/tcpproxy"
+	gonet "net"
+	"strconv"
+	"time"
+)
+
+func init() {
+	injector.Register(TargetMiddleware, FaultProxy, func() injector.IInjector { return &ProxyInjector{} })
+}
+
+// ProxyInjector redirect the new connections to upstream to a tcp proxy in the net namespace of target, which applies the toxics.
+// the established connections are not affected
+type ProxyInjector struct {
+	injector.BaseInjector
+	Args    ProxyArgs
+	Runtime ProxyRuntime
+}
+
+type ProxyArgs struct {
+	Upstream   string `json:"upstream"`
+	Latency    string `json:"latency,omitempty"`
+	Bandwidth  string `json:"bandwidth,omitempty"`
+	StallAfter string `json:"stall_after,omitempty"`
+	CloseAfter string `json:"close_after,omitempty"`
+	Direction  string `json:"direction"`
+	ProxyPort  int    `json:"proxy_port,omitempty"`
+}
+
+type ProxyRuntime struct {
+	Rules []string `json:"rules,omitempty"`
+}
+
+func (i *ProxyInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *ProxyInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *ProxyInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Upstream, "upstream", "u", "", "target upstream of the connections, format: [ipv4]:[port]. eg: 10.0.0.1:3306")
+	cmd.Flags().StringVarP(&i.Args.Latency, "latency", "l", "", "toxic: delay before the first byte, eg: 100ms、2s")
+	cmd.Flags().StringVarP(&i.Args.Bandwidth, "bandwidth", "b", "", "toxic: bytes per second, support unit: B/KB/MB/GB（default B）")
+	cmd.Flags().StringVarP(&i.Args.StallAfter, "stall-after", "s", "", "toxic: stop forwarding after these bytes and hold the connection, support unit: B/KB/MB/GB（default B）")
+	cmd.Flags().StringVarP(&i.Args.CloseAfter, "close-after", "c", "", "toxic: close the connection after this duration, eg: 500ms、10s")
+	cmd.Flags().StringVarP(&i.Args.Direction, "direction", "d", "", fmt.Sprintf("direction to apply latency、bandwidth and stall, support: %s(to upstream)、%s(from upstream)、%s（default %s）", tcpproxy.DirectionUp, tcpproxy.DirectionDown, tcpproxy.DirectionBoth, DefaultDirection))
+	injector.SetFlagEnum(cmd, "direction", tcpproxy.DirectionUp, tcpproxy.DirectionDown, tcpproxy.DirectionBoth)
+	cmd.Flags().IntVarP(&i.Args.ProxyPort, "proxy-port", "p", 0, fmt.Sprintf("listen port of the proxy on 127.0.0.1（default a port in [%d, %d) chosen by uid）", net.ProxyPortBase, net.ProxyPortBase+net.ProxyPortRange))
+}
+
+func (i *ProxyInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Direction == "" {
+		i.Args.Direction = DefaultDirection
+	}
+
+	if i.Args.ProxyPort == 0 {
+		i.Args.ProxyPort = net.GetProxyPort(i.Info.Uid)
+	}
+}
+
+func (i *ProxyInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if _, _, err := parseUpstream(i.Args.Upstream); err != nil {
+		return fmt.Errorf("\"upstream\" is invalid: %s", err.Error())
+	}
+
+	if i.Args.Direction != tcpproxy.DirectionUp && i.Args.Direction != tcpproxy.DirectionDown && i.Args.Direction != tcpproxy.DirectionBoth {
+		return fmt.Errorf("\"direction\" is not support: %s, only support: %s、%s、%s", i.Args.Direction, tcpproxy.DirectionUp, tcpproxy.DirectionDown, tcpproxy.DirectionBoth)
+	}
+
+	if i.Args.Latency == "" && i.Args.Bandwidth == "" && i.Args.StallAfter == "" && i.Args.CloseAfter == "" {
+		return fmt.Errorf("must provide at least one toxic of: latency、bandwidth、stall-after、close-after")
+	}
+
+	if _, err := i.getToxics(); err != nil {
+		return err
+	}
+
+	if i.Args.ProxyPort <= 0 || i.Args.ProxyPort > 65535 {
+		return fmt.Errorf("\"proxy-port\"[%d] must be in (0, 65535]", i.Args.ProxyPort)
+	}
+
+	if !cmdexec.SupportCmd("iptables") {
+		return fmt.Errorf("not support command \"iptables\"")
+	}
+
+	if err := net.CheckPortFree(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.ProxyPort); err != nil {
+		return fmt.Errorf("check \"proxy-port\" error: %s, please provide another one", err.Error())
+	}
+
+	return nil
+}
+
+func parseUpstream(upstream string) (string, int, error) {
+	host, portStr, err := gonet.SplitHostPort(upstream)
+	if err != nil {
+		return "", -1, err
+	}
+
+	if ip := gonet.ParseIP(host); ip == nil || ip.To4() == nil {
+		return "", -1, fmt.Errorf("%s is not a valid ipv4", host)
+	}
+
+	port, err := strconv.Atoi(portStr)
+	if err != nil || port <= 0 || port > 65535 {
+		return "", -1, fmt.Errorf("%s is not a valid port", portStr)
+	}
+
+	return host, port, nil
+}
+
+func getDurationMs(name, value string) (int64, error) {
+	if value == "" {
+		return 0, nil
+	}
+
+	d, err := time.ParseDuration(value)
+	if err != nil || d <= 0 {
+		return 0, fmt.Errorf("\"%s\"[%s] is not a valid positive duration", name, value)
+	}
+
+	return d.Milliseconds(), nil
+}
+
+func (i *ProxyInjector) getToxics() (*tcpproxy.Toxics, error) {
+	var (
+		t   = &tcpproxy.Toxics{StallAfter: -1, Direction: i.Args.Direction}
+		err error
+	)
+
+	if t.Latency, err = getDurationMs("latency", i.Args.Latency); err != nil {
+		return nil, err
+	}
+
+	if t.CloseAfter, err = getDurationMs("close-after", i.Args.CloseAfter); err != nil {
+		return nil, err
+	}
+
+	if i.Args.Bandwidth != "" {
+		if t.Bandwidth, err = utils.GetBytes(i.Args.Bandwidth); err != nil || t.Bandwidth <= 0 {
+			return nil, fmt.Errorf("\"bandwidth\"[%s] is not a valid positive bytes", i.Args.Bandwidth)
+		}
+	}
+
+	if i.Args.StallAfter != "" {
+		if t.StallAfter, err = utils.GetBytes(i.Args.StallAfter); err != nil {
+			return nil, fmt.Errorf("\"stall-after\"[%s] is invalid: %s", i.Args.StallAfter, err.Error())
+		}
+	}
+
+	return t, nil
+}
+
+func getProxyKey(uid string) string {
+	return fmt.Sprintf("%s %s", ProxyKey, uid)
+}
+
+func getRedirectRule(ip string, port, proxyPort int, mark uint32) string {
+	return fmt.Sprintf("%s -t nat -p tcp -d %s --dport %d -m mark ! --mark %s -j REDIRECT --to-ports %d", net.ChainOutput, ip, port, net.GetMarkStr(mark), proxyPort)
+}
+
+func (i *ProxyInjector) Inject(ctx context.Context) error {
+	ip, port, _ := parseUpstream(i.Args.Upstream)
+	t, _ := i.getToxics()
+	toxicsBytes, err := json.Marshal(t)
+	if err != nil {
+		return fmt.Errorf("marshal toxics error: %s", err.Error())
+	}
+
+	var timeout int64
+	if i.Info.Timeout != "" {
+		timeout, _ = utils.GetTimeSecond(i.Info.Timeout)
+	}
+
+	// the connections of proxy to upstream are marked in the reserved bits to skip the redirect rule,
+	// so that the bits used by others are not set, eg: 0x8000 is dropped by kube-proxy
+	mark := net.GetMark(i.Info.Uid)
+	cmd := fmt.Sprintf("%s %s %d %s %d %s %d", utils.GetToolPath(ProxyKey), i.Info.Uid, i.Args.ProxyPort, i.Args.Upstream,
+		mark, base64.StdEncoding.EncodeToString(toxicsBytes), timeout)
+	if err := cmdexec.WaitCommonWithNS(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, cmd, []string{namespace.NET}); err != nil {
+		return process.KillByKeyWithErr(ctx, getProxyKey(i.Info.Uid), fmt.Sprintf("start proxy error: %s", err.Error()))
+	}
+
+	rules := []string{getRedirectRule(ip, port, i.Args.ProxyPort, mark)}
+	if err := net.AddIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, rules); err != nil {
+		return process.KillByKeyWithErr(ctx, getProxyKey(i.Info.Uid), fmt.Sprintf("add redirect rule error: %s", err.Error()))
+	}
+	i.Runtime.Rules = rules
+
+	return nil
+}
+
+func (i *ProxyInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if err := net.DeleteIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Runtime.Rules); err != nil {
+		return fmt.Errorf("delete redirect rule error: %s", err.Error())
+	}
+
+	return process.CheckExistAndKillByKey(ctx, getProxyKey(i.Info.Uid))
+}
@@ -44,6 +44,29 @@ const (
 	DefaultGap     = 3
 	DefaultLatency = "1s"
 
+	FaultTcpReset = "tcpreset"
+	FaultTcpHang  = "tcphang"
+	// the connections created after inject are marked by a bit of connmark in [1<<24, 1<<31], so they are not hung
+	TcpHangMarkShift = 24
+	TcpHangMarkCount = 8
+
+	FaultIfDown     = "ifdown"
+	LinkWatchdogKey = "chaosmeta_watchdog"
+
+	FaultMtu = "mtu"
+	MinMtu   = 68
+	MaxMtu   = 65535
+
+	FaultNeighbor      = "neighbor"
+	DefaultNeighborMac = "02:00:00:00:00:01"
+
+	ScheduleKey          = "chaosmeta_tcschedule"
+	ScheduleRamp         = "ramp"
+	ScheduleBurst        = "burst"
+	ScheduleValueHolder  = "{value}"
+	DefaultRampInterval  = "10s"
+	DefaultBurstOffValue = "0"
+
 	//NetworkExec = "chaosmeta_network"
 )
 
@@ -42,14 +42,13 @@ type DelayArgs struct {
 	Jitter    string `json:"jitter"`
 	Direction string `json:"direction"`
 	Mode      string `json:"mode"`
-	SrcIp     string `json:"src_ip,omitempty"`
-	DstIp     string `json:"dst_ip,omitempty"`
-	SrcPort   string `json:"src_port,omitempty"`
-	DstPort   string `json:"dst_port,omitempty"`
 	Force     bool   `json:"force,omitempty"`
+	FilterArgs
 }
 
-type DelayRuntime struct{}
+type DelayRuntime struct {
+	FilterRuntime
+}
 
 func (i *DelayInjector) GetArgs() interface{} {
 	return &i.Args
@@ -78,14 +77,13 @@ func (i *DelayInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Jitter, "jitter", "j", "0", "jitter time value, support unit: \"s、ms、us\"(default us)")
 
 	cmd.Flags().StringVarP(&i.Args.Direction, "direction", "d", "", fmt.Sprintf("flow direction to inject, support: %s（default %s）", DirectionOut, DirectionOut))
+	injector.SetFlagEnum(cmd, "direction", DirectionOut)
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s（default）、%s(means white list mode)", net.ModeNormal, net.ModeExclude))
+	injector.SetFlagEnum(cmd, "mode", net.ModeNormal, net.ModeExclude)
 	cmd.Flags().BoolVarP(&i.Args.Force, "force", "f", false, "force will overwrite the network rule if old rule exist")
 
 	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "filter condition: network interface. eg: lo")
-	cmd.Flags().StringVar(&i.Args.SrcIp, "src-ip", "", "filter condition: source ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.DstIp, "dst-ip", "", "filter condition: destination ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.SrcPort, "src-port", "", "filter condition: source port. eg: 8080,9090,12000/8")
-	cmd.Flags().StringVar(&i.Args.DstPort, "dst-port", "", "filter condition: destination port. eg: 8080,9090,12000/8")
+	setFilterOption(cmd, &i.Args.FilterArgs)
 }
 
 // Validator Only one tc network failure can be executed at the same time
@@ -124,28 +122,8 @@ func (i *DelayInjector) Validator(ctx context.Context) error {
 		return fmt.Errorf("\"mode\" is not support: %s, only support: %s, %s", i.Args.Mode, net.ModeNormal, net.ModeExclude)
 	}
 
-	if i.Args.SrcIp != "" {
-		if _, err := net.GetValidIPList(i.Args.SrcIp, true); err != nil {
-			return fmt.Errorf("\"src-ip\"[%s] is invalid: %s", i.Args.SrcIp, err.Error())
-		}
-	}
-
-	if i.Args.DstIp != "" {
-		if _, err := net.GetValidIPList(i.Args.DstIp, true); err != nil {
-			return fmt.Errorf("\"dst-ip\"[%s] is invalid: %s", i.Args.DstIp, err.Error())
-		}
-	}
-
-	if i.Args.SrcPort != "" {
-		if _, err := net.GetValidPortList(i.Args.SrcPort); err != nil {
-			return fmt.Errorf("\"src-port\"[%s] is invalid: %s", i.Args.SrcPort, err.Error())
-		}
-	}
-
-	if i.Args.DstPort != "" {
-		if _, err := net.GetValidPortList(i.Args.DstPort); err != nil {
-			return fmt.Errorf("\"dst-port\"[%s] is invalid: %s", i.Args.DstPort, err.Error())
-		}
+	if err := i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId); err != nil {
+		return err
 	}
 
 	exist, err := net.ExistTCRootQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
@@ -170,7 +148,7 @@ func (i *DelayInjector) Inject(ctx context.Context) error {
 		}
 	}
 
-	if i.Args.SrcIp == "" && i.Args.DstIp == "" && i.Args.SrcPort == "" && i.Args.DstPort == "" {
+	if i.Args.FilterArgs.isEmpty() {
 		return net.AddNetemQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, "", FaultDelay, fmt.Sprintf("%s %s", i.Args.Latency, i.Args.Jitter))
 	}
 
@@ -192,7 +170,7 @@ func (i *DelayInjector) Inject(ctx context.Context) error {
 		}
 	}
 
-	if err := net.AddFilter(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, "1:4", i.Args.SrcIp, i.Args.DstIp, i.Args.SrcPort, i.Args.DstPort); err != nil {
+	if err := addFilter(ctx, &i.Info, i.Args.Interface, "1:4", &i.Args.FilterArgs, &i.Runtime.FilterRuntime); err != nil {
 		return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("add filter for %s error: %s", i.Args.Interface, err.Error()))
 	}
 
@@ -204,5 +182,15 @@ func (i *DelayInjector) Recover(ctx context.Context) error {
 		return nil
 	}
 
-	return execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if err := execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface); err != nil {
+		return err
+	}
+
+	return recoverFilter(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
+
+func (i *DelayInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
 }
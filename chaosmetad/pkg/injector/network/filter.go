@@ -0,0 +1,336 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/containerd/cgroups"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cgroup"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/containercgroup"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/filesys"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"hash/fnv"
+	"strings"
+)
+
+// IpPortArgs is the ip and port condition of packets, src is the local side and dst is the remote side
+type IpPortArgs struct {
+	SrcIp   string `json:"src_ip,omitempty"`
+	DstIp   string `json:"dst_ip,omitempty"`
+	SrcPort string `json:"src_port,omitempty"`
+	DstPort string `json:"dst_port,omitempty"`
+}
+
+// FilterArgs is the condition to select the packets to inject, all the provided conditions must be matched.
+// if "pid" or "key" is provided, target processes are moved to a net_cls cgroup of the experiment,
+// and their packets are marked by iptables, so that only their packets are matched.
+// net_cls is a controller of cgroup v1 only, so "pid" and "key" are not supported on cgroup v2 host
+type FilterArgs struct {
+	IpPortArgs
+	Protocol string `json:"protocol,omitempty"`
+	Tos      string `json:"tos,omitempty"`
+	Dscp     string `json:"dscp,omitempty"`
+	Pid      int    `json:"pid,omitempty"`
+	Key      string `json:"key,omitempty"`
+}
+
+type FilterRuntime struct {
+	ClassId      uint32         `json:"class_id,omitempty"`
+	Mark         uint32         `json:"mark,omitempty"`
+	OldCgroupMap map[int]string `json:"old_cgroup_map,omitempty"`
+}
+
+func setIpPortOption(cmd *cobra.Command, args *IpPortArgs) {
+	cmd.Flags().StringVar(&args.SrcIp, "src-ip", "", "filter condition: source ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
+	cmd.Flags().StringVar(&args.DstIp, "dst-ip", "", "filter condition: destination ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
+	cmd.Flags().StringVar(&args.SrcPort, "src-port", "", "filter condition: source port. eg: 8080,9090,12000/8")
+	cmd.Flags().StringVar(&args.DstPort, "dst-port", "", "filter condition: destination port. eg: 8080,9090,12000/8")
+}
+
+func (args *IpPortArgs) isEmpty() bool {
+	return args.SrcIp == "" && args.DstIp == "" && args.SrcPort == "" && args.DstPort == ""
+}
+
+func (args *IpPortArgs) validate() error {
+	if args.SrcIp != "" {
+		if _, err := net.GetValidIPList(args.SrcIp, true); err != nil {
+			return fmt.Errorf("\"src-ip\"[%s] is invalid: %s", args.SrcIp, err.Error())
+		}
+	}
+
+	if args.DstIp != "" {
+		if _, err := net.GetValidIPList(args.DstIp, true); err != nil {
+			return fmt.Errorf("\"dst-ip\"[%s] is invalid: %s", args.DstIp, err.Error())
+		}
+	}
+
+	if args.SrcPort != "" {
+		if _, err := net.GetValidPortList(args.SrcPort); err != nil {
+			return fmt.Errorf("\"src-port\"[%s] is invalid: %s", args.SrcPort, err.Error())
+		}
+	}
+
+	if args.DstPort != "" {
+		if _, err := net.GetValidPortList(args.DstPort); err != nil {
+			return fmt.Errorf("\"dst-port\"[%s] is invalid: %s", args.DstPort, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (args *IpPortArgs) getFilter() *net.Filter {
+	return &net.Filter{
+		SrcIp:   args.SrcIp,
+		DstIp:   args.DstIp,
+		SrcPort: args.SrcPort,
+		DstPort: args.DstPort,
+	}
+}
+
+func setFilterOption(cmd *cobra.Command, args *FilterArgs) {
+	setIpPortOption(cmd, &args.IpPortArgs)
+	cmd.Flags().StringVar(&args.Protocol, "protocol", "", fmt.Sprintf("filter condition: ip protocol, support: %s、%s、%s", net.ProtocolTCP, net.ProtocolUDP, net.ProtocolICMP))
+	injector.SetFlagEnum(cmd, "protocol", net.ProtocolTCP, net.ProtocolUDP, net.ProtocolICMP)
+	cmd.Flags().StringVar(&args.Tos, "tos", "", "filter condition: tos byte of ip header, in [0,255]. eg: 0x10")
+	cmd.Flags().StringVar(&args.Dscp, "dscp", "", "filter condition: dscp of ip header, in [0,63]. eg: 46")
+	cmd.Flags().IntVar(&args.Pid, "pid", 0, "filter condition: only the packets sent by the target process, need cgroup v1 controller net_cls")
+	cmd.Flags().StringVar(&args.Key, "key", "", "filter condition: only the packets sent by the processes found by key, the effect is equivalent to \"ps -ef | grep [key]\". if \"pid\" provided, \"key\" will be ignored, need cgroup v1 controller net_cls")
+}
+
+func (args *FilterArgs) isEmpty() bool {
+	return args.IpPortArgs.isEmpty() &&
+		args.Protocol == "" && args.Tos == "" && args.Dscp == "" && args.Pid == 0 && args.Key == ""
+}
+
+func (args *FilterArgs) validate(ctx context.Context, cr, cId string) error {
+	if err := args.IpPortArgs.validate(); err != nil {
+		return err
+	}
+
+	if args.Protocol != "" {
+		if _, err := net.GetValidProtocol(args.Protocol); err != nil {
+			return fmt.Errorf("\"protocol\"[%s] is invalid: %s", args.Protocol, err.Error())
+		}
+	}
+
+	if args.Tos != "" && args.Dscp != "" {
+		return fmt.Errorf("\"tos\" and \"dscp\" can not be provided at the same time")
+	}
+
+	if args.Tos != "" {
+		if _, err := net.GetValidTos(args.Tos); err != nil {
+			return fmt.Errorf("\"tos\"[%s] is invalid: %s", args.Tos, err.Error())
+		}
+	}
+
+	if args.Dscp != "" {
+		if _, err := net.GetValidDscp(args.Dscp); err != nil {
+			return fmt.Errorf("\"dscp\"[%s] is invalid: %s", args.Dscp, err.Error())
+		}
+	}
+
+	if args.Pid == 0 && args.Key == "" {
+		return nil
+	}
+
+	if !cmdexec.SupportCmd("iptables") {
+		return fmt.Errorf("not support command \"iptables\", which is needed by \"pid\" or \"key\"")
+	}
+
+	if cgroups.Mode() == cgroups.Unified {
+		return fmt.Errorf("\"pid\" and \"key\" need the cgroup v1 controller %s, which is not available on cgroup v2 host", cgroup.NETCLS)
+	}
+
+	exist, err := filesys.ExistPathLocal(fmt.Sprintf("%s/%s", containercgroup.RootCgroupPath, cgroup.NETCLS))
+	if err != nil {
+		return fmt.Errorf("check cgroup %s exist error: %s", cgroup.NETCLS, err.Error())
+	}
+
+	if !exist {
+		return fmt.Errorf("cgroup v1 controller %s is not mounted, which is needed by \"pid\" or \"key\"", cgroup.NETCLS)
+	}
+
+	pidList, err := process.GetPidListByPidOrKeyInContainer(ctx, cr, cId, args.Pid, args.Key)
+	if err != nil {
+		return fmt.Errorf("\"pid\" or \"key\" is invalid: %s", err.Error())
+	}
+
+	oldMap, err := cgroup.GetPidListCurCgroup(ctx, pidList, cgroup.NETCLS)
+	if err != nil {
+		return fmt.Errorf("get %s cgroup of %v error: %s", cgroup.NETCLS, pidList, err.Error())
+	}
+
+	for pid, path := range oldMap {
+		if strings.Contains(path, cgroup.NetClsCgroupName) {
+			return fmt.Errorf("%d is in experiment[%s]", pid, path)
+		}
+	}
+
+	return nil
+}
+
+// getClassId generate the net_cls classid of the experiment
+func getClassId(uid string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	if id := h.Sum32(); id != 0 {
+		return id
+	}
+
+	return 1
+}
+
+// getMarkRule only the bits in net.MarkMask are set, the bits used by others are kept
+func getMarkRule(classId, mark uint32) string {
+	return fmt.Sprintf("%s -t mangle -m cgroup --cgroup 0x%x -j MARK --set-xmark %s", net.ChainOutput, classId, net.GetMarkStr(mark))
+}
+
+// addFilter add the tc filter to classify the packets to target, the target processes are marked first if provided
+func addFilter(ctx context.Context, info *injector.BaseInfo, netInterface, target string, args *FilterArgs, runtime *FilterRuntime) error {
+	filter := args.IpPortArgs.getFilter()
+	filter.Protocol, filter.Tos, filter.Dscp = args.Protocol, args.Tos, args.Dscp
+
+	if args.Pid != 0 || args.Key != "" {
+		if err := markProcess(ctx, info, args, runtime); err != nil {
+			return fmt.Errorf("mark packets of target process error: %s", err.Error())
+		}
+		filter.Mark = runtime.Mark
+	}
+
+	if err := net.AddFilter(ctx, info.ContainerRuntime, info.ContainerId, netInterface, target, filter); err != nil {
+		if err := recoverFilter(ctx, info, runtime); err != nil {
+			log.GetLogger(ctx).Warnf("undo process mark error: %s", err.Error())
+		}
+		return err
+	}
+
+	return nil
+}
+
+func markProcess(ctx context.Context, info *injector.BaseInfo, args *FilterArgs, runtime *FilterRuntime) error {
+	logger := log.GetLogger(ctx)
+	pidList, err := process.GetPidListByPidOrKeyInContainer(ctx, info.ContainerRuntime, info.ContainerId, args.Pid, args.Key)
+	if err != nil {
+		return err
+	}
+
+	runtime.OldCgroupMap, err = cgroup.GetPidListCurCgroup(ctx, pidList, cgroup.NETCLS)
+	if err != nil {
+		return fmt.Errorf("get old path error: %s", err.Error())
+	}
+	logger.Debugf("old cgroup path: %v", runtime.OldCgroupMap)
+
+	containerCgroup, err := getContainerNetClsCgroup(ctx, info)
+	if err != nil {
+		return err
+	}
+
+	runtime.ClassId, runtime.Mark = getClassId(info.Uid), net.GetMark(info.Uid)
+	netClsPath := cgroup.GetNetClsCPath(info.Uid, containerCgroup)
+	if err := cgroup.NewCgroup(ctx, netClsPath, cgroup.GetNetClsConfig(runtime.ClassId, netClsPath)); err != nil {
+		if err := recoverFilter(ctx, info, runtime); err != nil {
+			logger.Warnf("undo error: %s", err.Error())
+		}
+		return fmt.Errorf("create cgroup[%s] error: %s", netClsPath, err.Error())
+	}
+
+	if err := cgroup.MovePidListToCgroup(ctx, pidList, netClsPath); err != nil {
+		if err := recoverFilter(ctx, info, runtime); err != nil {
+			logger.Warnf("undo error: %s", err.Error())
+		}
+		return fmt.Errorf("move pid list to cgroup[%s] error: %s", netClsPath, err.Error())
+	}
+
+	if err := net.AddIptablesRules(ctx, info.ContainerRuntime, info.ContainerId, []string{getMarkRule(runtime.ClassId, runtime.Mark)}); err != nil {
+		if err := recoverFilter(ctx, info, runtime); err != nil {
+			logger.Warnf("undo error: %s", err.Error())
+		}
+		return fmt.Errorf("add iptables mark rule error: %s", err.Error())
+	}
+
+	return nil
+}
+
+// recoverFilter remove the mark rule and move the target processes back to their old cgroup
+func recoverFilter(ctx context.Context, info *injector.BaseInfo, runtime *FilterRuntime) error {
+	if runtime.ClassId == 0 {
+		return nil
+	}
+
+	logger := log.GetLogger(ctx)
+	if err := net.DeleteIptablesRules(ctx, info.ContainerRuntime, info.ContainerId, []string{getMarkRule(runtime.ClassId, runtime.Mark)}); err != nil {
+		return fmt.Errorf("delete iptables mark rule error: %s", err.Error())
+	}
+
+	containerCgroup, err := getContainerNetClsCgroup(ctx, info)
+	if err != nil {
+		return err
+	}
+
+	netClsPath := cgroup.GetNetClsCPath(info.Uid, containerCgroup)
+	exist, err := filesys.ExistPathLocal(netClsPath)
+	if err != nil {
+		return fmt.Errorf("check cgroup[%s] exist error: %s", netClsPath, err.Error())
+	}
+
+	if !exist {
+		return nil
+	}
+
+	pidList, err := cgroup.GetPidStrListByCgroup(ctx, netClsPath)
+	if err != nil {
+		return fmt.Errorf("fail to get pid from cgroup[%s]: %s", netClsPath, err.Error())
+	}
+
+	for _, pid := range pidList {
+		oldPath, ok := runtime.OldCgroupMap[pid]
+		if !ok {
+			logger.Warnf("fail to get pid[%d]'s old cgroup path, move to \"%s\" instead", pid, containerCgroup)
+			oldPath = containerCgroup
+		}
+
+		if err := cgroup.MoveTaskToCgroup(ctx, pid, fmt.Sprintf("%s/%s%s", containercgroup.RootCgroupPath, cgroup.NETCLS, oldPath)); err != nil {
+			return fmt.Errorf("recover pid[%d] error: %s", pid, err.Error())
+		}
+	}
+
+	if err := cgroup.RemoveCgroup(ctx, netClsPath); err != nil {
+		return fmt.Errorf("remove cgroup[%s] error: %s", netClsPath, err.Error())
+	}
+
+	return nil
+}
+
+func getContainerNetClsCgroup(ctx context.Context, info *injector.BaseInfo) (string, error) {
+	if info.ContainerRuntime == "" {
+		return "", nil
+	}
+
+	path, err := cgroup.GetContainerCgroupPath(ctx, info.ContainerRuntime, info.ContainerId, cgroup.NETCLS)
+	if err != nil {
+		return "", fmt.Errorf("get %s cgroup path of container[%s] error: %s", cgroup.NETCLS, info.ContainerId, err.Error())
+	}
+
+	return path, nil
+}
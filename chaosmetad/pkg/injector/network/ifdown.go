@@ -0,0 +1,144 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+)
+
+func init() {
+	injector.Register(TargetNetwork, FaultIfDown, func() injector.IInjector { return &IfDownInjector{} })
+}
+
+// IfDownInjector set the link down, a watchdog process brings it up when timeout even if the agent is lost
+type IfDownInjector struct {
+	injector.BaseInjector
+	Args    IfDownArgs
+	Runtime IfDownRuntime
+}
+
+type IfDownArgs struct {
+	Interface string `json:"interface"`
+}
+
+type IfDownRuntime struct {
+	Up bool `json:"up"`
+}
+
+func (i *IfDownInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *IfDownInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *IfDownInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "network interface to set down. eg: eth0")
+}
+
+func (i *IfDownInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Interface == "" {
+		return fmt.Errorf("\"interface\" is empty")
+	}
+
+	if i.Info.Timeout == "" {
+		return fmt.Errorf("\"timeout\" must provide, the watchdog brings the link up when timeout")
+	}
+
+	if !cmdexec.SupportCmd("ip") {
+		return fmt.Errorf("not support command \"ip\"")
+	}
+
+	link, err := net.GetLinkInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if err != nil {
+		return fmt.Errorf("get info of interface[%s] error: %s", i.Args.Interface, err.Error())
+	}
+
+	if !link.Up {
+		return fmt.Errorf("interface[%s] is already down", i.Args.Interface)
+	}
+
+	return nil
+}
+
+func getLinkWatchdogKey(uid string) string {
+	return fmt.Sprintf("%s %s", LinkWatchdogKey, uid)
+}
+
+// startLinkWatchdog the watchdog only enter the net namespace of container, so the "ip" command of host is used
+func (i *IfDownInjector) startLinkWatchdog(ctx context.Context) error {
+	timeout, _ := utils.GetTimeSecond(i.Info.Timeout)
+	cmd := fmt.Sprintf(": %s; sleep %d; %s", getLinkWatchdogKey(i.Info.Uid), timeout, net.GetSetLinkCmd(i.Args.Interface, "up"))
+	if i.Info.ContainerRuntime == "" {
+		return cmdexec.StartBashCmd(ctx, cmd)
+	}
+
+	_, err := cmdexec.ExecContainer(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, []string{namespace.NET}, cmd, cmdexec.ExecStart)
+	return err
+}
+
+func (i *IfDownInjector) Inject(ctx context.Context) error {
+	i.Runtime.Up = true
+	if err := i.startLinkWatchdog(ctx); err != nil {
+		return fmt.Errorf("start watchdog error: %s", err.Error())
+	}
+
+	if err := net.SetLinkUp(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, false); err != nil {
+		if err := process.CheckExistAndKillByKey(ctx, getLinkWatchdogKey(i.Info.Uid)); err != nil {
+			log.GetLogger(ctx).Warnf("undo: kill watchdog error: %s", err.Error())
+		}
+		return fmt.Errorf("set interface[%s] down error: %s", i.Args.Interface, err.Error())
+	}
+
+	return nil
+}
+
+func (i *IfDownInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if err := process.CheckExistAndKillByKey(ctx, getLinkWatchdogKey(i.Info.Uid)); err != nil {
+		return fmt.Errorf("kill watchdog error: %s", err.Error())
+	}
+
+	if !i.Runtime.Up {
+		return nil
+	}
+
+	return net.SetLinkUp(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, true)
+}
+
+func (i *IfDownInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
+}
@@ -41,14 +41,15 @@ type LimitArgs struct {
 	Rate      string `json:"rate"`
 	Direction string `json:"direction"`
 	Mode      string `json:"mode"`
-	SrcIp     string `json:"src_ip,omitempty"`
-	DstIp     string `json:"dst_ip,omitempty"`
-	SrcPort   string `json:"src_port,omitempty"`
-	DstPort   string `json:"dst_port,omitempty"`
 	Force     bool   `json:"force,omitempty"`
+	FilterArgs
+	ScheduleArgs
 }
 
-type LimitRuntime struct{}
+type LimitRuntime struct {
+	FilterRuntime
+	Timeline *Timeline `json:"timeline,omitempty"`
+}
 
 func (i *LimitInjector) GetArgs() interface{} {
 	return &i.Args
@@ -68,6 +69,8 @@ func (i *LimitInjector) SetDefault() {
 	if i.Args.Mode == "" {
 		i.Args.Mode = net.ModeNormal
 	}
+
+	i.Args.ScheduleArgs.setDefault()
 }
 
 func (i *LimitInjector) SetOption(cmd *cobra.Command) {
@@ -75,15 +78,15 @@ func (i *LimitInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Rate, "rate", "r", "", "limit rate, means how fast per second, support unit: \"bit、kbit、mbit、gbit、tbit\"(default bit)")
 
 	cmd.Flags().StringVarP(&i.Args.Direction, "direction", "d", "", fmt.Sprintf("flow direction to inject, support: %s（default %s）", DirectionOut, DirectionOut))
+	injector.SetFlagEnum(cmd, "direction", DirectionOut)
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s（default）、%s(means white list mode)", net.ModeNormal, net.ModeExclude))
+	injector.SetFlagEnum(cmd, "mode", net.ModeNormal, net.ModeExclude)
 	cmd.Flags().BoolVarP(&i.Args.Force, "force", "f", false, "force will overwrite the network rule if old rule exist")
 
 	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "filter condition: network interface. eg: lo")
-	cmd.Flags().StringVar(&i.Args.SrcIp, "src-ip", "", "filter condition: source ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.DstIp, "dst-ip", "", "filter condition: destination ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.SrcPort, "src-port", "", "filter condition: source port. eg: 8080,9090,12000/8")
-	cmd.Flags().StringVar(&i.Args.DstPort, "dst-port", "", "filter condition: destination port. eg: 8080,9090,12000/8")
+	setFilterOption(cmd, &i.Args.FilterArgs)
 
+	setScheduleOption(cmd, &i.Args.ScheduleArgs, false)
 }
 
 // Validator Only one tc network failure can be executed at the same time
@@ -115,28 +118,12 @@ func (i *LimitInjector) Validator(ctx context.Context) error {
 		return fmt.Errorf("\"mode\" is not support: %s, only support: %s, %s", i.Args.Mode, net.ModeNormal, net.ModeExclude)
 	}
 
-	if i.Args.SrcIp != "" {
-		if _, err := net.GetValidIPList(i.Args.SrcIp, true); err != nil {
-			return fmt.Errorf("\"src-ip\"[%s] is invalid: %s", i.Args.SrcIp, err.Error())
-		}
-	}
-
-	if i.Args.DstIp != "" {
-		if _, err := net.GetValidIPList(i.Args.DstIp, true); err != nil {
-			return fmt.Errorf("\"dst-ip\"[%s] is invalid: %s", i.Args.DstIp, err.Error())
-		}
-	}
-
-	if i.Args.SrcPort != "" {
-		if _, err := net.GetValidPortList(i.Args.SrcPort); err != nil {
-			return fmt.Errorf("\"src-port\"[%s] is invalid: %s", i.Args.SrcPort, err.Error())
-		}
+	if err := i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId); err != nil {
+		return err
 	}
 
-	if i.Args.DstPort != "" {
-		if _, err := net.GetValidPortList(i.Args.DstPort); err != nil {
-			return fmt.Errorf("\"dst-port\"[%s] is invalid: %s", i.Args.DstPort, err.Error())
-		}
+	if err := i.Args.ScheduleArgs.validate(false, utils.CheckSpeedValue); err != nil {
+		return err
 	}
 
 	exist, err := net.ExistTCRootQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
@@ -169,13 +156,23 @@ func (i *LimitInjector) Inject(ctx context.Context) error {
 		return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("add limit class for %s error: %s", i.Args.Interface, err.Error()))
 	}
 
-	if i.Args.SrcIp != "" || i.Args.DstIp != "" || i.Args.SrcPort != "" || i.Args.DstPort != "" {
-		if err := net.AddFilter(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, "1:2", i.Args.SrcIp, i.Args.DstIp, i.Args.SrcPort, i.Args.DstPort); err != nil {
+	if !i.Args.FilterArgs.isEmpty() {
+		if err := addFilter(ctx, &i.Info, i.Args.Interface, "1:2", &i.Args.FilterArgs, &i.Runtime.FilterRuntime); err != nil {
 			return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("add filter for %s error: %s", i.Args.Interface, err.Error()))
 		}
 	}
 
-	return nil
+	if i.Args.Schedule == "" {
+		return nil
+	}
+
+	timeline, err := getLimitTimeline(i.Args.Interface, i.Args.Rate, i.Args.Mode, &i.Args.ScheduleArgs)
+	if err != nil {
+		return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("get timeline error: %s", err.Error()))
+	}
+	i.Runtime.Timeline = timeline
+
+	return startSchedule(ctx, &i.Info, i.Args.Interface, i.Runtime.Timeline)
 }
 
 func (i *LimitInjector) Recover(ctx context.Context) error {
@@ -183,5 +180,21 @@ func (i *LimitInjector) Recover(ctx context.Context) error {
 		return nil
 	}
 
-	return execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if i.Args.Schedule != "" {
+		if err := stopSchedule(ctx, i.Info.Uid); err != nil {
+			return err
+		}
+	}
+
+	if err := execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface); err != nil {
+		return err
+	}
+
+	return recoverFilter(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
+
+func (i *LimitInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
 }
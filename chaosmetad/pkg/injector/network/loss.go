@@ -42,14 +42,15 @@ type LossArgs struct {
 	Percent   int    `json:"percent"`
 	Direction string `json:"direction"`
 	Mode      string `json:"mode"`
-	SrcIp     string `json:"src_ip,omitempty"`
-	DstIp     string `json:"dst_ip,omitempty"`
-	SrcPort   string `json:"src_port,omitempty"`
-	DstPort   string `json:"dst_port,omitempty"`
 	Force     bool   `json:"force,omitempty"`
+	FilterArgs
+	ScheduleArgs
 }
 
-type LossRuntime struct{}
+type LossRuntime struct {
+	FilterRuntime
+	Timeline *Timeline `json:"timeline,omitempty"`
+}
 
 func (i *LossInjector) GetArgs() interface{} {
 	return &i.Args
@@ -69,6 +70,8 @@ func (i *LossInjector) SetDefault() {
 	if i.Args.Mode == "" {
 		i.Args.Mode = net.ModeNormal
 	}
+
+	i.Args.ScheduleArgs.setDefault()
 }
 
 func (i *LossInjector) SetOption(cmd *cobra.Command) {
@@ -76,14 +79,15 @@ func (i *LossInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().IntVarP(&i.Args.Percent, "percent", "p", 0, "packets loss percent, an integer in (0,100] without \"%\", eg: \"30\" means \"30%\"")
 
 	cmd.Flags().StringVarP(&i.Args.Direction, "direction", "d", "", fmt.Sprintf("flow direction to inject, support: %s（default %s）", DirectionOut, DirectionOut))
+	injector.SetFlagEnum(cmd, "direction", DirectionOut)
 	cmd.Flags().StringVarP(&i.Args.Mode, "mode", "m", "", fmt.Sprintf("inject mode, support: %s（default）、%s(means white list mode)", net.ModeNormal, net.ModeExclude))
+	injector.SetFlagEnum(cmd, "mode", net.ModeNormal, net.ModeExclude)
 	cmd.Flags().BoolVarP(&i.Args.Force, "force", "f", false, "force will overwrite the network rule if old rule exist")
 
 	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "filter condition: network interface. eg: lo")
-	cmd.Flags().StringVar(&i.Args.SrcIp, "src-ip", "", "filter condition: source ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.DstIp, "dst-ip", "", "filter condition: destination ip. eg: 10.10.0.0/16,192.168.2.5,192.168.1.0/24")
-	cmd.Flags().StringVar(&i.Args.SrcPort, "src-port", "", "filter condition: source port. eg: 8080,9090,12000/8")
-	cmd.Flags().StringVar(&i.Args.DstPort, "dst-port", "", "filter condition: destination port. eg: 8080,9090,12000/8")
+	setFilterOption(cmd, &i.Args.FilterArgs)
+
+	setScheduleOption(cmd, &i.Args.ScheduleArgs, true)
 }
 
 // Validator Only one tc network failure can be executed at the same time
@@ -111,28 +115,12 @@ func (i *LossInjector) Validator(ctx context.Context) error {
 		return fmt.Errorf("\"mode\" is not support: %s, only support: %s, %s", i.Args.Mode, net.ModeNormal, net.ModeExclude)
 	}
 
-	if i.Args.SrcIp != "" {
-		if _, err := net.GetValidIPList(i.Args.SrcIp, true); err != nil {
-			return fmt.Errorf("\"src-ip\"[%s] is invalid: %s", i.Args.SrcIp, err.Error())
-		}
-	}
-
-	if i.Args.DstIp != "" {
-		if _, err := net.GetValidIPList(i.Args.DstIp, true); err != nil {
-			return fmt.Errorf("\"dst-ip\"[%s] is invalid: %s", i.Args.DstIp, err.Error())
-		}
-	}
-
-	if i.Args.SrcPort != "" {
-		if _, err := net.GetValidPortList(i.Args.SrcPort); err != nil {
-			return fmt.Errorf("\"src-port\"[%s] is invalid: %s", i.Args.SrcPort, err.Error())
-		}
+	if err := i.Args.FilterArgs.validate(ctx, i.Info.ContainerRuntime, i.Info.ContainerId); err != nil {
+		return err
 	}
 
-	if i.Args.DstPort != "" {
-		if _, err := net.GetValidPortList(i.Args.DstPort); err != nil {
-			return fmt.Errorf("\"dst-port\"[%s] is invalid: %s", i.Args.DstPort, err.Error())
-		}
+	if err := i.Args.ScheduleArgs.validate(true, checkPercentValue); err != nil {
+		return err
 	}
 
 	exist, err := net.ExistTCRootQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
@@ -148,6 +136,25 @@ func (i *LossInjector) Validator(ctx context.Context) error {
 }
 
 func (i *LossInjector) Inject(ctx context.Context) error {
+	if err := i.injectRule(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Schedule == "" {
+		return nil
+	}
+
+	filtered := !i.Args.FilterArgs.isEmpty()
+	timeline, err := getPercentTimeline(i.Args.Interface, FaultLoss, i.Args.Mode, filtered, i.Args.Percent, &i.Args.ScheduleArgs)
+	if err != nil {
+		return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("get timeline error: %s", err.Error()))
+	}
+	i.Runtime.Timeline = timeline
+
+	return startSchedule(ctx, &i.Info, i.Args.Interface, i.Runtime.Timeline)
+}
+
+func (i *LossInjector) injectRule(ctx context.Context) error {
 	if i.Args.Force {
 		exist, _ := net.ExistTCRootQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
 		if exist {
@@ -157,7 +164,7 @@ func (i *LossInjector) Inject(ctx context.Context) error {
 		}
 	}
 
-	if i.Args.SrcIp == "" && i.Args.DstIp == "" && i.Args.SrcPort == "" && i.Args.DstPort == "" {
+	if i.Args.FilterArgs.isEmpty() {
 		return net.AddNetemQdisc(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, "", FaultLoss, fmt.Sprintf("%d", i.Args.Percent))
 	}
 
@@ -179,7 +186,7 @@ func (i *LossInjector) Inject(ctx context.Context) error {
 		}
 	}
 
-	if err := net.AddFilter(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, "1:4", i.Args.SrcIp, i.Args.DstIp, i.Args.SrcPort, i.Args.DstPort); err != nil {
+	if err := addFilter(ctx, &i.Info, i.Args.Interface, "1:4", &i.Args.FilterArgs, &i.Runtime.FilterRuntime); err != nil {
 		return undoTcWithErr(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, fmt.Sprintf("add filter for %s error: %s", i.Args.Interface, err.Error()))
 	}
 
@@ -191,5 +198,21 @@ func (i *LossInjector) Recover(ctx context.Context) error {
 		return nil
 	}
 
-	return execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if i.Args.Schedule != "" {
+		if err := stopSchedule(ctx, i.Info.Uid); err != nil {
+			return err
+		}
+	}
+
+	if err := execRecover(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface); err != nil {
+		return err
+	}
+
+	return recoverFilter(ctx, &i.Info, &i.Runtime.FilterRuntime)
+}
+
+func (i *LossInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
 }
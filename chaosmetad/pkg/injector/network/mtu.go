@@ -0,0 +1,119 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+)
+
+func init() {
+	injector.Register(TargetNetwork, FaultMtu, func() injector.IInjector { return &MtuInjector{} })
+}
+
+type MtuInjector struct {
+	injector.BaseInjector
+	Args    MtuArgs
+	Runtime MtuRuntime
+}
+
+type MtuArgs struct {
+	Interface string `json:"interface"`
+	Mtu       int    `json:"mtu"`
+}
+
+type MtuRuntime struct {
+	Mtu int `json:"mtu,omitempty"`
+}
+
+func (i *MtuInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *MtuInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *MtuInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "network interface to change mtu. eg: eth0")
+	cmd.Flags().IntVarP(&i.Args.Mtu, "mtu", "m", 0, fmt.Sprintf("target mtu, an integer in [%d, %d]", MinMtu, MaxMtu))
+}
+
+func (i *MtuInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Interface == "" {
+		return fmt.Errorf("\"interface\" is empty")
+	}
+
+	if i.Args.Mtu < MinMtu || i.Args.Mtu > MaxMtu {
+		return fmt.Errorf("\"mtu\"[%d] must be in [%d, %d]", i.Args.Mtu, MinMtu, MaxMtu)
+	}
+
+	if !cmdexec.SupportCmd("ip") {
+		return fmt.Errorf("not support command \"ip\"")
+	}
+
+	link, err := net.GetLinkInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if err != nil {
+		return fmt.Errorf("get info of interface[%s] error: %s", i.Args.Interface, err.Error())
+	}
+
+	if link.Mtu == i.Args.Mtu {
+		return fmt.Errorf("mtu of interface[%s] is already %d", i.Args.Interface, i.Args.Mtu)
+	}
+
+	return nil
+}
+
+func (i *MtuInjector) Inject(ctx context.Context) error {
+	link, err := net.GetLinkInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface)
+	if err != nil {
+		return fmt.Errorf("get info of interface[%s] error: %s", i.Args.Interface, err.Error())
+	}
+
+	i.Runtime.Mtu = link.Mtu
+	if err := net.SetLinkMtu(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, i.Args.Mtu); err != nil {
+		return fmt.Errorf("set mtu of interface[%s] error: %s", i.Args.Interface, err.Error())
+	}
+
+	return nil
+}
+
+func (i *MtuInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	if i.Runtime.Mtu == 0 {
+		return nil
+	}
+
+	return net.SetLinkMtu(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, i.Runtime.Mtu)
+}
+
+func (i *MtuInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
+}
@@ -0,0 +1,172 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	gonet "net"
+)
+
+func init() {
+	injector.Register(TargetNetwork, FaultNeighbor, func() injector.IInjector { return &NeighborInjector{} })
+}
+
+// NeighborInjector add wrong static arp(ipv4) or ndp(ipv6) entries for the ip list
+type NeighborInjector struct {
+	injector.BaseInjector
+	Args    NeighborArgs
+	Runtime NeighborRuntime
+}
+
+type NeighborArgs struct {
+	Interface string `json:"interface"`
+	Ip        string `json:"ip"`
+	Mac       string `json:"mac"`
+}
+
+// NeighborRuntime only the entries exist before inject are recorded
+type NeighborRuntime struct {
+	Neighbors []*net.Neighbor `json:"neighbors,omitempty"`
+}
+
+func (i *NeighborInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *NeighborInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *NeighborInjector) SetDefault() {
+	i.BaseInjector.SetDefault()
+
+	if i.Args.Mac == "" {
+		i.Args.Mac = DefaultNeighborMac
+	}
+}
+
+func (i *NeighborInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&i.Args.Interface, "interface", "i", "", "network interface of the neighbor entries. eg: eth0")
+	cmd.Flags().StringVar(&i.Args.Ip, "ip", "", "ip list to add wrong neighbor entries, support ipv4 and ipv6. eg: 10.0.0.1,fe80::1")
+	cmd.Flags().StringVarP(&i.Args.Mac, "mac", "m", "", fmt.Sprintf("wrong mac address of the entries（default %s）", DefaultNeighborMac))
+}
+
+func (i *NeighborInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.Interface == "" {
+		return fmt.Errorf("\"interface\" is empty")
+	}
+
+	if i.Args.Ip == "" {
+		return fmt.Errorf("\"ip\" is empty")
+	}
+
+	if _, err := net.GetValidIPList(i.Args.Ip, false); err != nil {
+		return fmt.Errorf("\"ip\" is invalid: %s", err.Error())
+	}
+
+	if _, err := gonet.ParseMAC(i.Args.Mac); err != nil {
+		return fmt.Errorf("\"mac\" is invalid: %s", err.Error())
+	}
+
+	if !cmdexec.SupportCmd("ip") {
+		return fmt.Errorf("not support command \"ip\"")
+	}
+
+	if _, err := net.GetLinkInfo(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface); err != nil {
+		return fmt.Errorf("get info of interface[%s] error: %s", i.Args.Interface, err.Error())
+	}
+
+	return nil
+}
+
+func (i *NeighborInjector) Inject(ctx context.Context) error {
+	ipList, _ := net.GetValidIPList(i.Args.Ip, false)
+	for _, ip := range ipList {
+		old, err := net.GetNeighbor(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, ip)
+		if err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("get neighbor entry of %s error: %s", ip, err.Error()))
+		}
+
+		if old != nil {
+			i.Runtime.Neighbors = append(i.Runtime.Neighbors, old)
+		}
+	}
+
+	for _, ip := range ipList {
+		if err := net.ReplaceNeighbor(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, ip, i.Args.Mac); err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("add neighbor entry of %s error: %s", ip, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func (i *NeighborInjector) getErrWithUndo(ctx context.Context, msg string) error {
+	if err := i.recoverNeighbors(ctx); err != nil {
+		log.GetLogger(ctx).Warnf("undo error: %s", err.Error())
+	}
+
+	return fmt.Errorf(msg)
+}
+
+func (i *NeighborInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return i.recoverNeighbors(ctx)
+}
+
+// recoverNeighbors restore the old permanent entries, other entries are deleted and learned again by kernel
+func (i *NeighborInjector) recoverNeighbors(ctx context.Context) error {
+	var oldMap = make(map[string]*net.Neighbor)
+	for _, unit := range i.Runtime.Neighbors {
+		oldMap[unit.Ip] = unit
+	}
+
+	ipList, _ := net.GetValidIPList(i.Args.Ip, false)
+	for _, ip := range ipList {
+		if old := oldMap[ip]; old != nil && old.IsPermanent() && old.Mac != "" {
+			if err := net.ReplaceNeighbor(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, ip, old.Mac); err != nil {
+				return fmt.Errorf("restore neighbor entry of %s error: %s", ip, err.Error())
+			}
+			continue
+		}
+
+		if err := net.DeleteNeighbor(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Interface, ip); err != nil {
+			return fmt.Errorf("delete neighbor entry of %s error: %s", ip, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *NeighborInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return &injector.Resource{
+		Interfaces: []string{i.Args.Interface},
+	}, nil
+}
@@ -71,6 +71,7 @@ func (i *OccupyInjector) SetOption(cmd *cobra.Command) {
 	cmd.Flags().StringVarP(&i.Args.Protocol, "protocol", "P", "",
 		fmt.Sprintf("target protocol, support: %s、%s、%s、%s（default %s）",
 			net.ProtocolTCP, net.ProtocolUDP, net.ProtocolTCP6, net.ProtocolUDP6, net.ProtocolTCP))
+	injector.SetFlagEnum(cmd, "protocol", net.ProtocolTCP, net.ProtocolUDP, net.ProtocolTCP6, net.ProtocolUDP6)
 	cmd.Flags().BoolVarP(&i.Args.Force, "force", "f", false, "if kill the process which occupied target port")
 	cmd.Flags().StringVarP(&i.Args.RecoverCmd, "recover-cmd", "r", "", "execute in recover stage")
 }
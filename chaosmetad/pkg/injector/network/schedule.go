@@ -0,0 +1,278 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"strconv"
+)
+
+// ScheduleArgs make the tc parameters change over time.
+// ramp: change the value from the fault value to "ramp-to" step by step in "ramp-duration";
+// burst: apply the fault value for "burst-duration" every "burst-interval", the fault is off for the rest time
+type ScheduleArgs struct {
+	Schedule      string `json:"schedule,omitempty"`
+	RampTo        string `json:"ramp_to,omitempty"`
+	RampDuration  string `json:"ramp_duration,omitempty"`
+	RampInterval  string `json:"ramp_interval,omitempty"`
+	BurstDuration string `json:"burst_duration,omitempty"`
+	BurstInterval string `json:"burst_interval,omitempty"`
+}
+
+// Timeline is executed by the supervisor process, the value of each step replace the holder in Commands.
+// if Period > 0, the steps are repeated every Period seconds
+type Timeline struct {
+	Commands []string       `json:"commands"`
+	Steps    []TimelineStep `json:"steps"`
+	Period   int64          `json:"period,omitempty"`
+}
+
+type TimelineStep struct {
+	Offset int64  `json:"offset"`
+	Value  string `json:"value"`
+}
+
+func setScheduleOption(cmd *cobra.Command, args *ScheduleArgs, supportBurst bool) {
+	if supportBurst {
+		cmd.Flags().StringVar(&args.Schedule, "schedule", "", fmt.Sprintf("change the fault value over time, support: %s、%s", ScheduleRamp, ScheduleBurst))
+		injector.SetFlagEnum(cmd, "schedule", ScheduleRamp, ScheduleBurst)
+	} else {
+		cmd.Flags().StringVar(&args.Schedule, "schedule", "", fmt.Sprintf("change the fault value over time, support: %s", ScheduleRamp))
+		injector.SetFlagEnum(cmd, "schedule", ScheduleRamp)
+	}
+
+	cmd.Flags().StringVar(&args.RampTo, "ramp-to", "", "ramp schedule: the final fault value")
+	cmd.Flags().StringVar(&args.RampDuration, "ramp-duration", "", "ramp schedule: how long to reach the final value, support unit: \"s、m、h\"(default s)")
+	cmd.Flags().StringVar(&args.RampInterval, "ramp-interval", "", fmt.Sprintf("ramp schedule: interval to update the value, support unit: \"s、m、h\"(default %s)", DefaultRampInterval))
+	if supportBurst {
+		cmd.Flags().StringVar(&args.BurstDuration, "burst-duration", "", "burst schedule: how long the fault lasts in each burst, support unit: \"s、m、h\"(default s)")
+		cmd.Flags().StringVar(&args.BurstInterval, "burst-interval", "", "burst schedule: interval between the start of two bursts, support unit: \"s、m、h\"(default s)")
+	}
+}
+
+func (args *ScheduleArgs) setDefault() {
+	if args.Schedule == ScheduleRamp && args.RampInterval == "" {
+		args.RampInterval = DefaultRampInterval
+	}
+}
+
+// validate checkValue is used to check the value of "ramp-to"
+func (args *ScheduleArgs) validate(supportBurst bool, checkValue func(string) error) error {
+	switch args.Schedule {
+	case "":
+		return nil
+	case ScheduleRamp:
+		if args.RampTo == "" {
+			return fmt.Errorf("\"ramp-to\" must provide in %s schedule", ScheduleRamp)
+		}
+
+		if err := checkValue(args.RampTo); err != nil {
+			return fmt.Errorf("\"ramp-to\"[%s] is invalid: %s", args.RampTo, err.Error())
+		}
+
+		duration, err := getPositiveSecond("ramp-duration", args.RampDuration)
+		if err != nil {
+			return err
+		}
+
+		interval, err := getPositiveSecond("ramp-interval", args.RampInterval)
+		if err != nil {
+			return err
+		}
+
+		if interval > duration {
+			return fmt.Errorf("\"ramp-interval\" must not be greater than \"ramp-duration\"")
+		}
+	case ScheduleBurst:
+		if !supportBurst {
+			return fmt.Errorf("\"schedule\" only support: %s", ScheduleRamp)
+		}
+
+		duration, err := getPositiveSecond("burst-duration", args.BurstDuration)
+		if err != nil {
+			return err
+		}
+
+		interval, err := getPositiveSecond("burst-interval", args.BurstInterval)
+		if err != nil {
+			return err
+		}
+
+		if duration >= interval {
+			return fmt.Errorf("\"burst-duration\" must be less than \"burst-interval\"")
+		}
+	default:
+		return fmt.Errorf("\"schedule\" is not support: %s", args.Schedule)
+	}
+
+	return nil
+}
+
+func getPositiveSecond(name, value string) (int64, error) {
+	if value == "" {
+		return -1, fmt.Errorf("\"%s\" must provide", name)
+	}
+
+	second, err := utils.GetTimeSecond(value)
+	if err != nil {
+		return -1, fmt.Errorf("\"%s\"[%s] is invalid: %s", name, value, err.Error())
+	}
+
+	if second <= 0 {
+		return -1, fmt.Errorf("\"%s\" must be larger than 0", name)
+	}
+
+	return second, nil
+}
+
+// getRampSteps change the value linearly from start to end, format convert the value to the string used in tc cmd
+func getRampSteps(start, end, duration, interval int64, format func(int64) string) []TimelineStep {
+	count := (duration + interval - 1) / interval
+	steps := make([]TimelineStep, 0, count+1)
+	for k := int64(0); k <= count; k++ {
+		offset := k * interval
+		if offset > duration {
+			offset = duration
+		}
+
+		steps = append(steps, TimelineStep{
+			Offset: offset,
+			Value:  format(start + (end-start)*offset/duration),
+		})
+	}
+
+	return steps
+}
+
+func getLimitTimeline(netInterface, rate, mode string, args *ScheduleArgs) (*Timeline, error) {
+	start, err := utils.GetSpeedBit(rate)
+	if err != nil {
+		return nil, fmt.Errorf("get bit of rate[%s] error: %s", rate, err.Error())
+	}
+
+	end, err := utils.GetSpeedBit(args.RampTo)
+	if err != nil {
+		return nil, fmt.Errorf("get bit of ramp-to[%s] error: %s", args.RampTo, err.Error())
+	}
+
+	duration, _ := utils.GetTimeSecond(args.RampDuration)
+	interval, _ := utils.GetTimeSecond(args.RampInterval)
+	return &Timeline{
+		Commands: []string{net.GetChangeLimitClassCmd(netInterface, ScheduleValueHolder, mode)},
+		Steps: getRampSteps(start, end, duration, interval, func(v int64) string {
+			if v <= 0 {
+				v = 1
+			}
+			return fmt.Sprintf("%dbit", v)
+		}),
+	}, nil
+}
+
+// getPercentTimeline is used by netem faults whose value is a percent, such as loss、corrupt、duplicate
+func getPercentTimeline(netInterface, fault, mode string, filtered bool, percent int, args *ScheduleArgs) (*Timeline, error) {
+	t := &Timeline{}
+	for _, parent := range getNetemParents(filtered, mode) {
+		t.Commands = append(t.Commands, net.GetChangeNetemQdiscCmd(netInterface, parent, fault, ScheduleValueHolder))
+	}
+
+	if args.Schedule == ScheduleRamp {
+		end, err := strconv.Atoi(args.RampTo)
+		if err != nil {
+			return nil, fmt.Errorf("ramp-to[%s] is not a num: %s", args.RampTo, err.Error())
+		}
+
+		duration, _ := utils.GetTimeSecond(args.RampDuration)
+		interval, _ := utils.GetTimeSecond(args.RampInterval)
+		t.Steps = getRampSteps(int64(percent), int64(end), duration, interval, func(v int64) string {
+			return fmt.Sprintf("%d", v)
+		})
+	} else {
+		duration, _ := utils.GetTimeSecond(args.BurstDuration)
+		interval, _ := utils.GetTimeSecond(args.BurstInterval)
+		t.Steps = []TimelineStep{
+			{Offset: 0, Value: fmt.Sprintf("%d", percent)},
+			{Offset: duration, Value: DefaultBurstOffValue},
+		}
+		t.Period = interval
+	}
+
+	return t, nil
+}
+
+// getNetemParents return the parents of netem qdisc created by inject, "" means root
+func getNetemParents(filtered bool, mode string) []string {
+	if !filtered {
+		return []string{""}
+	}
+
+	if mode == net.ModeNormal {
+		return []string{"1:4"}
+	}
+
+	return []string{"1:1", "1:2", "1:3"}
+}
+
+func checkPercentValue(value string) error {
+	percent, err := strconv.Atoi(value)
+	if err != nil {
+		return fmt.Errorf("is not a num: %s", err.Error())
+	}
+
+	if percent < 0 || percent > 100 {
+		return fmt.Errorf("must be in [0,100]")
+	}
+
+	return nil
+}
+
+// startSchedule start the supervisor process to execute the timeline, the tc rule is cleared if failed
+func startSchedule(ctx context.Context, info *injector.BaseInfo, netInterface string, t *Timeline) error {
+	data, err := json.Marshal(t)
+	if err != nil {
+		return undoTcWithErr(ctx, info.ContainerRuntime, info.ContainerId, netInterface, fmt.Sprintf("marshal timeline error: %s", err.Error()))
+	}
+
+	var timeout int64
+	if info.Timeout != "" {
+		timeout, _ = utils.GetTimeSecond(info.Timeout)
+	}
+
+	cmd := fmt.Sprintf("%s %s %s %d", utils.GetToolPath(ScheduleKey), info.Uid, base64.StdEncoding.EncodeToString(data), timeout)
+	if err := cmdexec.WaitCommonWithNS(ctx, info.ContainerRuntime, info.ContainerId, cmd, []string{namespace.NET}); err != nil {
+		return undoTcWithErr(ctx, info.ContainerRuntime, info.ContainerId, netInterface, fmt.Sprintf("start schedule process error: %s", err.Error()))
+	}
+
+	return nil
+}
+
+func stopSchedule(ctx context.Context, uid string) error {
+	if err := process.CheckExistAndKillByKey(ctx, fmt.Sprintf("%s %s", ScheduleKey, uid)); err != nil {
+		return fmt.Errorf("stop schedule process error: %s", err.Error())
+	}
+
+	return nil
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"fmt"
+	"reflect"
+	"testing"
+)
+
+func Test_getRampSteps(t *testing.T) {
+	format := func(v int64) string {
+		return fmt.Sprintf("%d", v)
+	}
+
+	got := getRampSteps(100, 0, 25, 10, format)
+	want := []TimelineStep{
+		{Offset: 0, Value: "100"},
+		{Offset: 10, Value: "60"},
+		{Offset: 20, Value: "20"},
+		{Offset: 25, Value: "0"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getRampSteps() = %v, want %v", got, want)
+	}
+}
+
+func Test_getPercentTimeline(t *testing.T) {
+	got, err := getPercentTimeline("eth0", FaultLoss, "exclude", true, 50, &ScheduleArgs{
+		Schedule:      ScheduleBurst,
+		BurstDuration: "5s",
+		BurstInterval: "1m",
+	})
+	if err != nil {
+		t.Fatalf("getPercentTimeline() error = %v", err)
+	}
+
+	if len(got.Commands) != 3 || got.Commands[0] != "tc qdisc change dev eth0 parent 1:1 netem loss {value}" {
+		t.Errorf("getPercentTimeline() commands = %v", got.Commands)
+	}
+
+	wantSteps := []TimelineStep{{Offset: 0, Value: "50"}, {Offset: 5, Value: "0"}}
+	if !reflect.DeepEqual(got.Steps, wantSteps) || got.Period != 60 {
+		t.Errorf("getPercentTimeline() steps = %v, period = %d", got.Steps, got.Period)
+	}
+}
+
+func TestScheduleArgs_validate(t *testing.T) {
+	tests := []struct {
+		name         string
+		args         ScheduleArgs
+		supportBurst bool
+		wantErr      bool
+	}{
+		{
+			name: "no schedule",
+			args: ScheduleArgs{},
+		},
+		{
+			name: "ramp",
+			args: ScheduleArgs{Schedule: ScheduleRamp, RampTo: "1", RampDuration: "10m", RampInterval: "10s"},
+		},
+		{
+			name:    "ramp without duration",
+			args:    ScheduleArgs{Schedule: ScheduleRamp, RampTo: "1", RampInterval: "10s"},
+			wantErr: true,
+		},
+		{
+			name:    "ramp interval larger than duration",
+			args:    ScheduleArgs{Schedule: ScheduleRamp, RampTo: "1", RampDuration: "5s", RampInterval: "10s"},
+			wantErr: true,
+		},
+		{
+			name:         "burst",
+			args:         ScheduleArgs{Schedule: ScheduleBurst, BurstDuration: "5s", BurstInterval: "1m"},
+			supportBurst: true,
+		},
+		{
+			name:    "burst not support",
+			args:    ScheduleArgs{Schedule: ScheduleBurst, BurstDuration: "5s", BurstInterval: "1m"},
+			wantErr: true,
+		},
+		{
+			name:         "burst duration not less than interval",
+			args:         ScheduleArgs{Schedule: ScheduleBurst, BurstDuration: "1m", BurstInterval: "1m"},
+			supportBurst: true,
+			wantErr:      true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.args.validate(tt.supportBurst, checkPercentValue); (err != nil) != tt.wantErr {
+				t.Errorf("validate() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
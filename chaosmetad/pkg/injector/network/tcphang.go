@@ -0,0 +1,154 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"strings"
+)
+
+func init() {
+	injector.Register(TargetNetwork, FaultTcpHang, func() injector.IInjector { return &TcpHangInjector{} })
+}
+
+// TcpHangInjector drop the packets of the tcp connections established before inject and matched by the filter in both directions,
+// so that the connections hang without closing. the connections created after inject are marked by connmark on their
+// first packet and are not affected
+type TcpHangInjector struct {
+	injector.BaseInjector
+	Args    TcpHangArgs
+	Runtime TcpHangRuntime
+}
+
+type TcpHangArgs struct {
+	IpPortArgs
+}
+
+type TcpHangRuntime struct {
+	Mark  uint32   `json:"mark,omitempty"`
+	Rules []string `json:"rules,omitempty"`
+}
+
+func (i *TcpHangInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *TcpHangInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *TcpHangInjector) SetOption(cmd *cobra.Command) {
+	setIpPortOption(cmd, &i.Args.IpPortArgs)
+}
+
+func (i *TcpHangInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.IpPortArgs.isEmpty() {
+		return fmt.Errorf("must provide at least one filter condition of: src-ip、dst-ip、src-port、dst-port")
+	}
+
+	if err := i.Args.IpPortArgs.validate(); err != nil {
+		return err
+	}
+
+	if !cmdexec.SupportCmd("iptables") {
+		return fmt.Errorf("not support command \"iptables\"")
+	}
+
+	return nil
+}
+
+func (i *TcpHangInjector) Inject(ctx context.Context) error {
+	mark, err := getFreeMark(ctx, i.Info.ContainerRuntime, i.Info.ContainerId)
+	if err != nil {
+		return fmt.Errorf("get free connmark error: %s", err.Error())
+	}
+
+	rules, err := getTcpHangRules(i.Args.IpPortArgs.getFilter(), mark)
+	if err != nil {
+		return err
+	}
+
+	if err := net.AddIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, rules); err != nil {
+		return fmt.Errorf("add drop rules error: %s", err.Error())
+	}
+	i.Runtime.Mark, i.Runtime.Rules = mark, rules
+
+	return nil
+}
+
+func (i *TcpHangInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return net.DeleteIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Runtime.Rules)
+}
+
+func getMarkStr(mark uint32) string {
+	return fmt.Sprintf("%#x/%#x", mark, mark)
+}
+
+// getFreeMark return a connmark bit which is not used by the rules of other tcp hang experiments
+func getFreeMark(ctx context.Context, cr, cId string) (uint32, error) {
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, "iptables -S", []string{namespace.NET})
+	if err != nil {
+		return 0, fmt.Errorf("list iptables rules error: %s", err.Error())
+	}
+
+	for n := 0; n < TcpHangMarkCount; n++ {
+		mark := uint32(1) << (TcpHangMarkShift + n)
+		if !strings.Contains(re, getMarkStr(mark)) {
+			return mark, nil
+		}
+	}
+
+	return 0, fmt.Errorf("all the %d connmark bits are used by other experiments", TcpHangMarkCount)
+}
+
+// getTcpHangRules mark the new connections first, then drop the established connections without the mark
+func getTcpHangRules(filter *net.Filter, mark uint32) ([]string, error) {
+	var rules []string
+	for _, chain := range []string{net.ChainOutput, net.ChainInput} {
+		rules = append(rules, fmt.Sprintf("%s -p tcp -m conntrack --ctstate NEW -j CONNMARK --set-xmark %s", chain, getMarkStr(mark)))
+	}
+
+	for _, unit := range []struct {
+		chain   string
+		reverse bool
+	}{{net.ChainOutput, false}, {net.ChainInput, true}} {
+		argsList, err := net.GetTcpMatchArgsList(filter, unit.reverse)
+		if err != nil {
+			return nil, fmt.Errorf("get iptables match args error: %s", err.Error())
+		}
+
+		for _, args := range argsList {
+			rules = append(rules, fmt.Sprintf("%s %s -m conntrack --ctstate ESTABLISHED -m connmark ! --mark %s -j DROP", unit.chain, args, getMarkStr(mark)))
+		}
+	}
+
+	return rules, nil
+}
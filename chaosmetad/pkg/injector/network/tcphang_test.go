@@ -0,0 +1,40 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+	"reflect"
+	"testing"
+)
+
+func Test_getTcpHangRules(t *testing.T) {
+	got, err := getTcpHangRules(&net.Filter{DstIp: "10.0.0.1", DstPort: "80"}, 1<<TcpHangMarkShift)
+	if err != nil {
+		t.Fatalf("getTcpHangRules() error: %s", err.Error())
+	}
+
+	want := []string{
+		"OUTPUT -p tcp -m conntrack --ctstate NEW -j CONNMARK --set-xmark 0x1000000/0x1000000",
+		"INPUT -p tcp -m conntrack --ctstate NEW -j CONNMARK --set-xmark 0x1000000/0x1000000",
+		"OUTPUT -p tcp -d 10.0.0.1 --dport 80 -m conntrack --ctstate ESTABLISHED -m connmark ! --mark 0x1000000/0x1000000 -j DROP",
+		"INPUT -p tcp -s 10.0.0.1 --sport 80 -m conntrack --ctstate ESTABLISHED -m connmark ! --mark 0x1000000/0x1000000 -j DROP",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("getTcpHangRules() = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,124 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package network
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/net"
+)
+
+func init() {
+	injector.Register(TargetNetwork, FaultTcpReset, func() injector.IInjector { return &TcpResetInjector{} })
+}
+
+// TcpResetInjector reset the established tcp connections matched by the filter,
+// and reset the new connections until recover if "new" is provided
+type TcpResetInjector struct {
+	injector.BaseInjector
+	Args    TcpResetArgs
+	Runtime TcpResetRuntime
+}
+
+type TcpResetArgs struct {
+	IpPortArgs
+	New bool `json:"new,omitempty"`
+}
+
+type TcpResetRuntime struct {
+	Rules []string `json:"rules,omitempty"`
+}
+
+func (i *TcpResetInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *TcpResetInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *TcpResetInjector) SetOption(cmd *cobra.Command) {
+	setIpPortOption(cmd, &i.Args.IpPortArgs)
+	cmd.Flags().BoolVarP(&i.Args.New, "new", "n", false, "also reset the new connections until recover")
+}
+
+func (i *TcpResetInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if i.Args.IpPortArgs.isEmpty() {
+		return fmt.Errorf("must provide at least one filter condition of: src-ip、dst-ip、src-port、dst-port")
+	}
+
+	if err := i.Args.IpPortArgs.validate(); err != nil {
+		return err
+	}
+
+	if !cmdexec.SupportCmd("ss") {
+		return fmt.Errorf("not support command \"ss\"")
+	}
+
+	if i.Args.New && !cmdexec.SupportCmd("iptables") {
+		return fmt.Errorf("not support command \"iptables\"")
+	}
+
+	return nil
+}
+
+func (i *TcpResetInjector) Inject(ctx context.Context) error {
+	filter := i.Args.IpPortArgs.getFilter()
+	if i.Args.New {
+		argsList, err := net.GetTcpMatchArgsList(filter, false)
+		if err != nil {
+			return fmt.Errorf("get iptables match args error: %s", err.Error())
+		}
+
+		var rules []string
+		for _, args := range argsList {
+			rules = append(rules, fmt.Sprintf("%s %s -j REJECT --reject-with tcp-reset", net.ChainOutput, args))
+		}
+
+		if err := net.AddIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, rules); err != nil {
+			return fmt.Errorf("add reset rules error: %s", err.Error())
+		}
+		i.Runtime.Rules = rules
+	}
+
+	re, err := net.KillTcpConnections(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, filter)
+	if err != nil {
+		if err := net.DeleteIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Runtime.Rules); err != nil {
+			log.GetLogger(ctx).Warnf("undo reset rules error: %s", err.Error())
+		}
+		return fmt.Errorf("reset established connections error: %s", err.Error())
+	}
+	log.GetLogger(ctx).Debugf("reset connections: %s", re)
+
+	return nil
+}
+
+func (i *TcpResetInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return net.DeleteIptablesRules(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Runtime.Rules)
+}
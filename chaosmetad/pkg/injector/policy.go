@@ -0,0 +1,121 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/policy"
+	"os"
+	"strings"
+)
+
+// Resource describes the resources affected by an experiment.
+// Cores are the cpu numbers of host, the others are in the scope of target, Devices are the "major:minor" of block devices
+type Resource struct {
+	CpuPercent  float64
+	MemPercent  float64
+	DiskPercent float64
+	Cores       []int
+	Pids        []int
+	ProcessKeys []string
+	Paths       []string
+	Interfaces  []string
+	Devices     []string
+}
+
+// IResource is implemented by the injectors which can describe the resources they affect
+type IResource interface {
+	GetResource(ctx context.Context) (*Resource, error)
+}
+
+func checkPolicy(ctx context.Context, i IInjector) error {
+	p, err := policy.Load()
+	if err != nil {
+		return err
+	}
+
+	r, ok := i.(IResource)
+	if p == nil || !ok {
+		return nil
+	}
+
+	res, err := r.GetResource(ctx)
+	if err != nil {
+		return fmt.Errorf("get resource of experiment error: %s", err.Error())
+	}
+
+	if res == nil {
+		return nil
+	}
+
+	if err := checkPercentPolicy(p, res); err != nil {
+		return err
+	}
+
+	// pids, paths and interfaces in policy are resources of host
+	if i.GetInfo().ContainerRuntime != "" {
+		return nil
+	}
+
+	for _, pid := range res.Pids {
+		if err := p.CheckPid(pid, getCmdline(pid)); err != nil {
+			return err
+		}
+	}
+
+	for _, key := range res.ProcessKeys {
+		if err := p.CheckProcessKey(key); err != nil {
+			return err
+		}
+	}
+
+	for _, path := range res.Paths {
+		if err := p.CheckPath(path); err != nil {
+			return err
+		}
+	}
+
+	for _, netInterface := range res.Interfaces {
+		if err := p.CheckInterface(netInterface); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func checkPercentPolicy(p *policy.Policy, res *Resource) error {
+	if err := p.CheckPercent(policy.ResourceCpu, res.CpuPercent); err != nil {
+		return err
+	}
+
+	if err := p.CheckPercent(policy.ResourceMem, res.MemPercent); err != nil {
+		return err
+	}
+
+	return p.CheckPercent(policy.ResourceDisk, res.DiskPercent)
+}
+
+func getCmdline(pid int) string {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cmdline", pid))
+	if err != nil {
+		return ""
+	}
+
+	return strings.TrimSpace(strings.ReplaceAll(string(content), "\x00", " "))
+}
@@ -23,5 +23,12 @@ const (
 
 	FaultProcessStop = "stop"
 
+	FaultProcessPriority = "priority"
+	IoClassRealtime      = "realtime"
+	IoClassBestEffort    = "best-effort"
+	IoClassIdle          = "idle"
+
+	FaultProcessCpuset = "cpuset"
+
 	//ProcessExec = "chaosmeta_process"
 )
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"os"
+	"strings"
+)
+
+const onlineCpuFile = "/sys/devices/system/cpu/online"
+
+func init() {
+	injector.Register(TargetProcess, FaultProcessCpuset, func() injector.IInjector { return &CpusetInjector{} })
+}
+
+// CpusetInjector restrict every thread of the target processes to the cpu list by sched_setaffinity.
+// the cpus outside of the cpuset cgroup of target are ignored by the kernel
+type CpusetInjector struct {
+	injector.BaseInjector
+	Args    CpusetArgs
+	Runtime CpusetRuntime
+}
+
+type CpusetArgs struct {
+	Pid     int    `json:"pid,omitempty"`
+	Key     string `json:"key,omitempty"`
+	CpuList string `json:"cpu_list"`
+}
+
+type CpusetRuntime struct {
+	OldCpuList map[int][]int `json:"old_cpu_list,omitempty"`
+}
+
+func (i *CpusetInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *CpusetInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *CpusetInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&i.Args.Pid, "pid", "p", 0, "target process's pid")
+	cmd.Flags().StringVarP(&i.Args.Key, "key", "k", "", "the key used to grep to get target process, the effect is equivalent to \"ps -ef | grep [key]\". if \"pid\" provided, \"key\" will be ignored")
+	cmd.Flags().StringVarP(&i.Args.CpuList, "cpu-list", "c", "", "cpu list to run the target process, eg: 0-1,3")
+}
+
+func (i *CpusetInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if _, err := process.GetPidListByPidOrKeyInContainer(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Pid, i.Args.Key); err != nil {
+		return fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	if i.Args.CpuList == "" {
+		return fmt.Errorf("\"cpu-list\" must provide")
+	}
+
+	cpuList, err := utils.GetNumArrByList(i.Args.CpuList)
+	if err != nil {
+		return fmt.Errorf("\"cpu-list\"[%s] is invalid: %s", i.Args.CpuList, err.Error())
+	}
+
+	onlineBytes, err := os.ReadFile(onlineCpuFile)
+	if err != nil {
+		return fmt.Errorf("read online cpus error: %s", err.Error())
+	}
+
+	onlineList, err := utils.GetNumArrByList(strings.TrimSpace(string(onlineBytes)))
+	if err != nil {
+		return fmt.Errorf("format online cpus error: %s", err.Error())
+	}
+
+	onlineMap := make(map[int]bool)
+	for _, cpu := range onlineList {
+		onlineMap[cpu] = true
+	}
+
+	for _, cpu := range cpuList {
+		if !onlineMap[cpu] {
+			return fmt.Errorf("cpu[%d] of \"cpu-list\" is not online, online cpus: %s", cpu, strings.TrimSpace(string(onlineBytes)))
+		}
+	}
+
+	return nil
+}
+
+func (i *CpusetInjector) Inject(ctx context.Context) error {
+	tidList, err := getTargetThreadList(ctx, &i.Info, i.Args.Pid, i.Args.Key)
+	if err != nil {
+		return err
+	}
+
+	cpuList, _ := utils.GetNumArrByList(i.Args.CpuList)
+	i.Runtime.OldCpuList = make(map[int][]int)
+	for _, tid := range tidList {
+		old, err := process.GetAffinity(tid)
+		if err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("get affinity of thread[%d] error: %s", tid, err.Error()))
+		}
+
+		i.Runtime.OldCpuList[tid] = old
+		if err := process.SetAffinity(tid, cpuList); err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("set affinity of thread[%d] error: %s", tid, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+func (i *CpusetInjector) getErrWithUndo(ctx context.Context, msg string) error {
+	if err := i.restore(ctx); err != nil {
+		log.GetLogger(ctx).Warnf("undo error: %s", err.Error())
+	}
+
+	return fmt.Errorf(msg)
+}
+
+// restore ignore the threads which have exited
+func (i *CpusetInjector) restore(ctx context.Context) error {
+	for tid, old := range i.Runtime.OldCpuList {
+		if exist, _ := process.ExistPid(ctx, tid); !exist {
+			continue
+		}
+
+		if err := process.SetAffinity(tid, old); err != nil {
+			return fmt.Errorf("recover affinity of thread[%d] error: %s", tid, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *CpusetInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return i.restore(ctx)
+}
+
+func (i *CpusetInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return getTargetResource(ctx, &i.Info, i.Args.Pid, i.Args.Key)
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"github.com/spf13/cobra"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+)
+
+func init() {
+	injector.Register(TargetProcess, FaultProcessPriority, func() injector.IInjector { return &PriorityInjector{} })
+}
+
+// PriorityInjector change the nice and the io scheduling class of every thread of the target processes
+type PriorityInjector struct {
+	injector.BaseInjector
+	Args    PriorityArgs
+	Runtime PriorityRuntime
+}
+
+type PriorityArgs struct {
+	Pid     int    `json:"pid,omitempty"`
+	Key     string `json:"key,omitempty"`
+	Nice    *int   `json:"nice,omitempty"`
+	IoClass string `json:"io_class,omitempty"`
+	IoLevel int    `json:"io_level,omitempty"`
+}
+
+type PriorityRuntime struct {
+	OldPriority map[int]*process.Priority `json:"old_priority,omitempty"`
+}
+
+var ioClassMap = map[string]int{
+	IoClassRealtime:   process.IoClassRealtime,
+	IoClassBestEffort: process.IoClassBestEffort,
+	IoClassIdle:       process.IoClassIdle,
+}
+
+func (i *PriorityInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *PriorityInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *PriorityInjector) SetOption(cmd *cobra.Command) {
+	cmd.Flags().IntVarP(&i.Args.Pid, "pid", "p", 0, "target process's pid")
+	cmd.Flags().StringVarP(&i.Args.Key, "key", "k", "", "the key used to grep to get target process, the effect is equivalent to \"ps -ef | grep [key]\". if \"pid\" provided, \"key\" will be ignored")
+	injector.IntPtrVarP(cmd, &i.Args.Nice, "nice", "n", fmt.Sprintf("nice value to set, in [%d, %d], not provided means not change", process.MinNice, process.MaxNice))
+	cmd.Flags().StringVarP(&i.Args.IoClass, "io-class", "c", "", fmt.Sprintf("io scheduling class to set, support: %s、%s、%s, empty means not change", IoClassRealtime, IoClassBestEffort, IoClassIdle))
+	injector.SetFlagEnum(cmd, "io-class", IoClassRealtime, IoClassBestEffort, IoClassIdle)
+	cmd.Flags().IntVarP(&i.Args.IoLevel, "io-level", "l", 0, fmt.Sprintf("io priority level of class %s and %s, in [0, %d], 0 is the highest", IoClassRealtime, IoClassBestEffort, process.MaxIoLevel))
+}
+
+func (i *PriorityInjector) Validator(ctx context.Context) error {
+	if err := i.BaseInjector.Validator(ctx); err != nil {
+		return err
+	}
+
+	if _, err := process.GetPidListByPidOrKeyInContainer(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Pid, i.Args.Key); err != nil {
+		return fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	if i.Args.Nice == nil && i.Args.IoClass == "" {
+		return fmt.Errorf("must provide at least one args of: nice、io-class")
+	}
+
+	if i.Args.Nice != nil && (*i.Args.Nice < process.MinNice || *i.Args.Nice > process.MaxNice) {
+		return fmt.Errorf("\"nice\"[%d] must be in [%d, %d]", *i.Args.Nice, process.MinNice, process.MaxNice)
+	}
+
+	if i.Args.IoClass != "" {
+		if _, ok := ioClassMap[i.Args.IoClass]; !ok {
+			return fmt.Errorf("\"io-class\" is not support: %s, only support: %s、%s、%s", i.Args.IoClass, IoClassRealtime, IoClassBestEffort, IoClassIdle)
+		}
+	}
+
+	if i.Args.IoLevel < 0 || i.Args.IoLevel > process.MaxIoLevel {
+		return fmt.Errorf("\"io-level\"[%d] must be in [0, %d]", i.Args.IoLevel, process.MaxIoLevel)
+	}
+
+	return nil
+}
+
+func (i *PriorityInjector) Inject(ctx context.Context) error {
+	tidList, err := getTargetThreadList(ctx, &i.Info, i.Args.Pid, i.Args.Key)
+	if err != nil {
+		return err
+	}
+
+	i.Runtime.OldPriority = make(map[int]*process.Priority)
+	for _, tid := range tidList {
+		old, err := process.GetPriority(tid)
+		if err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("get priority of thread[%d] error: %s", tid, err.Error()))
+		}
+
+		p := *old
+		if i.Args.Nice != nil {
+			p.Nice = *i.Args.Nice
+		}
+
+		if i.Args.IoClass != "" {
+			p.IoClass, p.IoLevel = ioClassMap[i.Args.IoClass], i.Args.IoLevel
+			if i.Args.IoClass == IoClassIdle {
+				p.IoLevel = 0
+			}
+		}
+
+		i.Runtime.OldPriority[tid] = old
+		if err := i.setPriority(tid, &p); err != nil {
+			return i.getErrWithUndo(ctx, fmt.Sprintf("set priority of thread[%d] error: %s", tid, err.Error()))
+		}
+	}
+
+	return nil
+}
+
+// setPriority only set the provided items, so that the io priority derived from nice is not fixed by a nice-only experiment
+func (i *PriorityInjector) setPriority(tid int, p *process.Priority) error {
+	if i.Args.Nice != nil {
+		if err := process.SetNice(tid, p.Nice); err != nil {
+			return fmt.Errorf("set nice error: %s", err.Error())
+		}
+	}
+
+	if i.Args.IoClass != "" {
+		if err := process.SetIoPriority(tid, p.IoClass, p.IoLevel); err != nil {
+			return fmt.Errorf("set ioprio error: %s", err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *PriorityInjector) getErrWithUndo(ctx context.Context, msg string) error {
+	if err := i.restore(ctx); err != nil {
+		log.GetLogger(ctx).Warnf("undo error: %s", err.Error())
+	}
+
+	return fmt.Errorf(msg)
+}
+
+// restore ignore the threads which have exited
+func (i *PriorityInjector) restore(ctx context.Context) error {
+	for tid, old := range i.Runtime.OldPriority {
+		if exist, _ := process.ExistPid(ctx, tid); !exist {
+			continue
+		}
+
+		if err := i.setPriority(tid, old); err != nil {
+			return fmt.Errorf("recover priority of thread[%d] error: %s", tid, err.Error())
+		}
+	}
+
+	return nil
+}
+
+func (i *PriorityInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	return i.restore(ctx)
+}
+
+func (i *PriorityInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	return getTargetResource(ctx, &i.Info, i.Args.Pid, i.Args.Key)
+}
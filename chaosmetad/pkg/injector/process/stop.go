@@ -108,3 +108,17 @@ func (i *StopInjector) Recover(ctx context.Context) error {
 
 	return nil
 }
+
+func (i *StopInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	pidList, err := process.GetPidListByPidOrKeyInContainer(ctx, i.Info.ContainerRuntime, i.Info.ContainerId, i.Args.Pid, i.Args.Key)
+	if err != nil {
+		return nil, fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	r := &injector.Resource{Pids: pidList}
+	if i.Args.Pid <= 0 {
+		r.ProcessKeys = []string{i.Args.Key}
+	}
+
+	return r, nil
+}
@@ -0,0 +1,63 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+)
+
+// getTargetThreadList return the thread ids of the target processes in the pid namespace of host.
+// the scheduling attributes are per thread, the threads created after inject inherit from their creator and are not recovered
+func getTargetThreadList(ctx context.Context, info *injector.BaseInfo, pid int, key string) ([]int, error) {
+	pidList, err := process.GetPidListByPidOrKeyInContainer(ctx, info.ContainerRuntime, info.ContainerId, pid, key)
+	if err != nil {
+		return nil, fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	hostPidList, err := process.GetHostPidList(ctx, info.ContainerRuntime, info.ContainerId, pidList)
+	if err != nil {
+		return nil, fmt.Errorf("get host pid of %v error: %s", pidList, err.Error())
+	}
+
+	var tidList []int
+	for _, hostPid := range hostPidList {
+		tids, err := process.GetThreadList(hostPid)
+		if err != nil {
+			return nil, fmt.Errorf("get thread list of process[%d] error: %s", hostPid, err.Error())
+		}
+		tidList = append(tidList, tids...)
+	}
+
+	return tidList, nil
+}
+
+func getTargetResource(ctx context.Context, info *injector.BaseInfo, pid int, key string) (*injector.Resource, error) {
+	pidList, err := process.GetPidListByPidOrKeyInContainer(ctx, info.ContainerRuntime, info.ContainerId, pid, key)
+	if err != nil {
+		return nil, fmt.Errorf("get target process error: %s", err.Error())
+	}
+
+	r := &injector.Resource{Pids: pidList}
+	if pid <= 0 {
+		r.ProcessKeys = []string{key}
+	}
+
+	return r, nil
+}
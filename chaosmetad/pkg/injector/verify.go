@@ -0,0 +1,242 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"sort"
+	"strings"
+)
+
+// VerifyScope is what an experiment may change, it is snapshotted before inject and compared after recover
+type VerifyScope struct {
+	// Interfaces the tc qdiscs of the interfaces
+	Interfaces []string
+	// Files the content of the files, eg: /etc/hosts
+	Files []string
+	// Perms the permission of the paths
+	Perms []string
+	// Sysctls the kernel parameters, eg: fs.file-max
+	Sysctls []string
+	// Pids the cgroups of the processes
+	Pids []int
+}
+
+// IVerifyScope is implemented by the injectors which change more than the Resource they affect.
+// the interfaces, paths and pids of Resource are always in the scope
+type IVerifyScope interface {
+	GetVerifyScope(ctx context.Context) (*VerifyScope, error)
+}
+
+// Snapshot is the state of a VerifyScope, the values are the output of commands which are compared as strings
+type Snapshot struct {
+	Qdiscs  map[string]string `json:"qdiscs,omitempty"`
+	Files   map[string]string `json:"files,omitempty"`
+	Perms   map[string]string `json:"perms,omitempty"`
+	Sysctls map[string]string `json:"sysctls,omitempty"`
+	Cgroups map[int]string    `json:"cgroups,omitempty"`
+}
+
+func (s *Snapshot) isEmpty() bool {
+	return len(s.Qdiscs) == 0 && len(s.Files) == 0 && len(s.Perms) == 0 && len(s.Sysctls) == 0 && len(s.Cgroups) == 0
+}
+
+// scope return the scope of the snapshot, so that the same items can be snapshotted again
+func (s *Snapshot) scope() *VerifyScope {
+	re := &VerifyScope{
+		Interfaces: sortedKeys(s.Qdiscs),
+		Files:      sortedKeys(s.Files),
+		Perms:      sortedKeys(s.Perms),
+		Sysctls:    sortedKeys(s.Sysctls),
+	}
+
+	for pid := range s.Cgroups {
+		re.Pids = append(re.Pids, pid)
+	}
+	sort.Ints(re.Pids)
+
+	return re
+}
+
+// Diff return the items of s which are not the same in now.
+// the cgroup of a process which does not exist any more is ignored, eg: the process is killed by experiment
+func (s *Snapshot) Diff(now *Snapshot) []string {
+	var drifts []string
+	for _, item := range []struct {
+		name       string
+		before, in map[string]string
+		hideValue  bool
+	}{
+		{name: "qdisc of interface", before: s.Qdiscs, in: now.Qdiscs},
+		{name: "content of file", before: s.Files, in: now.Files, hideValue: true},
+		{name: "permission of path", before: s.Perms, in: now.Perms},
+		{name: "sysctl", before: s.Sysctls, in: now.Sysctls},
+	} {
+		for _, k := range sortedKeys(item.before) {
+			if item.before[k] == item.in[k] {
+				continue
+			}
+
+			if item.hideValue {
+				drifts = append(drifts, fmt.Sprintf("%s[%s] changed", item.name, k))
+			} else {
+				drifts = append(drifts, fmt.Sprintf("%s[%s] changed from %q to %q", item.name, k, item.before[k], item.in[k]))
+			}
+		}
+	}
+
+	for _, pid := range s.scope().Pids {
+		if cg := now.Cgroups[pid]; cg != "" && cg != s.Cgroups[pid] {
+			drifts = append(drifts, fmt.Sprintf("cgroup of process[%d] changed from %q to %q", pid, s.Cgroups[pid], cg))
+		}
+	}
+
+	return drifts
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+func getVerifyScope(ctx context.Context, i IInjector) (*VerifyScope, error) {
+	scope := &VerifyScope{}
+	if r, ok := i.(IResource); ok {
+		res, err := r.GetResource(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get resource error: %s", err.Error())
+		}
+
+		if res != nil {
+			scope.Interfaces, scope.Perms, scope.Pids = res.Interfaces, res.Paths, res.Pids
+		}
+	}
+
+	if v, ok := i.(IVerifyScope); ok {
+		s, err := v.GetVerifyScope(ctx)
+		if err != nil {
+			return nil, fmt.Errorf("get verify scope error: %s", err.Error())
+		}
+
+		if s != nil {
+			scope.Interfaces = append(scope.Interfaces, s.Interfaces...)
+			scope.Files = append(scope.Files, s.Files...)
+			scope.Perms = append(scope.Perms, s.Perms...)
+			scope.Sysctls = append(scope.Sysctls, s.Sysctls...)
+			scope.Pids = append(scope.Pids, s.Pids...)
+		}
+	}
+
+	return scope, nil
+}
+
+// TakeSnapshot snapshot the scope in the container if cr is not empty
+func TakeSnapshot(ctx context.Context, cr, cId string, scope *VerifyScope) (*Snapshot, error) {
+	s := &Snapshot{
+		Qdiscs:  make(map[string]string),
+		Files:   make(map[string]string),
+		Perms:   make(map[string]string),
+		Sysctls: make(map[string]string),
+		Cgroups: make(map[int]string),
+	}
+
+	for _, item := range []struct {
+		keys []string
+		re   map[string]string
+		ns   string
+		cmd  func(key string) string
+	}{
+		{scope.Interfaces, s.Qdiscs, namespace.NET, getQdiscCmd},
+		{scope.Files, s.Files, namespace.MNT, getFileHashCmd},
+		{scope.Perms, s.Perms, namespace.MNT, getPathPermCmd},
+		{scope.Sysctls, s.Sysctls, namespace.NET, getSysctlCmd},
+	} {
+		for _, key := range item.keys {
+			out, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, item.cmd(key), []string{item.ns})
+			if err != nil {
+				return nil, fmt.Errorf("snapshot %s error: %s", key, err.Error())
+			}
+			item.re[key] = strings.TrimSpace(out)
+		}
+	}
+
+	for _, pid := range scope.Pids {
+		out, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, getCgroupCmd(pid), []string{namespace.MNT})
+		if err != nil {
+			return nil, fmt.Errorf("snapshot cgroup of process[%d] error: %s", pid, err.Error())
+		}
+		s.Cgroups[pid] = strings.TrimSpace(out)
+	}
+
+	return s, nil
+}
+
+// the commands do not fail when the target does not exist, so that "not exist" is a state can be compared
+func getQdiscCmd(dev string) string {
+	return fmt.Sprintf("tc qdisc show dev %s 2>&1 || true", dev)
+}
+
+func getFileHashCmd(file string) string {
+	return fmt.Sprintf("sha256sum %s 2>/dev/null | cut -d ' ' -f 1", file)
+}
+
+func getPathPermCmd(path string) string {
+	return fmt.Sprintf("stat -c '%%a' %s 2>/dev/null || true", path)
+}
+
+func getSysctlCmd(key string) string {
+	return fmt.Sprintf("cat /proc/sys/%s 2>/dev/null || true", strings.ReplaceAll(key, ".", "/"))
+}
+
+func getCgroupCmd(pid int) string {
+	return fmt.Sprintf("cat /proc/%d/cgroup 2>/dev/null || true", pid)
+}
+
+// takeSnapshot save the snapshot of the verify scope in the info of experiment, nothing is saved if the scope is empty
+func takeSnapshot(ctx context.Context, i IInjector) error {
+	scope, err := getVerifyScope(ctx, i)
+	if err != nil {
+		return err
+	}
+
+	info := i.GetInfo()
+	s, err := TakeSnapshot(ctx, info.ContainerRuntime, info.ContainerId, scope)
+	if err != nil {
+		return err
+	}
+
+	if s.isEmpty() {
+		return nil
+	}
+
+	sBytes, err := json.Marshal(s)
+	if err != nil {
+		return fmt.Errorf("snapshot convert to string error: %s", err.Error())
+	}
+
+	info.Snapshot = string(sBytes)
+	return nil
+}
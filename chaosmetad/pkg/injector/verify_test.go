@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestSnapshotDiff(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "verify.txt")
+	if err := os.WriteFile(file, []byte("before"), 0644); err != nil {
+		t.Fatalf("create file error: %s", err.Error())
+	}
+
+	scope := &VerifyScope{Files: []string{file}, Perms: []string{file}, Sysctls: []string{"kernel.ostype"}, Pids: []int{os.Getpid()}}
+	before, err := TakeSnapshot(ctx, "", "", scope)
+	if err != nil {
+		t.Fatalf("take snapshot error: %s", err.Error())
+	}
+
+	if before.Perms[file] != "644" || before.Sysctls["kernel.ostype"] != "Linux" || before.Cgroups[os.Getpid()] == "" {
+		t.Fatalf("unexpected snapshot: %#v", before)
+	}
+
+	now, _ := TakeSnapshot(ctx, "", "", before.scope())
+	if drifts := before.Diff(now); len(drifts) != 0 {
+		t.Errorf("expected no drift, get: %v", drifts)
+	}
+
+	if err := os.WriteFile(file, []byte("after"), 0600); err != nil {
+		t.Fatalf("write file error: %s", err.Error())
+	}
+	_ = os.Chmod(file, 0600)
+
+	now, _ = TakeSnapshot(ctx, "", "", before.scope())
+	expected := []string{"content of file[" + file + "] changed", "permission of path[" + file + "] changed from \"644\" to \"600\""}
+	if drifts := before.Diff(now); !reflect.DeepEqual(drifts, expected) {
+		t.Errorf("expected drifts: %v, get: %v", expected, drifts)
+	}
+}
+
+func TestSnapshotDiff_ExitedProcess(t *testing.T) {
+	before := &Snapshot{Cgroups: map[int]string{1: "0::/a", 2: "0::/a"}}
+	now := &Snapshot{Cgroups: map[int]string{1: "0::/b", 2: ""}}
+	expected := []string{"cgroup of process[1] changed from \"0::/a\" to \"0::/b\""}
+	if drifts := before.Diff(now); !reflect.DeepEqual(drifts, expected) {
+		t.Errorf("expected drifts: %v, get: %v", expected, drifts)
+	}
+}
+
+type verifyTestInjector struct {
+	BaseInjector
+	Args         struct{}
+	Runtime      struct{}
+	recoverCount *int
+}
+
+func (i *verifyTestInjector) GetArgs() interface{} {
+	return &i.Args
+}
+
+func (i *verifyTestInjector) GetRuntime() interface{} {
+	return &i.Runtime
+}
+
+func (i *verifyTestInjector) Recover(ctx context.Context) error {
+	if i.BaseInjector.Recover(ctx) == nil {
+		return nil
+	}
+
+	*i.recoverCount++
+	return nil
+}
+
+func TestProcessRecover_VerifyRetry(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "verify.txt")
+	if err := os.WriteFile(file, []byte("before"), 0644); err != nil {
+		t.Fatalf("create file error: %s", err.Error())
+	}
+
+	snapshot, err := TakeSnapshot(ctx, "", "", &VerifyScope{Files: []string{file}})
+	if err != nil {
+		t.Fatalf("take snapshot error: %s", err.Error())
+	}
+	snapshotBytes, _ := json.Marshal(snapshot)
+
+	var recoverCount int
+	Register("verifytest", "fault", func() IInjector { return &verifyTestInjector{recoverCount: &recoverCount} })
+	defer delete(constructorScheme, getInjectorKey("verifytest", "fault"))
+
+	db := storage.NewMemoryStore()
+	storage.SetExperimentStore(db)
+	defer storage.SetExperimentStore(nil)
+
+	uid := "verifytest"
+	if err := db.Insert(&storage.Experiment{Uid: uid, Target: "verifytest", Fault: "fault", Args: "{}", Runtime: "{}",
+		Status: utils.StatusSuccess, Snapshot: string(snapshotBytes)}); err != nil {
+		t.Fatalf("insert experiment error: %s", err.Error())
+	}
+
+	// the file is not restored by recover, so verify failed
+	if err := os.WriteFile(file, []byte("after"), 0644); err != nil {
+		t.Fatalf("write file error: %s", err.Error())
+	}
+
+	if code, msg := ProcessRecover(ctx, uid); code != errutil.RecoverErr {
+		t.Fatalf("expect recover error, get code %d: %s", code, msg)
+	}
+
+	if exp, _ := db.GetByUid(uid); exp.Status != utils.StatusRecoverVerifyFailed || exp.Drift == "" {
+		t.Fatalf("expect status %s with drift, get status %s and drift %q", utils.StatusRecoverVerifyFailed, exp.Status, exp.Drift)
+	}
+
+	// retry after the file is fixed, only verify is run again
+	if err := os.WriteFile(file, []byte("before"), 0644); err != nil {
+		t.Fatalf("write file error: %s", err.Error())
+	}
+
+	if code, msg := ProcessRecover(ctx, uid); code != errutil.NoErr {
+		t.Fatalf("expect recover success, get code %d: %s", code, msg)
+	}
+
+	if exp, _ := db.GetByUid(uid); exp.Status != utils.StatusDestroyed {
+		t.Fatalf("expect status %s, get %s", utils.StatusDestroyed, exp.Status)
+	}
+
+	if recoverCount != 1 {
+		t.Fatalf("expect recover to be run once, get %d", recoverCount)
+	}
+}
+
+func TestProcessRecover_Destroyed(t *testing.T) {
+	ctx := context.Background()
+	file := filepath.Join(t.TempDir(), "verify.txt")
+	if err := os.WriteFile(file, []byte("before"), 0644); err != nil {
+		t.Fatalf("create file error: %s", err.Error())
+	}
+
+	snapshot, err := TakeSnapshot(ctx, "", "", &VerifyScope{Files: []string{file}})
+	if err != nil {
+		t.Fatalf("take snapshot error: %s", err.Error())
+	}
+	snapshotBytes, _ := json.Marshal(snapshot)
+
+	var recoverCount int
+	Register("verifytest", "fault", func() IInjector { return &verifyTestInjector{recoverCount: &recoverCount} })
+	defer delete(constructorScheme, getInjectorKey("verifytest", "fault"))
+
+	db := storage.NewMemoryStore()
+	storage.SetExperimentStore(db)
+	defer storage.SetExperimentStore(nil)
+
+	uid := "verifytest"
+	if err := db.Insert(&storage.Experiment{Uid: uid, Target: "verifytest", Fault: "fault", Args: "{}", Runtime: "{}",
+		Status: utils.StatusDestroyed, Snapshot: string(snapshotBytes)}); err != nil {
+		t.Fatalf("insert experiment error: %s", err.Error())
+	}
+
+	// the file is changed after the experiment is destroyed, which is not drift of the experiment
+	if err := os.WriteFile(file, []byte("after"), 0644); err != nil {
+		t.Fatalf("write file error: %s", err.Error())
+	}
+
+	for n := 0; n < 2; n++ {
+		if code, msg := ProcessRecover(ctx, uid); code != errutil.NoErr {
+			t.Fatalf("expect recover success, get code %d: %s", code, msg)
+		}
+
+		if exp, _ := db.GetByUid(uid); exp.Status != utils.StatusDestroyed {
+			t.Fatalf("expect status %s, get %s", utils.StatusDestroyed, exp.Status)
+		}
+	}
+
+	if recoverCount != 0 {
+		t.Fatalf("expect recover not to be run, get %d", recoverCount)
+	}
+}
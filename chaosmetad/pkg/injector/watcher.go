@@ -0,0 +1,139 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package injector
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/event"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"time"
+)
+
+// IProgress is implemented by injectors which can report the progress of a running experiment, eg: mem usage of "mem fill"
+type IProgress interface {
+	GetProgress(ctx context.Context) (string, error)
+}
+
+// WatchExperiment publish status changes made by other processes(eg: "chaosmetad recover" started by timeout)
+// and the progress of running experiments to the event bus
+func WatchExperiment(ctx context.Context, interval time.Duration) {
+	var (
+		logger       = log.GetLogger(ctx)
+		bus          = event.GetBus()
+		since        = time.Now().Format(utils.TimeFormat)
+		lastProgress = make(map[string]string)
+		ticker       = time.NewTicker(interval)
+	)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		db, err := storage.GetExperimentStore()
+		if err != nil {
+			logger.Warnf("watch experiment: connect db error: %s", err.Error())
+			continue
+		}
+
+		// use ">=" and the time before query, so no update will be missed, repeated status is dropped by the bus
+		now := time.Now().Format(utils.TimeFormat)
+		exps, err := db.QueryByUpdateTime(since)
+		if err != nil {
+			logger.Warnf("watch experiment: query updated experiments error: %s", err.Error())
+			continue
+		}
+		since = now
+
+		for _, exp := range exps {
+			bus.PublishStatus(exp.Uid, exp.Target, exp.Fault, exp.Status, exp.Error)
+		}
+
+		if !bus.HasSubscriber() {
+			continue
+		}
+
+		running, err := db.QueryByStatus(utils.StatusSuccess)
+		if err != nil {
+			logger.Warnf("watch experiment: query running experiments error: %s", err.Error())
+			continue
+		}
+
+		runningSet := make(map[string]bool)
+		for _, exp := range running {
+			runningSet[exp.Uid] = true
+			progress, err := getProgress(ctx, exp)
+			if err != nil {
+				logger.Debugf("watch experiment: get progress of experiment[%s] error: %s", exp.Uid, err.Error())
+				continue
+			}
+
+			if progress == "" || progress == lastProgress[exp.Uid] {
+				continue
+			}
+
+			lastProgress[exp.Uid] = progress
+			bus.Publish(&event.Event{
+				Type:    event.TypeProgress,
+				Uid:     exp.Uid,
+				Target:  exp.Target,
+				Fault:   exp.Fault,
+				Status:  exp.Status,
+				Message: progress,
+			})
+		}
+
+		for uid := range lastProgress {
+			if !runningSet[uid] {
+				delete(lastProgress, uid)
+			}
+		}
+	}
+}
+
+func getProgress(ctx context.Context, exp *storage.Experiment) (string, error) {
+	i, err := newInjectorByExp(exp)
+	if err != nil {
+		return "", err
+	}
+
+	p, ok := i.(IProgress)
+	if !ok {
+		return "", nil
+	}
+
+	return p.GetProgress(ctx)
+}
+
+func newInjectorByExp(exp *storage.Experiment) (IInjector, error) {
+	i, err := NewInjector(exp.Target, exp.Fault)
+	if err != nil {
+		return nil, fmt.Errorf("find injector by target[%s] and fault[%s] error: %s", exp.Target, exp.Fault, err.Error())
+	}
+
+	if err := i.LoadInjector(exp, i.GetArgs(), i.GetRuntime()); err != nil {
+		return nil, fmt.Errorf("load experiment to injector error: %s", err.Error())
+	}
+
+	return i, nil
+}
@@ -29,6 +29,7 @@ import (
 var (
 	Level  string
 	Path   string
+	Stderr bool
 	logger *logrus.Logger
 	mutex  sync.Mutex
 )
@@ -59,6 +60,13 @@ func GetLogger(ctx context.Context) *logrus.Entry {
 	return logger.WithFields(logrus.Fields{})
 }
 
+// SetLogger replace the default logger, which is created by Level, Path and Stderr
+func SetLogger(l *logrus.Logger) {
+	mutex.Lock()
+	defer mutex.Unlock()
+	logger = l
+}
+
 //func WithUid(uid string) *logrus.Entry {
 //	return GetLogger().WithFields(logrus.Fields{
 //		"uid": uid,
@@ -79,7 +87,11 @@ func setLogger() {
 	})
 	logger.SetLevel(getLogLevel(Level))
 	if Path == "" {
-		logger.SetOutput(os.Stdout)
+		if Stderr {
+			logger.SetOutput(os.Stderr)
+		} else {
+			logger.SetOutput(os.Stdout)
+		}
 	} else {
 		f, err := getLogPathFile()
 		if err != nil {
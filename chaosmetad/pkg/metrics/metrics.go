@@ -0,0 +1,77 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"strconv"
+	"time"
+)
+
+const namespace = "chaosmetad"
+
+var (
+	injectTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "inject_total",
+		Help:      "Count of inject processes, labeled by target, fault and result code of errutil.",
+	}, []string{"target", "fault", "code"})
+
+	recoverTotal = prometheus.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "recover_total",
+		Help:      "Count of recover processes, labeled by target, fault and result code of errutil.",
+	}, []string{"target", "fault", "code"})
+
+	injectDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "inject_duration_seconds",
+		Help:      "Duration of inject processes.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"target", "fault"})
+
+	recoverDuration = prometheus.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "recover_duration_seconds",
+		Help:      "Duration of recover processes.",
+		Buckets:   []float64{0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60},
+	}, []string{"target", "fault"})
+
+	ActiveExperimentsDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "active_experiments"),
+		"Count of experiments in status success, labeled by target and fault.",
+		[]string{"target", "fault"}, nil)
+
+	InjectorRuntimeDesc = prometheus.NewDesc(
+		prometheus.BuildFQName(namespace, "", "injector_runtime"),
+		"Runtime values reported by injectors of active experiments, eg: fill_bytes, burn_cores.",
+		[]string{"uid", "target", "fault", "name"}, nil)
+)
+
+func init() {
+	prometheus.MustRegister(injectTotal, recoverTotal, injectDuration, recoverDuration)
+}
+
+func ObserveInject(target, fault string, code int, startTime time.Time) {
+	injectTotal.WithLabelValues(target, fault, strconv.Itoa(code)).Inc()
+	injectDuration.WithLabelValues(target, fault).Observe(time.Since(startTime).Seconds())
+}
+
+func ObserveRecover(target, fault string, code int, startTime time.Time) {
+	recoverTotal.WithLabelValues(target, fault, strconv.Itoa(code)).Inc()
+	recoverDuration.WithLabelValues(target, fault).Observe(time.Since(startTime).Seconds())
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package output
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/spf13/pflag"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"os"
+)
+
+const (
+	TextFormat = "text"
+	JsonFormat = "json"
+)
+
+var Format string
+
+// Result is the json document printed by the cmd, Code is the same as the exit code
+type Result struct {
+	Code    int             `json:"code"`
+	Message string          `json:"message"`
+	Uid     string          `json:"uid,omitempty"`
+	Status  string          `json:"status,omitempty"`
+	Runtime json.RawMessage `json:"runtime,omitempty"`
+	Data    interface{}     `json:"data,omitempty"`
+}
+
+// AddFlag add the "output" flag, should be called by the commands which support json output
+func AddFlag(flags *pflag.FlagSet) {
+	flags.StringVar(&Format, "output", TextFormat, fmt.Sprintf("output format, support: %s(default), %s", TextFormat, JsonFormat))
+}
+
+// Init check the format and make the logs not mix with the json document, must be called before any log is printed
+func Init(ctx context.Context) {
+	if Format == JsonFormat {
+		log.Stderr = true
+		return
+	}
+
+	if Format != TextFormat && Format != "" {
+		errutil.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("not support output format: %s", Format))
+	}
+}
+
+func IsJson() bool {
+	return Format == JsonFormat
+}
+
+// NewExpResult create a result with the status and runtime of the experiment recorded in db
+func NewExpResult(ctx context.Context, code int, msg, uid string) *Result {
+	re := &Result{
+		Code:    code,
+		Message: msg,
+		Uid:     uid,
+	}
+
+	if uid == "" || !IsJson() {
+		return re
+	}
+
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		log.GetLogger(ctx).Warnf("connect db error: %s", err.Error())
+		return re
+	}
+
+	exp, err := db.GetByUid(uid)
+	if err != nil {
+		log.GetLogger(ctx).Debugf("query experiment by uid[%s] error: %s", uid, err.Error())
+		return re
+	}
+
+	re.SetExp(exp)
+	return re
+}
+
+// SetExp fill the status and runtime by the experiment
+func (r *Result) SetExp(exp *storage.Experiment) {
+	r.Uid, r.Status = exp.Uid, exp.Status
+	if json.Valid([]byte(exp.Runtime)) {
+		r.Runtime = json.RawMessage(exp.Runtime)
+	}
+}
+
+// Solve print the result in json format and exit with the code if output format is json, otherwise solve as text
+func Solve(ctx context.Context, r *Result) {
+	if !IsJson() {
+		errutil.SolveErr(ctx, r.Code, r.Message)
+	}
+
+	reBytes, err := json.Marshal(r)
+	if err != nil {
+		reBytes, _ = json.Marshal(&Result{
+			Code:    errutil.InternalErr,
+			Message: fmt.Sprintf("result change to json error: %s", err.Error()),
+			Uid:     r.Uid,
+		})
+		r.Code = errutil.InternalErr
+	}
+
+	fmt.Println(string(reBytes))
+	os.Exit(r.Code)
+}
+
+// SolveErr is the same as errutil.SolveErr but print a json document if output format is json
+func SolveErr(ctx context.Context, code int, msg string) {
+	Solve(ctx, &Result{Code: code, Message: msg})
+}
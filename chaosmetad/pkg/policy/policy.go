@@ -0,0 +1,156 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+const (
+	policyFile = "chaosmetad_policy.json"
+
+	ResourceCpu  = "cpu"
+	ResourceMem  = "mem"
+	ResourceDisk = "disk"
+)
+
+// Path is the node-local policy file, default is "chaosmetad_policy.json" in the run path
+var Path string
+
+// Policy describes the blast-radius limits and the protected resources of the node
+type Policy struct {
+	MaxCpuPercent        int      `json:"max_cpu_percent,omitempty"`
+	MaxMemPercent        int      `json:"max_mem_percent,omitempty"`
+	MaxDiskPercent       int      `json:"max_disk_percent,omitempty"`
+	ProtectedProcessKeys []string `json:"protected_process_keys,omitempty"`
+	ProtectedPids        []int    `json:"protected_pids,omitempty"`
+	ProtectedPaths       []string `json:"protected_paths,omitempty"`
+	ProtectedInterfaces  []string `json:"protected_interfaces,omitempty"`
+}
+
+func GetPath() string {
+	if Path != "" {
+		return Path
+	}
+
+	return path.Join(utils.GetRunPath(), policyFile)
+}
+
+// Load reads the policy file, return nil if the file not exists
+func Load() (*Policy, error) {
+	policyPath := GetPath()
+	content, err := os.ReadFile(policyPath)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read policy file[%s] error: %s", policyPath, err.Error())
+	}
+
+	p := &Policy{}
+	if err := json.Unmarshal(content, p); err != nil {
+		return nil, fmt.Errorf("parse policy file[%s] error: %s", policyPath, err.Error())
+	}
+
+	return p, nil
+}
+
+// CheckPercent checks the usage percent of resource[cpu/mem/disk] after injection
+func (p *Policy) CheckPercent(resource string, percent float64) error {
+	var limit int
+	switch resource {
+	case ResourceCpu:
+		limit = p.MaxCpuPercent
+	case ResourceMem:
+		limit = p.MaxMemPercent
+	case ResourceDisk:
+		limit = p.MaxDiskPercent
+	default:
+		return fmt.Errorf("unknown resource: %s", resource)
+	}
+
+	if limit > 0 && percent > float64(limit) {
+		return fmt.Errorf("%s percent[%.2f] exceeds the limit[%d]", resource, percent, limit)
+	}
+
+	return nil
+}
+
+// CheckPid checks whether the process is protected by pid or by its cmdline
+func (p *Policy) CheckPid(pid int, cmdline string) error {
+	for _, protectedPid := range p.ProtectedPids {
+		if pid == protectedPid {
+			return fmt.Errorf("process[%d] is protected", pid)
+		}
+	}
+
+	for _, key := range p.ProtectedProcessKeys {
+		if key != "" && strings.Contains(cmdline, key) {
+			return fmt.Errorf("process[%d] matches protected process key[%s]", pid, key)
+		}
+	}
+
+	return nil
+}
+
+// CheckProcessKey checks whether the key used to grep process overlaps with the protected process keys
+func (p *Policy) CheckProcessKey(key string) error {
+	if key == "" {
+		return nil
+	}
+
+	for _, protectedKey := range p.ProtectedProcessKeys {
+		if protectedKey != "" && (strings.Contains(key, protectedKey) || strings.Contains(protectedKey, key)) {
+			return fmt.Errorf("process key[%s] matches protected process key[%s]", key, protectedKey)
+		}
+	}
+
+	return nil
+}
+
+// CheckPath checks whether the path is a protected path or under a protected dir
+func (p *Policy) CheckPath(targetPath string) error {
+	targetPath = filepath.Clean(targetPath)
+	for _, protectedPath := range p.ProtectedPaths {
+		if protectedPath == "" {
+			continue
+		}
+
+		protectedPath = filepath.Clean(protectedPath)
+		if targetPath == protectedPath || strings.HasPrefix(targetPath, strings.TrimSuffix(protectedPath, "/")+"/") {
+			return fmt.Errorf("path[%s] is protected by [%s]", targetPath, protectedPath)
+		}
+	}
+
+	return nil
+}
+
+// CheckInterface checks whether the network interface is protected
+func (p *Policy) CheckInterface(netInterface string) error {
+	if utils.StrListContain(p.ProtectedInterfaces, netInterface) {
+		return fmt.Errorf("network interface[%s] is protected", netInterface)
+	}
+
+	return nil
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package policy
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestPolicy_Check(t *testing.T) {
+	p := &Policy{
+		MaxCpuPercent:        80,
+		ProtectedProcessKeys: []string{"kubelet", "sshd"},
+		ProtectedPids:        []int{1},
+		ProtectedPaths:       []string{"/etc/kubernetes/", "/boot"},
+		ProtectedInterfaces:  []string{"eth0"},
+	}
+
+	tests := []struct {
+		name    string
+		check   func() error
+		wantErr bool
+	}{
+		{name: "cpu in limit", check: func() error { return p.CheckPercent(ResourceCpu, 80) }, wantErr: false},
+		{name: "cpu over limit", check: func() error { return p.CheckPercent(ResourceCpu, 80.5) }, wantErr: true},
+		{name: "mem no limit", check: func() error { return p.CheckPercent(ResourceMem, 100) }, wantErr: false},
+		{name: "protected pid", check: func() error { return p.CheckPid(1, "/sbin/init") }, wantErr: true},
+		{name: "protected cmdline", check: func() error { return p.CheckPid(100, "/usr/bin/kubelet --config x") }, wantErr: true},
+		{name: "normal pid", check: func() error { return p.CheckPid(100, "/usr/bin/sleep 100") }, wantErr: false},
+		{name: "protected key", check: func() error { return p.CheckProcessKey("kube") }, wantErr: true},
+		{name: "normal key", check: func() error { return p.CheckProcessKey("nginx") }, wantErr: false},
+		{name: "protected dir", check: func() error { return p.CheckPath("/etc/kubernetes") }, wantErr: true},
+		{name: "under protected dir", check: func() error { return p.CheckPath("/boot/../boot/grub/grub.cfg") }, wantErr: true},
+		{name: "similar prefix", check: func() error { return p.CheckPath("/bootstrap/a.txt") }, wantErr: false},
+		{name: "protected interface", check: func() error { return p.CheckInterface("eth0") }, wantErr: true},
+		{name: "normal interface", check: func() error { return p.CheckInterface("lo") }, wantErr: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.check(); (err != nil) != tt.wantErr {
+				t.Errorf("check error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	Path = filepath.Join(t.TempDir(), policyFile)
+	defer func() { Path = "" }()
+
+	p, err := Load()
+	if err != nil || p != nil {
+		t.Fatalf("Load() without file = %v, %v, want nil, nil", p, err)
+	}
+
+	if err := os.WriteFile(Path, []byte(`{"max_mem_percent": 90, "protected_interfaces": ["eth0"]}`), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	p, err = Load()
+	if err != nil {
+		t.Fatalf("Load() error = %v", err)
+	}
+
+	if p.MaxMemPercent != 90 || len(p.ProtectedInterfaces) != 1 {
+		t.Errorf("Load() = %+v", p)
+	}
+}
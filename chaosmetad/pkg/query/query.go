@@ -22,6 +22,7 @@ import (
 	"fmt"
 	"github.com/bndr/gotabulate"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/output"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/web/handler"
@@ -47,28 +48,30 @@ const (
 
 func PrintExpByOption(ctx context.Context, o *OptionExpQuery, ifAll bool, format string) {
 	if format != TableFormat && format != JsonFormat {
-		errutil.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("not support format: %s", format))
+		output.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("not support format: %s", format))
 	}
 
 	if o == nil {
-		errutil.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("option is empty"))
+		output.SolveErr(ctx, errutil.BadArgsErr, fmt.Sprintf("option is empty"))
 	}
 
 	temp, err := json.Marshal(o)
 	if err != nil {
-		errutil.SolveErr(ctx, errutil.BadArgsErr, err.Error())
+		output.SolveErr(ctx, errutil.BadArgsErr, err.Error())
 	}
 
 	db, dbErr := storage.GetExperimentStore()
 	if dbErr != nil {
-		errutil.SolveErr(ctx, errutil.DBErr, dbErr.Error())
+		output.SolveErr(ctx, errutil.DBErr, dbErr.Error())
 	}
 	exps, total, queryErr := db.QueryByOption(o.Uid, o.Status, o.Target, o.Fault, o.Creator, o.ContainerRuntime, o.ContainerId, o.Offset, o.Limit)
 	if queryErr != nil {
-		errutil.SolveErr(ctx, errutil.DBErr, queryErr.Error())
+		output.SolveErr(ctx, errutil.DBErr, queryErr.Error())
 	}
 
-	if format == JsonFormat {
+	if output.IsJson() {
+		printResult(ctx, o, exps, total)
+	} else if format == JsonFormat {
 		printJson(ctx, exps, total)
 	} else {
 		log.GetLogger(ctx).Infof("query args: %s", string(temp))
@@ -76,19 +79,36 @@ func PrintExpByOption(ctx context.Context, o *OptionExpQuery, ifAll bool, format
 	}
 }
 
-func printJson(ctx context.Context, exps []*storage.Experiment, total int64) {
-	logger := log.GetLogger(ctx)
+func getResponseData(exps []*storage.Experiment, total int64) *model.QueryResponseData {
 	reList := make([]model.ExperimentDataUnit, len(exps))
 	for i, exp := range exps {
 		reList[i] = handler.ExpToExperimentDataUnit(exp)
 	}
 
-	res := &model.QueryResponseData{
+	return &model.QueryResponseData{
 		Experiments: reList,
 		Total:       total,
 	}
+}
+
+// printResult print the result document of "--output json", uid, status and runtime are filled when query by uid
+func printResult(ctx context.Context, o *OptionExpQuery, exps []*storage.Experiment, total int64) {
+	re := &output.Result{
+		Code:    errutil.NoErr,
+		Message: "success",
+		Data:    getResponseData(exps, total),
+	}
 
-	reBytes, err := json.Marshal(res)
+	if o.Uid != "" && len(exps) == 1 {
+		re.SetExp(exps[0])
+	}
+
+	output.Solve(ctx, re)
+}
+
+func printJson(ctx context.Context, exps []*storage.Experiment, total int64) {
+	logger := log.GetLogger(ctx)
+	reBytes, err := json.Marshal(getResponseData(exps, total))
 	if err != nil {
 		errutil.SolveErr(ctx, errutil.InternalErr, fmt.Sprintf("query response change to string error: %s", err.Error()))
 	}
@@ -109,7 +129,7 @@ func printTable(ctx context.Context, exps []*storage.Experiment, total int64, if
 			var aData []interface{}
 			if ifAll {
 				aData = []interface{}{exp.Uid, exp.Status, exp.Target, exp.Fault, exp.Args, exp.Creator, exp.Runtime,
-					exp.ContainerId, exp.ContainerRuntime, exp.Timeout, exp.Error, exp.CreateTime, exp.UpdateTime}
+					exp.ContainerId, exp.ContainerRuntime, exp.Timeout, exp.Error, exp.Drift, exp.CreateTime, exp.UpdateTime}
 			} else {
 				aData = []interface{}{exp.Uid, exp.Status, exp.Target, exp.Fault, exp.Args}
 			}
@@ -120,7 +140,7 @@ func printTable(ctx context.Context, exps []*storage.Experiment, total int64, if
 		t := gotabulate.Create(data)
 		if ifAll {
 			t.SetHeaders([]string{"UID", "STATUS", "TARGET", "FAULT", "ARGS", "CREATOR", "RUNTIME",
-				"CONTAINER_ID", "CONTAINER_RUNTIME", "TIMEOUT", "ERROR", "CREATE_TIME", "UPDATE_TIME"})
+				"CONTAINER_ID", "CONTAINER_RUNTIME", "TIMEOUT", "ERROR", "DRIFT", "CREATE_TIME", "UPDATE_TIME"})
 		} else {
 			t.SetHeaders([]string{"UID", "STATUS", "TARGET", "FAULT", "ARGS"})
 		}
@@ -0,0 +1,263 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package sdk runs the injectors of chaosmetad in-process, without the cli or the http server.
+// Import the injector package of the target to register its faults and to get the typed args, eg:
+//
+//	exp, err := sdk.Inject(ctx, network.TargetNetwork, network.FaultDelay, &network.DelayArgs{Interface: "eth0", Latency: "100ms"})
+//	defer exp.Recover(ctx)
+package sdk
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/sirupsen/logrus"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"math"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// Config replace the global storage and logger of chaosmetad, nil fields keep the defaults
+type Config struct {
+	// Store default is the sqlite file in the run path, storage.NewMemoryStore keeps nothing on disk
+	Store  storage.ExperimentStore
+	Logger *logrus.Logger
+}
+
+// Setup should be called before any experiment is created
+func Setup(c *Config) {
+	if c == nil {
+		return
+	}
+
+	if c.Store != nil {
+		storage.SetExperimentStore(c.Store)
+	}
+
+	if c.Logger != nil {
+		log.SetLogger(c.Logger)
+	}
+}
+
+// Error is returned when the experiment fails, Code is one of the codes in errutil
+type Error struct {
+	Code    int
+	Message string
+	// Uid is empty if the experiment is not created
+	Uid string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("code %d: %s", e.Code, e.Message)
+}
+
+// Code return the code of err, errutil.NoErr for nil and errutil.UnknownErr for the errors not from sdk
+func Code(err error) int {
+	if err == nil {
+		return errutil.NoErr
+	}
+
+	var e *Error
+	if errors.As(err, &e) {
+		return e.Code
+	}
+
+	return errutil.UnknownErr
+}
+
+type Option func(info *injector.BaseInfo)
+
+func WithUid(uid string) Option {
+	return func(info *injector.BaseInfo) { info.Uid = uid }
+}
+
+func WithCreator(creator string) Option {
+	return func(info *injector.BaseInfo) { info.Creator = creator }
+}
+
+// WithTimeout recover the experiment automatically in the current process, rounded up to seconds
+func WithTimeout(timeout time.Duration) Option {
+	return func(info *injector.BaseInfo) {
+		info.Timeout = fmt.Sprintf("%ds", int64(math.Ceil(timeout.Seconds())))
+	}
+}
+
+func WithContainer(runtime, id string) Option {
+	return func(info *injector.BaseInfo) { info.ContainerRuntime, info.ContainerId = runtime, id }
+}
+
+func WithPod(namespace, name, container string) Option {
+	return func(info *injector.BaseInfo) {
+		info.PodNamespace, info.PodName, info.ContainerName = namespace, name, container
+	}
+}
+
+func WithAllowOverlap() Option {
+	return func(info *injector.BaseInfo) { info.AllowOverlap = true }
+}
+
+// Experiment is the handle of a successful injection
+type Experiment struct {
+	Uid    string
+	Target string
+	Fault  string
+	// Args and Runtime are the typed pointers of the injector, eg: *network.DelayArgs, with defaults filled
+	Args    interface{}
+	Runtime interface{}
+
+	mutex sync.Mutex
+	timer *time.Timer
+}
+
+// Recover the experiment and stop the timer of auto recover, it is ok to call it more than once
+func (e *Experiment) Recover(ctx context.Context) error {
+	e.mutex.Lock()
+	if e.timer != nil {
+		e.timer.Stop()
+	}
+	e.mutex.Unlock()
+
+	return Recover(ctx, e.Uid)
+}
+
+// Status query the current status of the experiment from the store
+func (e *Experiment) Status() (string, error) {
+	db, err := storage.GetExperimentStore()
+	if err != nil {
+		return "", &Error{Code: errutil.DBErr, Message: fmt.Sprintf("connect db error: %s", err.Error()), Uid: e.Uid}
+	}
+
+	exp, err := db.GetByUid(e.Uid)
+	if err != nil {
+		return "", &Error{Code: errutil.DBErr, Message: fmt.Sprintf("query experiment by uid[%s] error: %s", e.Uid, err.Error()), Uid: e.Uid}
+	}
+
+	return exp.Status, nil
+}
+
+// Inject create an experiment of target and fault, args must be the args struct of the injector or a pointer to it
+func Inject(ctx context.Context, target, fault string, args interface{}, opts ...Option) (*Experiment, error) {
+	i, err := injector.NewInjector(target, fault)
+	if err != nil {
+		return nil, &Error{Code: errutil.BadArgsErr, Message: fmt.Sprintf("find injector by target[%s] and fault[%s] error: %s, is the injector package imported?", target, fault, err.Error())}
+	}
+
+	if err := setArgs(i.GetArgs(), args); err != nil {
+		return nil, &Error{Code: errutil.BadArgsErr, Message: err.Error()}
+	}
+
+	info := &injector.BaseInfo{}
+	for _, opt := range opts {
+		opt(info)
+	}
+	i.SetCommonArgs(info)
+
+	exp := &Experiment{Target: target, Fault: fault}
+	code, msg := injector.ProcessInject(ctx, &sdkInjector{IInjector: i, exp: exp})
+	if code != errutil.NoErr {
+		re := &Error{Code: code, Message: msg}
+		if code != errutil.BadArgsErr && code != errutil.PolicyErr && code != errutil.ConflictErr {
+			re.Uid = i.GetInfo().Uid
+		}
+		return nil, re
+	}
+
+	exp.Uid, exp.Args, exp.Runtime = i.GetInfo().Uid, i.GetArgs(), i.GetRuntime()
+	return exp, nil
+}
+
+// Recover the experiment by uid
+func Recover(ctx context.Context, uid string) error {
+	if code, msg := injector.ProcessRecover(ctx, uid); code != errutil.NoErr {
+		return &Error{Code: code, Message: msg, Uid: uid}
+	}
+
+	return nil
+}
+
+func setArgs(dst, args interface{}) error {
+	if args == nil {
+		return nil
+	}
+
+	src := reflect.ValueOf(args)
+	if src.Kind() == reflect.Ptr {
+		if src.IsNil() {
+			return nil
+		}
+		src = src.Elem()
+	}
+
+	d := reflect.ValueOf(dst)
+	if d.Kind() != reflect.Ptr || d.IsNil() {
+		return fmt.Errorf("injector has no args, but get %s", src.Type())
+	}
+
+	if d.Elem().Type() != src.Type() {
+		return fmt.Errorf("args type must be %s, but get %s", d.Elem().Type(), src.Type())
+	}
+
+	d.Elem().Set(src)
+	return nil
+}
+
+// sdkInjector replace the delay recover which execs "chaosmetad recover" with a timer in the current process
+type sdkInjector struct {
+	injector.IInjector
+	exp *Experiment
+}
+
+// GetResource and GetVerifyScope keep the policy, conflict and verify checks of the wrapped injector
+func (i *sdkInjector) GetResource(ctx context.Context) (*injector.Resource, error) {
+	if r, ok := i.IInjector.(injector.IResource); ok {
+		return r.GetResource(ctx)
+	}
+
+	return nil, nil
+}
+
+func (i *sdkInjector) GetVerifyScope(ctx context.Context) (*injector.VerifyScope, error) {
+	if v, ok := i.IInjector.(injector.IVerifyScope); ok {
+		return v.GetVerifyScope(ctx)
+	}
+
+	return nil, nil
+}
+
+func (i *sdkInjector) DelayRecover(ctx context.Context, timeout int64) error {
+	var (
+		uid    = i.GetInfo().Uid
+		bgCtx  = utils.GetCtxWithTraceId(context.Background(), utils.GetTraceId(ctx))
+		logger = log.GetLogger(ctx)
+	)
+
+	i.exp.mutex.Lock()
+	defer i.exp.mutex.Unlock()
+	i.exp.timer = time.AfterFunc(time.Duration(timeout)*time.Second, func() {
+		if err := Recover(bgCtx, uid); err != nil {
+			logger.Warnf("auto recover experiment[%s] error: %s", uid, err.Error())
+		}
+	})
+
+	return nil
+}
@@ -0,0 +1,128 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package sdk
+
+import (
+	"context"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/file"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/storage"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestMain(m *testing.M) {
+	Setup(&Config{Store: storage.NewMemoryStore()})
+	os.Exit(m.Run())
+}
+
+func newFile(t *testing.T) string {
+	path := filepath.Join(t.TempDir(), "sdk.txt")
+	if err := os.WriteFile(path, nil, 0644); err != nil {
+		t.Fatalf("create file error: %s", err.Error())
+	}
+
+	return path
+}
+
+func checkPerm(t *testing.T, path string, perm os.FileMode) {
+	t.Helper()
+	info, err := os.Stat(path)
+	if err != nil {
+		t.Fatalf("stat file error: %s", err.Error())
+	}
+
+	if info.Mode().Perm() != perm {
+		t.Errorf("expected perm %o, get %o", perm, info.Mode().Perm())
+	}
+}
+
+func TestInjectAndRecover(t *testing.T) {
+	ctx, path := context.Background(), newFile(t)
+	exp, err := Inject(ctx, file.TargetFile, file.FaultFileChmod, file.ChmodArgs{Path: path, Permission: "600"})
+	if err != nil {
+		t.Fatalf("inject error: %s", err.Error())
+	}
+	checkPerm(t, path, 0600)
+
+	if r, ok := exp.Runtime.(*file.ChmodRuntime); !ok || r.Permission != "644" {
+		t.Errorf("unexpected runtime: %#v", exp.Runtime)
+	}
+
+	if err := exp.Recover(ctx); err != nil {
+		t.Fatalf("recover error: %s", err.Error())
+	}
+	checkPerm(t, path, 0644)
+
+	if status, err := exp.Status(); err != nil || status != utils.StatusDestroyed {
+		t.Errorf("expected status %s, get: %s, %v", utils.StatusDestroyed, status, err)
+	}
+
+	if err := exp.Recover(ctx); err != nil {
+		t.Errorf("recover again error: %s", err.Error())
+	}
+}
+
+func TestInjectTimeout(t *testing.T) {
+	ctx, path := context.Background(), newFile(t)
+	exp, err := Inject(ctx, file.TargetFile, file.FaultFileChmod, &file.ChmodArgs{Path: path, Permission: "600"}, WithTimeout(time.Second))
+	if err != nil {
+		t.Fatalf("inject error: %s", err.Error())
+	}
+
+	time.Sleep(2 * time.Second)
+	checkPerm(t, path, 0644)
+	if status, _ := exp.Status(); status != utils.StatusDestroyed {
+		t.Errorf("expected status %s, get: %s", utils.StatusDestroyed, status)
+	}
+}
+
+func TestInjectError(t *testing.T) {
+	ctx := context.Background()
+	for _, c := range []struct {
+		name  string
+		fault string
+		args  interface{}
+		code  int
+	}{
+		{"unknown fault", "none", nil, errutil.BadArgsErr},
+		{"wrong args type", file.FaultFileChmod, &file.AddArgs{}, errutil.BadArgsErr},
+		{"invalid args", file.FaultFileChmod, &file.ChmodArgs{Path: "relative"}, errutil.BadArgsErr},
+	} {
+		if _, err := Inject(ctx, file.TargetFile, c.fault, c.args); Code(err) != c.code {
+			t.Errorf("%s: expected code %d, get: %v", c.name, c.code, err)
+		}
+	}
+
+	path := newFile(t)
+	exp, err := Inject(ctx, file.TargetFile, file.FaultFileChmod, &file.ChmodArgs{Path: path, Permission: "600"})
+	if err != nil {
+		t.Fatalf("inject error: %s", err.Error())
+	}
+	defer exp.Recover(ctx)
+
+	if _, err := Inject(ctx, file.TargetFile, file.FaultFileChmod, &file.ChmodArgs{Path: path, Permission: "600"}); Code(err) != errutil.ConflictErr {
+		t.Errorf("expected conflict error, get: %v", err)
+	}
+
+	if err := Recover(ctx, "notexist"); Code(err) != errutil.DBErr {
+		t.Errorf("expected recover not exist experiment error, get: %v", err)
+	}
+}
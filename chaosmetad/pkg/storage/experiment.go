@@ -19,27 +19,46 @@ package storage
 import (
 	"errors"
 	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/event"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"gorm.io/gorm"
 	"time"
 )
 
-var globalExpStorage *experimentStore
+var globalExpStorage ExperimentStore
+
+// ExperimentStore persists the experiments, the default implementation is backed by sqlite
+type ExperimentStore interface {
+	Insert(exp *Experiment) error
+	Update(exp *Experiment) error
+	UpdateStatus(uid, status string) error
+	UpdateStatusAndErr(uid, status, errMsg string) error
+	GetByUid(uid string) (*Experiment, error)
+	QueryByStatus(status string) ([]*Experiment, error)
+	QueryByUpdateTime(since string) ([]*Experiment, error)
+	QueryByOption(uid, status, target, fault, creator, cr, cId string, offset, limit uint) ([]*Experiment, int64, error)
+}
 
 type experimentStore struct {
 	db *dbStorage
 }
 
-func GetExperimentStore() (*experimentStore, error) {
+// SetExperimentStore replace the default sqlite store, eg: with NewMemoryStore when embedded in other programs
+func SetExperimentStore(s ExperimentStore) {
+	globalExpStorage = s
+}
+
+func GetExperimentStore() (ExperimentStore, error) {
 	if globalExpStorage == nil {
 		db, err := newDBStorage()
 		if err != nil {
 			return nil, fmt.Errorf("newDBStorage error: %s", err.Error())
 		}
-		globalExpStorage, err = newExperimentStore(db)
+		s, err := newExperimentStore(db)
 		if err != nil {
 			return nil, fmt.Errorf("newExperimentStore error: %s", err.Error())
 		}
+		globalExpStorage = s
 	}
 
 	return globalExpStorage, nil
@@ -62,6 +81,7 @@ func (e *experimentStore) Insert(exp *Experiment) error {
 		return err
 	}
 
+	event.GetBus().PublishStatus(exp.Uid, exp.Target, exp.Fault, exp.Status, exp.Error)
 	return nil
 }
 
@@ -74,6 +94,7 @@ func (e *experimentStore) Update(exp *Experiment) error {
 		return err
 	}
 
+	event.GetBus().PublishStatus(exp.Uid, exp.Target, exp.Fault, exp.Status, exp.Error)
 	return nil
 }
 
@@ -85,6 +106,7 @@ func (e *experimentStore) UpdateStatus(uid, status string) error {
 		return err
 	}
 
+	event.GetBus().PublishStatus(uid, "", "", status, "")
 	return nil
 }
 
@@ -96,6 +118,7 @@ func (e *experimentStore) UpdateStatusAndErr(uid, status, errMsg string) error {
 		return err
 	}
 
+	event.GetBus().PublishStatus(uid, "", "", status, errMsg)
 	return nil
 }
 
@@ -114,6 +137,32 @@ func (e *experimentStore) GetByUid(uid string) (*Experiment, error) {
 	return exp, nil
 }
 
+func (e *experimentStore) QueryByStatus(status string) ([]*Experiment, error) {
+	var exps []*Experiment
+	if err := e.db.Model(Experiment{}).
+		Where("status = ?", status).
+		Find(&exps).
+		Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return exps, nil
+}
+
+// QueryByUpdateTime query experiments updated not earlier than "since", time format: utils.TimeFormat
+func (e *experimentStore) QueryByUpdateTime(since string) ([]*Experiment, error) {
+	var exps []*Experiment
+	if err := e.db.Model(Experiment{}).
+		Where("update_time >= ?", since).
+		Order("update_time ASC").
+		Find(&exps).
+		Error; err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, err
+	}
+
+	return exps, nil
+}
+
 func (e *experimentStore) QueryByOption(uid, status, target, fault, creator, cr, cId string, offset, limit uint) ([]*Experiment, int64, error) {
 	var exps []*Experiment
 	db := e.db.Model(Experiment{})
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package storage
+
+import (
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/event"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"sort"
+	"sync"
+	"time"
+)
+
+type memoryStore struct {
+	mutex sync.RWMutex
+	exps  map[string]*Experiment
+}
+
+// NewMemoryStore return a store which keeps the experiments in memory, they are lost when the process exit
+func NewMemoryStore() ExperimentStore {
+	return &memoryStore{exps: make(map[string]*Experiment)}
+}
+
+func (m *memoryStore) Insert(exp *Experiment) error {
+	m.mutex.Lock()
+	if _, ok := m.exps[exp.Uid]; ok {
+		m.mutex.Unlock()
+		return fmt.Errorf("experiment[%s] already exists", exp.Uid)
+	}
+
+	nowTime := time.Now().Format(utils.TimeFormat)
+	exp.CreateTime, exp.UpdateTime = nowTime, nowTime
+	newExp := *exp
+	m.exps[exp.Uid] = &newExp
+	m.mutex.Unlock()
+
+	event.GetBus().PublishStatus(exp.Uid, exp.Target, exp.Fault, exp.Status, exp.Error)
+	return nil
+}
+
+// Update only update the non-empty fields, the same as the sqlite store
+func (m *memoryStore) Update(exp *Experiment) error {
+	exp.UpdateTime = time.Now().Format(utils.TimeFormat)
+	if err := m.update(exp.Uid, func(old *Experiment) {
+		for _, f := range []struct {
+			dst *string
+			src string
+		}{
+			{&old.Target, exp.Target}, {&old.Fault, exp.Fault}, {&old.Args, exp.Args}, {&old.Runtime, exp.Runtime},
+			{&old.Timeout, exp.Timeout}, {&old.Status, exp.Status}, {&old.Creator, exp.Creator}, {&old.Error, exp.Error},
+			{&old.CreateTime, exp.CreateTime}, {&old.UpdateTime, exp.UpdateTime},
+			{&old.ContainerId, exp.ContainerId}, {&old.ContainerRuntime, exp.ContainerRuntime},
+			{&old.Snapshot, exp.Snapshot}, {&old.Drift, exp.Drift},
+		} {
+			if f.src != "" {
+				*f.dst = f.src
+			}
+		}
+	}); err != nil {
+		return err
+	}
+
+	event.GetBus().PublishStatus(exp.Uid, exp.Target, exp.Fault, exp.Status, exp.Error)
+	return nil
+}
+
+func (m *memoryStore) UpdateStatus(uid, status string) error {
+	return m.UpdateStatusAndErr(uid, status, "")
+}
+
+func (m *memoryStore) UpdateStatusAndErr(uid, status, errMsg string) error {
+	if err := m.update(uid, func(old *Experiment) {
+		old.Status, old.UpdateTime = status, time.Now().Format(utils.TimeFormat)
+		if errMsg != "" {
+			old.Error = errMsg
+		}
+	}); err != nil {
+		return err
+	}
+
+	event.GetBus().PublishStatus(uid, "", "", status, errMsg)
+	return nil
+}
+
+func (m *memoryStore) update(uid string, f func(old *Experiment)) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	old, ok := m.exps[uid]
+	if !ok {
+		return fmt.Errorf("experiment[%s] not found", uid)
+	}
+
+	f(old)
+	return nil
+}
+
+func (m *memoryStore) GetByUid(uid string) (*Experiment, error) {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	exp, ok := m.exps[uid]
+	if !ok {
+		return nil, fmt.Errorf("experiment[%s] not found", uid)
+	}
+
+	re := *exp
+	return &re, nil
+}
+
+func (m *memoryStore) QueryByStatus(status string) ([]*Experiment, error) {
+	return m.query(func(exp *Experiment) bool {
+		return exp.Status == status
+	}), nil
+}
+
+// QueryByUpdateTime query experiments updated not earlier than "since", time format: utils.TimeFormat
+func (m *memoryStore) QueryByUpdateTime(since string) ([]*Experiment, error) {
+	exps := m.query(func(exp *Experiment) bool {
+		return exp.UpdateTime >= since
+	})
+
+	sort.SliceStable(exps, func(i, j int) bool {
+		return exps[i].UpdateTime < exps[j].UpdateTime
+	})
+
+	return exps, nil
+}
+
+func (m *memoryStore) QueryByOption(uid, status, target, fault, creator, cr, cId string, offset, limit uint) ([]*Experiment, int64, error) {
+	exps := m.query(func(exp *Experiment) bool {
+		for _, f := range [][2]string{
+			{uid, exp.Uid}, {status, exp.Status}, {creator, exp.Creator}, {target, exp.Target},
+			{fault, exp.Fault}, {cr, exp.ContainerRuntime}, {cId, exp.ContainerId},
+		} {
+			if f[0] != "" && f[0] != f[1] {
+				return false
+			}
+		}
+
+		return true
+	})
+
+	sort.SliceStable(exps, func(i, j int) bool {
+		return exps[i].CreateTime > exps[j].CreateTime
+	})
+
+	total := int64(len(exps))
+	if int(offset) >= len(exps) {
+		return []*Experiment{}, total, nil
+	}
+
+	exps = exps[offset:]
+	if limit > 0 && int(limit) < len(exps) {
+		exps = exps[:limit]
+	}
+
+	return exps, total, nil
+}
+
+func (m *memoryStore) query(filter func(exp *Experiment) bool) []*Experiment {
+	m.mutex.RLock()
+	defer m.mutex.RUnlock()
+
+	exps := make([]*Experiment, 0)
+	for _, exp := range m.exps {
+		if filter(exp) {
+			re := *exp
+			exps = append(exps, &re)
+		}
+	}
+
+	return exps
+}
@@ -30,4 +30,6 @@ type Experiment struct {
 	UpdateTime       string `json:"update_time"`
 	ContainerId      string `json:"container_id"`
 	ContainerRuntime string `json:"container_runtime"`
+	Snapshot         string `json:"snapshot,omitempty"`
+	Drift            string `json:"drift,omitempty"`
 }
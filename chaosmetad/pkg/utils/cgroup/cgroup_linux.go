@@ -69,6 +69,14 @@ func GetBlkioCPath(uid string, prefix string) string {
 	return fmt.Sprintf("%s/%s%s/%s_%s", containercgroup.RootCgroupPath, BLKIO, prefix, BlkioCgroupName, uid)
 }
 
+func GetNetClsCPath(uid string, prefix string) string {
+	return fmt.Sprintf("%s/%s%s/%s_%s", containercgroup.RootCgroupPath, NETCLS, prefix, NetClsCgroupName, uid)
+}
+
+func GetNetClsConfig(classId uint32, cgroupPath string) string {
+	return fmt.Sprintf("echo 0x%x > %s/%s", classId, cgroupPath, NetClsClassIdFile)
+}
+
 func CheckPidListBlkioCgroup(ctx context.Context, pidList []int) error {
 	for _, unitP := range pidList {
 		oldPath, err := GetpidCurCgroup(ctx, unitP, BLKIO)
@@ -20,6 +20,7 @@ const (
 	BLKIO  = "blkio"
 	CPUSET = "cpuset"
 	MEMORY = "memory"
+	NETCLS = "net_cls"
 )
 
 const (
@@ -33,4 +34,6 @@ const (
 	WriteIOFile            = "blkio.throttle.write_iops_device"
 	ReadIOFile             = "blkio.throttle.read_iops_device"
 	BlkioCgroupName        = "chaosmeta_blkio"
+	NetClsClassIdFile      = "net_cls.classid"
+	NetClsCgroupName       = "chaosmeta_net_cls"
 )
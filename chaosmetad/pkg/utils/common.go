@@ -63,6 +63,8 @@ const (
 	StatusSuccess   = "success"
 	StatusError     = "error"
 	StatusDestroyed = "destroyed"
+	// StatusRecoverVerifyFailed recover success, but the system drifted from the snapshot taken before inject
+	StatusRecoverVerifyFailed = "recover_verify_failed"
 )
 
 func NewUid() string {
@@ -243,3 +243,45 @@ func TestCheckSpeedValue(t *testing.T) {
 		})
 	}
 }
+
+func TestGetSpeedBit(t *testing.T) {
+	tests := []struct {
+		name    string
+		sp      string
+		want    int64
+		wantErr bool
+	}{
+		{
+			name: "no unit",
+			sp:   "100",
+			want: 100,
+		},
+		{
+			name: "kbit",
+			sp:   "8kbit",
+			want: 8000,
+		},
+		{
+			name: "mbit",
+			sp:   "100mbit",
+			want: 100000000,
+		},
+		{
+			name:    "invalid unit",
+			sp:      "100mb",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := GetSpeedBit(tt.sp)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("GetSpeedBit() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if !tt.wantErr && got != tt.want {
+				t.Errorf("GetSpeedBit() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
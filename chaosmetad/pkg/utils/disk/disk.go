@@ -23,9 +23,17 @@ import (
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"os"
+	"path/filepath"
+	"runtime"
 	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
 )
 
+const inodeFilesPerDir = 10000
+
 func GetDevList(ctx context.Context, cr, cId string, devStr string) ([]string, error) {
 	if devStr == "" {
 		return nil, fmt.Errorf("args dev-list is empty")
@@ -126,3 +134,168 @@ func GetFillKBytes(dir string, percent int, bytes string) (int64, error) {
 
 	return fillKBytes, nil
 }
+
+// GetFillPercent return the disk usage percent of dir after filling bytes
+func GetFillPercent(dir string, bytes string) (float64, error) {
+	fillKBytes, err := utils.GetKBytes(bytes)
+	if err != nil {
+		return -1, fmt.Errorf("\"bytes\" is invalid: %s", err.Error())
+	}
+
+	usage, err := disk.Usage(dir)
+	if err != nil {
+		return -1, fmt.Errorf("get disk info error: %s", err.Error())
+	}
+
+	return usage.UsedPercent + float64(fillKBytes)/(float64(usage.Total)/1024)*100, nil
+}
+
+// GetMountInfo return the device and mount point of the filesystem which dir is on
+func GetMountInfo(ctx context.Context, cr, cId string, dir string) (string, string, error) {
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("df -P %s | tail -n 1", dir), []string{namespace.MNT})
+	if err != nil {
+		return "", "", fmt.Errorf("get filesystem of %s error: %s", dir, err.Error())
+	}
+
+	fields := strings.Fields(re)
+	if len(fields) < 6 {
+		return "", "", fmt.Errorf("unexpected output of df: %s", re)
+	}
+
+	return fields[0], fields[len(fields)-1], nil
+}
+
+// GetInodeFillCount return the count of files to create to reach the inode used percent of dir
+func GetInodeFillCount(dir string, percent int) (int64, error) {
+	var stat syscall.Statfs_t
+	if err := syscall.Statfs(dir, &stat); err != nil {
+		return -1, fmt.Errorf("get filesystem info of %s error: %s", dir, err.Error())
+	}
+
+	if stat.Files == 0 {
+		return -1, fmt.Errorf("filesystem of %s has no fixed inode count, not support inode fill", dir)
+	}
+
+	used := stat.Files - stat.Ffree
+	usedPercent := float64(used) / float64(stat.Files) * 100
+	if float64(percent) < usedPercent {
+		return -1, fmt.Errorf("target path current inode usage is %.2f%%, no need to fill", usedPercent)
+	}
+
+	count := int64(float64(percent)/100*float64(stat.Files)) - int64(used)
+	// one inode for every sub dir
+	count -= count/inodeFilesPerDir + 1
+	if count <= 0 {
+		return -1, fmt.Errorf("fill inode count[%d] must larget than 0", count)
+	}
+
+	return count, nil
+}
+
+// RunFillInode creates count empty files in sub dirs of dir in parallel, stop when no inode left
+func RunFillInode(ctx context.Context, count int64, dir string) error {
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return fmt.Errorf("create dir[%s] error: %s", dir, err.Error())
+	}
+
+	var (
+		dirCount       = (count + inodeFilesPerDir - 1) / inodeFilesPerDir
+		next     int64 = -1
+		full     int32
+		wg       sync.WaitGroup
+		errOnce  sync.Once
+		runErr   error
+	)
+
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for atomic.LoadInt32(&full) == 0 {
+				d := atomic.AddInt64(&next, 1)
+				if d >= dirCount {
+					return
+				}
+
+				filesNum := int64(inodeFilesPerDir)
+				if d == dirCount-1 {
+					filesNum = count - d*inodeFilesPerDir
+				}
+
+				if err := fillInodeDir(filepath.Join(dir, fmt.Sprintf("%d", d)), filesNum); err != nil {
+					atomic.StoreInt32(&full, 1)
+					if err != syscall.ENOSPC {
+						errOnce.Do(func() { runErr = err })
+					}
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	return runErr
+}
+
+func fillInodeDir(dir string, count int64) error {
+	if err := os.Mkdir(dir, 0755); err != nil {
+		return unwrapErrno(err)
+	}
+
+	for i := int64(0); i < count; i++ {
+		fd, err := syscall.Open(filepath.Join(dir, fmt.Sprintf("%d", i)), syscall.O_CREAT|syscall.O_WRONLY|syscall.O_CLOEXEC, 0644)
+		if err != nil {
+			return unwrapErrno(err)
+		}
+		_ = syscall.Close(fd)
+	}
+
+	return nil
+}
+
+func unwrapErrno(err error) error {
+	if pathErr, ok := err.(*os.PathError); ok {
+		return pathErr.Err
+	}
+
+	return err
+}
+
+// RemoveFillInode removes the sub dirs of dir in parallel
+func RemoveFillInode(ctx context.Context, dir string) error {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return fmt.Errorf("read dir[%s] error: %s", dir, err.Error())
+	}
+
+	var (
+		ch      = make(chan string, len(entries))
+		wg      sync.WaitGroup
+		errOnce sync.Once
+		runErr  error
+	)
+
+	for _, entry := range entries {
+		ch <- filepath.Join(dir, entry.Name())
+	}
+	close(ch)
+
+	for w := 0; w < runtime.NumCPU(); w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for subDir := range ch {
+				if err := os.RemoveAll(subDir); err != nil {
+					errOnce.Do(func() { runErr = err })
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if runErr != nil {
+		return fmt.Errorf("remove fill files error: %s", runErr.Error())
+	}
+
+	return os.RemoveAll(dir)
+}
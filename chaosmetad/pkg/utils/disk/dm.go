@@ -0,0 +1,195 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"golang.org/x/sys/unix"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+const (
+	DmDirPath = "/dev/mapper"
+
+	DmTargetDelay   = "delay"
+	DmTargetFlakey  = "flakey"
+	DmTargetLinear  = "linear"
+	DmTargetStriped = "striped"
+
+	FlakeyErrorReads  = "error_reads"
+	FlakeyErrorWrites = "error_writes"
+)
+
+// GetDmPath return the device path of the device-mapper device
+func GetDmPath(name string) string {
+	return fmt.Sprintf("%s/%s", DmDirPath, name)
+}
+
+// GetDmName return the device-mapper name of the block device, return "" if it is not a device-mapper device
+func GetDmName(dev string) (string, error) {
+	realPath, err := filepath.EvalSymlinks(dev)
+	if err != nil {
+		return "", err
+	}
+
+	info, err := os.Stat(realPath)
+	if err != nil {
+		return "", err
+	}
+
+	if info.Mode()&os.ModeDevice == 0 || info.Mode()&os.ModeCharDevice != 0 {
+		return "", fmt.Errorf("%s is not a block device", dev)
+	}
+
+	name, err := os.ReadFile(fmt.Sprintf("/sys/class/block/%s/dm/name", filepath.Base(realPath)))
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", nil
+		}
+		return "", err
+	}
+
+	return strings.TrimSpace(string(name)), nil
+}
+
+// GetDevNum return "major:minor" of the block device
+func GetDevNum(dev string) (string, error) {
+	var st unix.Stat_t
+	if err := unix.Stat(dev, &st); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%d:%d", unix.Major(st.Rdev), unix.Minor(st.Rdev)), nil
+}
+
+// SupportDmTarget check if the kernel support the device-mapper target, eg: delay、flakey
+func SupportDmTarget(ctx context.Context, target string) (bool, error) {
+	re, err := cmdexec.RunBashCmdWithOutput(ctx, "dmsetup targets")
+	if err != nil {
+		return false, err
+	}
+
+	for _, line := range strings.Split(re, "\n") {
+		if fields := strings.Fields(line); len(fields) > 0 && fields[0] == target {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// GetBlockSectors return the size of the block device in 512-byte sectors
+func GetBlockSectors(ctx context.Context, dev string) (int64, error) {
+	re, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("blockdev --getsz %s", dev))
+	if err != nil {
+		return -1, err
+	}
+
+	return strconv.ParseInt(strings.TrimSpace(re), 10, 64)
+}
+
+// GetDelayTable return a single line table of dm-delay, the delays are in milliseconds and 0 means no delay
+func GetDelayTable(dev string, sectors, readDelay, writeDelay int64) string {
+	return fmt.Sprintf("0 %d %s %s 0 %d %s 0 %d", sectors, DmTargetDelay, dev, readDelay, dev, writeDelay)
+}
+
+// GetFlakeyTable return a single line table of dm-flakey, the I/O fails in the down interval(seconds).
+// all the I/O fails if no feature provided, or only the reads or writes fails with the feature
+func GetFlakeyTable(dev string, sectors, upInterval, downInterval int64, features ...string) string {
+	table := fmt.Sprintf("0 %d %s %s 0 %d %d", sectors, DmTargetFlakey, dev, upInterval, downInterval)
+	if len(features) > 0 {
+		table = fmt.Sprintf("%s %d %s", table, len(features), strings.Join(features, " "))
+	}
+
+	return table
+}
+
+// getTableInput the table may have multiple lines, which is passed to dmsetup by stdin
+func getTableInput(table string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n") {
+		lines = append(lines, fmt.Sprintf("'%s'", strings.TrimSpace(line)))
+	}
+
+	return fmt.Sprintf("printf '%%s\\n' %s", strings.Join(lines, " "))
+}
+
+func ExistDm(ctx context.Context, name string) bool {
+	_, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("dmsetup info %s", name))
+	return err == nil
+}
+
+func GetDmTable(ctx context.Context, name string) (string, error) {
+	re, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("dmsetup table %s", name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(re), nil
+}
+
+// GetTableTargets return the target type of each line in the table, the line format is "start length target args..."
+func GetTableTargets(table string) ([]string, error) {
+	var targets []string
+	for _, line := range strings.Split(strings.TrimSpace(table), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) < 3 {
+			return nil, fmt.Errorf("invalid table line: %s", line)
+		}
+
+		targets = append(targets, fields[2])
+	}
+
+	return targets, nil
+}
+
+// GetDmDevNum return "major:minor" of the device-mapper device, which is used in tables without depending on the device node
+func GetDmDevNum(ctx context.Context, name string) (string, error) {
+	re, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("dmsetup info -c --noheadings -o major,minor --separator : %s", name))
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(re), nil
+}
+
+func CreateDm(ctx context.Context, name, table string) error {
+	_, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("%s | dmsetup create %s", getTableInput(table), name))
+	return err
+}
+
+// ReloadDm replace the table of a live device, the I/O in flight is flushed when suspend, and the device is always resumed
+func ReloadDm(ctx context.Context, name, table string) error {
+	_, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("dmsetup suspend %s && { %s | dmsetup load %s; re=$?; dmsetup resume %s && exit $re; }",
+		name, getTableInput(table), name, name))
+	return err
+}
+
+// RemoveDm fail if the device is still in use, eg: mounted
+func RemoveDm(ctx context.Context, name string) error {
+	if !ExistDm(ctx, name) {
+		return nil
+	}
+
+	_, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("dmsetup remove --retry %s", name))
+	return err
+}
@@ -0,0 +1,54 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestGetTable(t *testing.T) {
+	for _, c := range []struct {
+		table, expect string
+	}{
+		{GetDelayTable("/dev/loop0", 2048, 0, 50), "0 2048 delay /dev/loop0 0 0 /dev/loop0 0 50"},
+		{GetFlakeyTable("7:0", 2048, 0, 1), "0 2048 flakey 7:0 0 0 1"},
+		{GetFlakeyTable("7:0", 2048, 5, 10, FlakeyErrorWrites), "0 2048 flakey 7:0 0 5 10 1 error_writes"},
+	} {
+		if c.table != c.expect {
+			t.Errorf("expected table: %s, get: %s", c.expect, c.table)
+		}
+	}
+}
+
+func TestGetTableInput(t *testing.T) {
+	table := "0 100 linear 8:1 0\n100 200 linear 8:2 0\n"
+	if re := getTableInput(table); re != "printf '%s\\n' '0 100 linear 8:1 0' '100 200 linear 8:2 0'" {
+		t.Errorf("unexpected table input: %s", re)
+	}
+}
+
+func TestGetTableTargets(t *testing.T) {
+	targets, err := GetTableTargets("0 100 linear 8:1 0\n100 200 striped 2 128 8:2 0 8:3 0\n")
+	if err != nil || !reflect.DeepEqual(targets, []string{DmTargetLinear, DmTargetStriped}) {
+		t.Errorf("unexpected targets: %v, error: %v", targets, err)
+	}
+
+	if _, err := GetTableTargets("0 100"); err == nil {
+		t.Errorf("expected error of invalid table")
+	}
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package disk
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"strings"
+)
+
+// GetMountOptions return the per-mount options of the mount point in the mount namespace of target,
+// return "" if path is not a mount point
+func GetMountOptions(ctx context.Context, cr, cId string, path string) (string, error) {
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("awk '$5 == \"%s\" {print $6}' /proc/self/mountinfo | tail -n 1", path), []string{namespace.MNT})
+	if err != nil {
+		return "", fmt.Errorf("get mount info of %s error: %s", path, err.Error())
+	}
+
+	return strings.TrimSpace(re), nil
+}
+
+// IsReadOnlyOptions check if the mount options contain "ro"
+func IsReadOnlyOptions(options string) bool {
+	for _, opt := range strings.Split(options, ",") {
+		if opt == "ro" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ReplaceRwOptions replace "rw" or "ro" of the mount options with rw
+func ReplaceRwOptions(options string, rw string) string {
+	opts := strings.Split(options, ",")
+	for i, opt := range opts {
+		if opt == "rw" || opt == "ro" {
+			opts[i] = rw
+		}
+	}
+
+	return strings.Join(opts, ",")
+}
+
+func RemountBind(ctx context.Context, cr, cId string, path, options string) error {
+	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("mount -o remount,bind,%s %s", options, path), []string{namespace.MNT})
+	return err
+}
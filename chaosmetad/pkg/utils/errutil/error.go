@@ -23,6 +23,7 @@ import (
 	"os"
 )
 
+// the codes are also the exit codes of the cmd and the "code" of json output, only append new codes to keep them stable
 const (
 	NoErr = iota
 	BadArgsErr
@@ -31,6 +32,9 @@ const (
 	InternalErr
 	RecoverErr
 	UnknownErr
+	AuthErr
+	PolicyErr
+	ConflictErr
 )
 
 const (
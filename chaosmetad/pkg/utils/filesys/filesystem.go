@@ -19,15 +19,18 @@ package filesys
 import (
 	"context"
 	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
 	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
 	"os"
+	"path/filepath"
 	"strconv"
 	"strings"
 )
 
 const (
 	FileNotFoundKey = "exit code: 1"
+	MaxSymlinkCount = 255
 )
 
 func getChmodCmd(path, perm string) string {
@@ -364,3 +367,78 @@ func CreateFdFile(ctx context.Context, dir, filePrefix string, count int) error
 
 	return nil
 }
+
+// GetHostPath return the path which can access the file in the mount namespace of container from host.
+// the symlinks are resolved in the root of container, so that an absolute symlink does not point to the file of host
+func GetHostPath(ctx context.Context, cr, cId string, path string) (string, error) {
+	if cr == "" {
+		return path, nil
+	}
+
+	client, err := crclient.GetClient(ctx, cr)
+	if err != nil {
+		return "", fmt.Errorf("get %s client error: %s", cr, err.Error())
+	}
+
+	pid, err := client.GetPidById(ctx, cId)
+	if err != nil {
+		return "", fmt.Errorf("get pid of container[%s]'s init process error: %s", cId, err.Error())
+	}
+
+	return ResolveInRoot(fmt.Sprintf("/proc/%d/root", pid), path)
+}
+
+// ResolveInRoot join path to root with its symlinks resolved as if root is "/", same as RESOLVE_IN_ROOT of openat2.
+// the components not exist are joined as they are
+func ResolveInRoot(root, path string) (string, error) {
+	var (
+		current   = "/"
+		remaining = path
+		linkCount int
+	)
+
+	for remaining != "" {
+		var part string
+		if index := strings.IndexByte(remaining, '/'); index >= 0 {
+			part, remaining = remaining[:index], remaining[index+1:]
+		} else {
+			part, remaining = remaining, ""
+		}
+
+		if part == "" || part == "." {
+			continue
+		}
+
+		// ".." can not go out of root
+		next := filepath.Join(current, part)
+		fileInfo, err := os.Lstat(root + next)
+		if err != nil {
+			if os.IsNotExist(err) {
+				current = next
+				continue
+			}
+			return "", fmt.Errorf("get info of %s error: %s", next, err.Error())
+		}
+
+		if fileInfo.Mode()&os.ModeSymlink == 0 {
+			current = next
+			continue
+		}
+
+		if linkCount++; linkCount > MaxSymlinkCount {
+			return "", fmt.Errorf("too many levels of symbolic links: %s", path)
+		}
+
+		dest, err := os.Readlink(root + next)
+		if err != nil {
+			return "", fmt.Errorf("read link %s error: %s", next, err.Error())
+		}
+
+		if filepath.IsAbs(dest) {
+			current = "/"
+		}
+		remaining = dest + "/" + remaining
+	}
+
+	return root + current, nil
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package filesys
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestResolveInRoot(t *testing.T) {
+	root := t.TempDir()
+	for _, dir := range []string{"etc", "run", "data/b"} {
+		if err := os.MkdirAll(filepath.Join(root, dir), 0755); err != nil {
+			t.Fatalf("create dir error: %s", err.Error())
+		}
+	}
+
+	for link, dest := range map[string]string{
+		"etc/resolv.conf": "/run/resolv.conf",
+		"data/a":          "../data/b",
+		"data/escape":     "../../../../etc",
+		"data/loop":       "loop",
+	} {
+		if err := os.Symlink(dest, filepath.Join(root, link)); err != nil {
+			t.Fatalf("create link error: %s", err.Error())
+		}
+	}
+
+	for _, c := range []struct {
+		path, want string
+	}{
+		{"/etc/resolv.conf", "/run/resolv.conf"},
+		{"/data/a/file", "/data/b/file"},
+		{"/../../etc/hosts", "/etc/hosts"},
+		{"/data/escape/hosts", "/etc/hosts"},
+		{"/not/exist/../file", "/not/file"},
+	} {
+		got, err := ResolveInRoot(root, c.path)
+		if err != nil {
+			t.Errorf("ResolveInRoot(%s) error: %s", c.path, err.Error())
+		} else if got != root+c.want {
+			t.Errorf("ResolveInRoot(%s) = %s, want %s", c.path, got, root+c.want)
+		}
+	}
+
+	if _, err := ResolveInRoot(root, "/data/loop"); err == nil {
+		t.Errorf("expect error of symlink loop")
+	}
+}
@@ -0,0 +1,207 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcproxy
+
+import (
+	"crypto/tls"
+	"fmt"
+	"golang.org/x/net/http2"
+	"golang.org/x/net/http2/h2c"
+	"io"
+	"log"
+	"math/rand"
+	"net"
+	"net/http"
+	"net/http/httputil"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// CodeNone means no status is returned by the rule
+	CodeNone = -1
+	// MaxCode is the max value of the grpc status codes
+	MaxCode = 16
+)
+
+var codeNames = []string{"OK", "CANCELLED", "UNKNOWN", "INVALID_ARGUMENT", "DEADLINE_EXCEEDED", "NOT_FOUND", "ALREADY_EXISTS",
+	"PERMISSION_DENIED", "RESOURCE_EXHAUSTED", "FAILED_PRECONDITION", "ABORTED", "OUT_OF_RANGE", "UNIMPLEMENTED", "INTERNAL",
+	"UNAVAILABLE", "DATA_LOSS", "UNAUTHENTICATED"}
+
+// Rule select the grpc calls by ":path" and metadata, and apply the fault to Percent% of them.
+// the fault is applied in order: delay, then status or reset, the call is forwarded to upstream if neither is set
+type Rule struct {
+	Method  string            `json:"method,omitempty"`
+	Headers map[string]string `json:"headers,omitempty"`
+	Percent int               `json:"percent"`
+	Delay   int64             `json:"delay,omitempty"`
+	Code    int               `json:"code"`
+	Message string            `json:"message,omitempty"`
+	Reset   bool              `json:"reset,omitempty"`
+}
+
+// ParseCode support the code name(case insensitive) or the number, eg: UNAVAILABLE、14
+func ParseCode(code string) (int, error) {
+	if c, err := strconv.Atoi(code); err == nil {
+		if c < 0 || c > MaxCode {
+			return CodeNone, fmt.Errorf("code %d is not in [0, %d]", c, MaxCode)
+		}
+		return c, nil
+	}
+
+	for c, name := range codeNames {
+		if strings.EqualFold(name, code) {
+			return c, nil
+		}
+	}
+
+	return CodeNone, fmt.Errorf("%s is not a valid grpc status code", code)
+}
+
+// ParseHeaders convert "k1=v1,k2=v2" to a map, the keys are lowercase as the grpc metadata
+func ParseHeaders(headers string) (map[string]string, error) {
+	if headers == "" {
+		return nil, nil
+	}
+
+	re := make(map[string]string)
+	for _, kv := range strings.Split(headers, ",") {
+		index := strings.Index(kv, "=")
+		if index <= 0 {
+			return nil, fmt.Errorf("%s is not in format: [key]=[value]", kv)
+		}
+
+		re[strings.ToLower(strings.TrimSpace(kv[:index]))] = strings.TrimSpace(kv[index+1:])
+	}
+
+	return re, nil
+}
+
+// CheckMethod method is "/[service]/[method]", and "/[service]/*" match all the methods of the service
+func CheckMethod(method string) error {
+	if method == "" {
+		return nil
+	}
+
+	items := strings.Split(method, "/")
+	if len(items) != 3 || items[0] != "" || items[1] == "" || items[2] == "" {
+		return fmt.Errorf("%s is not in format: /[service]/[method]", method)
+	}
+
+	return nil
+}
+
+// Match check the request is a grpc call matched by the rule, the percent is not considered
+func (r *Rule) Match(req *http.Request) bool {
+	if !strings.HasPrefix(req.Header.Get("Content-Type"), "application/grpc") {
+		return false
+	}
+
+	if r.Method != "" {
+		if strings.HasSuffix(r.Method, "/*") {
+			if !strings.HasPrefix(req.URL.Path, strings.TrimSuffix(r.Method, "*")) {
+				return false
+			}
+		} else if req.URL.Path != r.Method {
+			return false
+		}
+	}
+
+	for k, v := range r.Headers {
+		if req.Header.Get(k) != v {
+			return false
+		}
+	}
+
+	return true
+}
+
+// NewHandler return a h2c handler which forward the grpc calls to the "port" of the local address that accept them.
+// the requests redirected by iptables are accepted by the address of the incoming interface,
+// so that the connections to upstream are not redirected again
+func NewHandler(port int, rule *Rule) http.Handler {
+	p := &httputil.ReverseProxy{
+		Director: func(req *http.Request) {
+			host := "127.0.0.1"
+			if addr, ok := req.Context().Value(http.LocalAddrContextKey).(*net.TCPAddr); ok {
+				host = addr.IP.String()
+			}
+
+			req.URL.Scheme = "http"
+			req.URL.Host = net.JoinHostPort(host, strconv.Itoa(port))
+		},
+		Transport: &http2.Transport{
+			AllowHTTP: true,
+			DialTLS: func(network, addr string, cfg *tls.Config) (net.Conn, error) {
+				return net.DialTimeout(network, addr, 10*time.Second)
+			},
+		},
+		FlushInterval: -1,
+		// the proxy runs in background, nobody reads its output, eg: "proxy error: context canceled" of a canceled call
+		ErrorLog: log.New(io.Discard, "", 0),
+	}
+
+	return h2c.NewHandler(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if rule.Match(req) && rand.Intn(100) < rule.Percent {
+			if rule.Delay > 0 {
+				select {
+				case <-time.After(time.Duration(rule.Delay) * time.Millisecond):
+				case <-req.Context().Done():
+					return
+				}
+			}
+
+			if rule.Reset {
+				// the http2 server reset the stream when the handler abort
+				panic(http.ErrAbortHandler)
+			}
+
+			if rule.Code != CodeNone {
+				writeStatus(w, rule.Code, rule.Message)
+				return
+			}
+		}
+
+		p.ServeHTTP(w, req)
+	}), &http2.Server{})
+}
+
+// writeStatus reply a "Trailers-Only" response
+func writeStatus(w http.ResponseWriter, code int, msg string) {
+	w.Header().Set("Content-Type", "application/grpc")
+	w.Header().Set("Grpc-Status", strconv.Itoa(code))
+	if msg != "" {
+		w.Header().Set("Grpc-Message", encodeMessage(msg))
+	}
+	w.WriteHeader(http.StatusOK)
+}
+
+// encodeMessage percent-encode the message as the grpc protocol
+func encodeMessage(msg string) string {
+	var sb strings.Builder
+	for i := 0; i < len(msg); i++ {
+		c := msg[i]
+		if c >= ' ' && c <= '~' && c != '%' {
+			sb.WriteByte(c)
+		} else {
+			sb.WriteString(fmt.Sprintf("%%%02X", c))
+		}
+	}
+
+	return sb.String()
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package grpcproxy
+
+import (
+	"context"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"net"
+	"net/http"
+	"testing"
+	"time"
+)
+
+const checkMethod = "/grpc.health.v1.Health/Check"
+
+// startServer start a grpc health server as the upstream, and a proxy with the rule in front of it
+func startServer(t *testing.T, rule *Rule) grpc_health_v1.HealthClient {
+	sl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen server error: %s", err.Error())
+	}
+
+	s := grpc.NewServer()
+	grpc_health_v1.RegisterHealthServer(s, health.NewServer())
+	go func() { _ = s.Serve(sl) }()
+	t.Cleanup(s.Stop)
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen proxy error: %s", err.Error())
+	}
+
+	p := &http.Server{Handler: NewHandler(sl.Addr().(*net.TCPAddr).Port, rule)}
+	go func() { _ = p.Serve(pl) }()
+	t.Cleanup(func() { _ = p.Close() })
+
+	conn, err := grpc.Dial(pl.Addr().String(), grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatalf("dial proxy error: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return grpc_health_v1.NewHealthClient(conn)
+}
+
+func check(client grpc_health_v1.HealthClient, md map[string]string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	ctx = metadata.NewOutgoingContext(ctx, metadata.New(md))
+	re, err := client.Check(ctx, &grpc_health_v1.HealthCheckRequest{})
+	if err != nil {
+		return err
+	}
+
+	if re.Status != grpc_health_v1.HealthCheckResponse_SERVING {
+		return status.Errorf(codes.Unknown, "unexpected health status: %s", re.Status)
+	}
+
+	return nil
+}
+
+func TestProxy_Status(t *testing.T) {
+	client := startServer(t, &Rule{
+		Method:  checkMethod,
+		Headers: map[string]string{"x-user": "chaos"},
+		Percent: 100,
+		Code:    int(codes.Unavailable),
+		Message: "injected by 100%",
+	})
+
+	err := check(client, map[string]string{"x-user": "chaos"})
+	if s, _ := status.FromError(err); s.Code() != codes.Unavailable || s.Message() != "injected by 100%" {
+		t.Errorf("expected injected status, get: %v", err)
+	}
+
+	if err := check(client, map[string]string{"x-user": "other"}); err != nil {
+		t.Errorf("expected metadata not matched call to pass, get: %s", err.Error())
+	}
+}
+
+func TestProxy_Method(t *testing.T) {
+	for _, c := range []struct {
+		method string
+		inject bool
+	}{
+		{"/grpc.health.v1.Health/*", true},
+		{"/grpc.health.v1.Health/Watch", false},
+		{"/other.Service/*", false},
+	} {
+		client := startServer(t, &Rule{Method: c.method, Percent: 100, Code: int(codes.Internal)})
+		if err := check(client, nil); (status.Code(err) == codes.Internal) != c.inject {
+			t.Errorf("method %s: expected inject %v, get: %v", c.method, c.inject, err)
+		}
+	}
+}
+
+func TestProxy_Percent(t *testing.T) {
+	client := startServer(t, &Rule{Percent: 0, Code: int(codes.Internal)})
+	for i := 0; i < 10; i++ {
+		if err := check(client, nil); err != nil {
+			t.Fatalf("expected no call to be injected with 0 percent, get: %s", err.Error())
+		}
+	}
+}
+
+func TestProxy_Delay(t *testing.T) {
+	client := startServer(t, &Rule{Method: checkMethod, Percent: 100, Delay: 300, Code: CodeNone})
+	start := time.Now()
+	if err := check(client, nil); err != nil {
+		t.Fatalf("expected delayed call to pass, get: %s", err.Error())
+	}
+
+	if cost := time.Since(start); cost < 300*time.Millisecond {
+		t.Errorf("expected delay at least 300ms, get: %s", cost)
+	}
+}
+
+func TestProxy_Reset(t *testing.T) {
+	client := startServer(t, &Rule{Method: checkMethod, Percent: 100, Reset: true, Code: CodeNone})
+	if err := check(client, nil); status.Code(err) != codes.Internal {
+		t.Errorf("expected stream reset, get: %v", err)
+	}
+
+	if err := check(client, nil); status.Code(err) != codes.Internal {
+		t.Errorf("expected connection to be reused after reset, get: %v", err)
+	}
+}
+
+func TestParseCode(t *testing.T) {
+	for _, c := range []struct {
+		code   string
+		expect int
+	}{
+		{"14", 14}, {"unavailable", 14}, {"DEADLINE_EXCEEDED", 4}, {"17", CodeNone}, {"bad", CodeNone},
+	} {
+		if re, _ := ParseCode(c.code); re != c.expect {
+			t.Errorf("code %s: expected %d, get %d", c.code, c.expect, re)
+		}
+	}
+}
+
+func TestEncodeMessage(t *testing.T) {
+	if re := encodeMessage("a 100%\n错"); re != "a 100%25%0A%E9%94%99" {
+		t.Errorf("unexpected encoded message: %s", re)
+	}
+}
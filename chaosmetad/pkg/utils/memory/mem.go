@@ -65,6 +65,41 @@ func CalculateFillKBytes(ctx context.Context, cr, cId string, percent int, fillB
 	return fillKBytes, nil
 }
 
+// GetMemUsedPercent calculated in the same way as CalculateFillKBytes: (Total - Available)/Total
+func GetMemUsedPercent(ctx context.Context, cr, cId string) (float64, error) {
+	total, err := getMemTotal(ctx, cr, cId)
+	if err != nil {
+		return -1, fmt.Errorf("get total mem error: %s", err.Error())
+	}
+
+	avail, err := getMemAvailable(ctx, cr, cId)
+	if err != nil {
+		return -1, fmt.Errorf("get avail mem error: %s", err.Error())
+	}
+
+	return (total - avail) / total * 100, nil
+}
+
+// GetFillPercent return the mem usage percent after filling bytes
+func GetFillPercent(ctx context.Context, cr, cId string, fillBytes string) (float64, error) {
+	fillKBytes, err := utils.GetKBytes(fillBytes)
+	if err != nil {
+		return -1, fmt.Errorf("\"bytes\" is invalid: %s", err.Error())
+	}
+
+	total, err := getMemTotal(ctx, cr, cId)
+	if err != nil {
+		return -1, fmt.Errorf("get total mem error: %s", err.Error())
+	}
+
+	usedPercent, err := GetMemUsedPercent(ctx, cr, cId)
+	if err != nil {
+		return -1, err
+	}
+
+	return usedPercent + float64(fillKBytes)/total*100, nil
+}
+
 func FillCache(ctx context.Context, cr, cId string, percent int, bytes string, dir string, filename string) error {
 	fillKBytes, err := CalculateFillKBytes(ctx, cr, cId, percent, bytes)
 	if err != nil {
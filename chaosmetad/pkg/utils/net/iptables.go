@@ -0,0 +1,238 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"hash/fnv"
+	"strconv"
+	"strings"
+)
+
+const (
+	ChainInput      = "INPUT"
+	ChainOutput     = "OUTPUT"
+	ChainPrerouting = "PREROUTING"
+
+	// MarkShift and MarkMask are the bits of packet mark used by experiments, the other bits are kept,
+	// eg: 0x4000 and 0x8000 used by kube-proxy
+	MarkShift = 16
+	MarkMask  = 0xff << MarkShift
+)
+
+// GetMark return a non-zero mark in MarkMask chosen by uid
+func GetMark(uid string) uint32 {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	return (h.Sum32()%(MarkMask>>MarkShift) + 1) << MarkShift
+}
+
+// GetMarkStr return the mark with MarkMask for iptables, eg: "0x10000/0xff0000"
+func GetMarkStr(mark uint32) string {
+	return fmt.Sprintf("%#x/%#x", mark, MarkMask)
+}
+
+// getPortRangeList convert the port list with mask to port ranges: [low, high]
+func getPortRangeList(portListStr string) ([][2]int, error) {
+	if portListStr == "" {
+		return nil, nil
+	}
+
+	portList, err := GetValidPortList(portListStr)
+	if err != nil {
+		return nil, err
+	}
+
+	var re [][2]int
+	for _, unit := range portList {
+		portArr := strings.Split(unit, utils.PortSplit)
+		port, _ := strconv.Atoi(portArr[0])
+		mask, _ := strconv.ParseInt(portArr[1], 0, 32)
+		re = append(re, [2]int{port & int(mask), port | (^int(mask) & 0xffff)})
+	}
+
+	return re, nil
+}
+
+func getIptablesPort(portRange [2]int) string {
+	if portRange[0] == portRange[1] {
+		return fmt.Sprintf("%d", portRange[0])
+	}
+
+	return fmt.Sprintf("%d:%d", portRange[0], portRange[1])
+}
+
+// GetTcpMatchArgsList return the iptables match args of tcp packets sent by the local side of matched connections,
+// one for each combination of src port and dst port. if reverse is true, return the args of packets received instead
+func GetTcpMatchArgsList(filter *Filter, reverse bool) ([]string, error) {
+	srcIp, dstIp, srcPort, dstPort := filter.SrcIp, filter.DstIp, filter.SrcPort, filter.DstPort
+	if reverse {
+		srcIp, dstIp, srcPort, dstPort = dstIp, srcIp, dstPort, srcPort
+	}
+
+	var base = "-p tcp"
+	if srcIp != "" {
+		ipList, err := GetValidIPList(srcIp, true)
+		if err != nil {
+			return nil, fmt.Errorf("get valid src ip list from [%s] error: %s", srcIp, err.Error())
+		}
+		base += fmt.Sprintf(" -s %s", strings.Join(ipList, ","))
+	}
+
+	if dstIp != "" {
+		ipList, err := GetValidIPList(dstIp, true)
+		if err != nil {
+			return nil, fmt.Errorf("get valid dst ip list from [%s] error: %s", dstIp, err.Error())
+		}
+		base += fmt.Sprintf(" -d %s", strings.Join(ipList, ","))
+	}
+
+	srcPortList, err := getPortRangeList(srcPort)
+	if err != nil {
+		return nil, fmt.Errorf("get valid src port list from [%s] error: %s", srcPort, err.Error())
+	}
+
+	dstPortList, err := getPortRangeList(dstPort)
+	if err != nil {
+		return nil, fmt.Errorf("get valid dst port list from [%s] error: %s", dstPort, err.Error())
+	}
+
+	var srcArgs, dstArgs = []string{""}, []string{""}
+	if len(srcPortList) > 0 {
+		srcArgs = nil
+		for _, unit := range srcPortList {
+			srcArgs = append(srcArgs, fmt.Sprintf(" --sport %s", getIptablesPort(unit)))
+		}
+	}
+
+	if len(dstPortList) > 0 {
+		dstArgs = nil
+		for _, unit := range dstPortList {
+			dstArgs = append(dstArgs, fmt.Sprintf(" --dport %s", getIptablesPort(unit)))
+		}
+	}
+
+	var re []string
+	for _, s := range srcArgs {
+		for _, d := range dstArgs {
+			re = append(re, base+s+d)
+		}
+	}
+
+	if len(re) > MaxRuleCount {
+		return nil, fmt.Errorf("iptables rule count is larger than %d", MaxRuleCount)
+	}
+
+	return re, nil
+}
+
+// AddIptablesRules rule format: "[chain] [rule specification]", the added rules are deleted if failed.
+// the rules are inserted to the head of chain in order, so that they are not skipped by the existing accept rules or jumps
+func AddIptablesRules(ctx context.Context, cr, cId string, rules []string) error {
+	for i := len(rules) - 1; i >= 0; i-- {
+		if _, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, getInsertRuleCmd(rules[i]), []string{namespace.NET}); err != nil {
+			if undoErr := DeleteIptablesRules(ctx, cr, cId, rules[i+1:]); undoErr != nil {
+				return fmt.Errorf("add rule[%s] error: %s, undo error: %s", rules[i], err.Error(), undoErr.Error())
+			}
+			return fmt.Errorf("add rule[%s] error: %s", rules[i], err.Error())
+		}
+	}
+
+	return nil
+}
+
+func getInsertRuleCmd(rule string) string {
+	chainAndSpec := strings.SplitN(rule, " ", 2)
+	if len(chainAndSpec) < 2 {
+		return fmt.Sprintf("iptables -I %s 1", rule)
+	}
+
+	return fmt.Sprintf("iptables -I %s 1 %s", chainAndSpec[0], chainAndSpec[1])
+}
+
+// DeleteIptablesRules ignore the rules not exist
+func DeleteIptablesRules(ctx context.Context, cr, cId string, rules []string) error {
+	for _, rule := range rules {
+		if _, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("if iptables -C %s; then iptables -D %s; fi", rule, rule), []string{namespace.NET}); err != nil {
+			return fmt.Errorf("delete rule[%s] error: %s", rule, err.Error())
+		}
+	}
+
+	return nil
+}
+
+// getSSFilter return the filter expression of ss, src is the local side and dst is the remote side
+func getSSFilter(filter *Filter) (string, error) {
+	var exprList []string
+	for _, unit := range []struct {
+		name, value string
+	}{{"src", filter.SrcIp}, {"dst", filter.DstIp}} {
+		if unit.value == "" {
+			continue
+		}
+
+		ipList, err := GetValidIPList(unit.value, true)
+		if err != nil {
+			return "", fmt.Errorf("get valid %s ip list from [%s] error: %s", unit.name, unit.value, err.Error())
+		}
+
+		var condList []string
+		for _, ip := range ipList {
+			condList = append(condList, fmt.Sprintf("%s %s", unit.name, ip))
+		}
+		exprList = append(exprList, fmt.Sprintf("( %s )", strings.Join(condList, " or ")))
+	}
+
+	for _, unit := range []struct {
+		name, value string
+	}{{"sport", filter.SrcPort}, {"dport", filter.DstPort}} {
+		portList, err := getPortRangeList(unit.value)
+		if err != nil {
+			return "", fmt.Errorf("get valid %s list from [%s] error: %s", unit.name, unit.value, err.Error())
+		}
+
+		if len(portList) == 0 {
+			continue
+		}
+
+		var condList []string
+		for _, r := range portList {
+			if r[0] == r[1] {
+				condList = append(condList, fmt.Sprintf("%s = :%d", unit.name, r[0]))
+			} else {
+				condList = append(condList, fmt.Sprintf("( %s >= :%d and %s <= :%d )", unit.name, r[0], unit.name, r[1]))
+			}
+		}
+		exprList = append(exprList, fmt.Sprintf("( %s )", strings.Join(condList, " or ")))
+	}
+
+	return strings.Join(exprList, " and "), nil
+}
+
+// KillTcpConnections forcibly close the established tcp connections matched by filter, the peer receive a RST
+func KillTcpConnections(ctx context.Context, cr, cId string, filter *Filter) (string, error) {
+	expr, err := getSSFilter(filter)
+	if err != nil {
+		return "", err
+	}
+
+	return cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ss -K -t -n state established '%s'", expr), []string{namespace.NET})
+}
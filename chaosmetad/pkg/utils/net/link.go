@@ -0,0 +1,145 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"strconv"
+	"strings"
+)
+
+const NeighborStatePermanent = "PERMANENT"
+
+type LinkInfo struct {
+	Up  bool
+	Mtu int
+}
+
+type Neighbor struct {
+	Ip     string `json:"ip"`
+	Mac    string `json:"mac,omitempty"`
+	States string `json:"states,omitempty"`
+}
+
+func (n *Neighbor) IsPermanent() bool {
+	return strings.Contains(n.States, NeighborStatePermanent)
+}
+
+// parseLinkInfo parse the output of "ip -o link show dev [interface]"
+func parseLinkInfo(output string) (*LinkInfo, error) {
+	start, end := strings.Index(output, "<"), strings.Index(output, ">")
+	if start < 0 || end < start {
+		return nil, fmt.Errorf("flags is not found in: %s", output)
+	}
+
+	var info = &LinkInfo{}
+	for _, flag := range strings.Split(output[start+1:end], ",") {
+		if flag == "UP" {
+			info.Up = true
+		}
+	}
+
+	fields := strings.Fields(output[end+1:])
+	for i := 0; i < len(fields)-1; i++ {
+		if fields[i] == "mtu" {
+			mtu, err := strconv.Atoi(fields[i+1])
+			if err != nil {
+				return nil, fmt.Errorf("mtu[%s] is not a num", fields[i+1])
+			}
+			info.Mtu = mtu
+			return info, nil
+		}
+	}
+
+	return nil, fmt.Errorf("mtu is not found in: %s", output)
+}
+
+// parseNeighbor parse the output of "ip neigh show to [ip] dev [interface]", return nil if no entry
+func parseNeighbor(ip, output string) *Neighbor {
+	fields := strings.Fields(output)
+	if len(fields) == 0 {
+		return nil
+	}
+
+	var n = &Neighbor{Ip: ip}
+	var states []string
+	for i := 1; i < len(fields); i++ {
+		if fields[i] == "lladdr" && i+1 < len(fields) {
+			n.Mac = fields[i+1]
+			i++
+			continue
+		}
+
+		if strings.ToUpper(fields[i]) == fields[i] {
+			states = append(states, fields[i])
+		}
+	}
+	n.States = strings.Join(states, ",")
+
+	return n
+}
+
+func GetLinkInfo(ctx context.Context, cr, cId, netInterface string) (*LinkInfo, error) {
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ip -o link show dev %s", netInterface), []string{namespace.NET})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseLinkInfo(strings.TrimSpace(re))
+}
+
+func SetLinkUp(ctx context.Context, cr, cId, netInterface string, up bool) error {
+	state := "down"
+	if up {
+		state = "up"
+	}
+
+	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, GetSetLinkCmd(netInterface, state), []string{namespace.NET})
+	return err
+}
+
+func GetSetLinkCmd(netInterface, state string) string {
+	return fmt.Sprintf("ip link set dev %s %s", netInterface, state)
+}
+
+func SetLinkMtu(ctx context.Context, cr, cId, netInterface string, mtu int) error {
+	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ip link set dev %s mtu %d", netInterface, mtu), []string{namespace.NET})
+	return err
+}
+
+func GetNeighbor(ctx context.Context, cr, cId, netInterface, ip string) (*Neighbor, error) {
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ip neigh show to %s dev %s", ip, netInterface), []string{namespace.NET})
+	if err != nil {
+		return nil, err
+	}
+
+	return parseNeighbor(ip, re), nil
+}
+
+// ReplaceNeighbor add or replace a static neighbor entry, ip neigh use arp for ipv4 and ndp for ipv6
+func ReplaceNeighbor(ctx context.Context, cr, cId, netInterface, ip, mac string) error {
+	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ip neigh replace %s lladdr %s dev %s nud permanent", ip, mac, netInterface), []string{namespace.NET})
+	return err
+}
+
+func DeleteNeighbor(ctx context.Context, cr, cId, netInterface, ip string) error {
+	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("if ip neigh show to %s dev %s | grep -q .; then ip neigh del %s dev %s; fi", ip, netInterface, ip, netInterface), []string{namespace.NET})
+	return err
+}
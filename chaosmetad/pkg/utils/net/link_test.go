@@ -0,0 +1,103 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package net
+
+import (
+	"reflect"
+	"testing"
+)
+
+func Test_parseLinkInfo(t *testing.T) {
+	tests := []struct {
+		name    string
+		output  string
+		want    *LinkInfo
+		wantErr bool
+	}{
+		{
+			name:   "up",
+			output: "2: eth0@if5: <BROADCAST,MULTICAST,UP,LOWER_UP> mtu 1500 qdisc noqueue state UP mode DEFAULT group default qlen 1000",
+			want:   &LinkInfo{Up: true, Mtu: 1500},
+		},
+		{
+			name:   "down",
+			output: "3: vt0@vt1: <BROADCAST,MULTICAST> mtu 9000 qdisc noqueue state DOWN mode DEFAULT group default qlen 1000",
+			want:   &LinkInfo{Up: false, Mtu: 9000},
+		},
+		{
+			name:    "invalid",
+			output:  "Device \"eth9\" does not exist.",
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseLinkInfo(tt.output)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseLinkInfo() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseLinkInfo() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func Test_parseNeighbor(t *testing.T) {
+	tests := []struct {
+		name          string
+		output        string
+		want          *Neighbor
+		wantPermanent bool
+	}{
+		{
+			name:   "no entry",
+			output: "",
+		},
+		{
+			name:          "permanent",
+			output:        "10.0.0.5 lladdr 02:11:11:11:11:11 PERMANENT",
+			want:          &Neighbor{Ip: "10.0.0.5", Mac: "02:11:11:11:11:11", States: "PERMANENT"},
+			wantPermanent: true,
+		},
+		{
+			name:   "router and reachable",
+			output: "fe80::1 lladdr 02:11:11:11:11:12 router REACHABLE",
+			want:   &Neighbor{Ip: "fe80::1", Mac: "02:11:11:11:11:12", States: "REACHABLE"},
+		},
+		{
+			name:   "failed",
+			output: "10.0.0.6  FAILED",
+			want:   &Neighbor{Ip: "10.0.0.6", States: "FAILED"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ip := tt.name
+			if tt.want != nil {
+				ip = tt.want.Ip
+			}
+			got := parseNeighbor(ip, tt.output)
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parseNeighbor() = %v, want %v", got, tt.want)
+			}
+			if got != nil && got.IsPermanent() != tt.wantPermanent {
+				t.Errorf("IsPermanent() = %v, want %v", got.IsPermanent(), tt.wantPermanent)
+			}
+		})
+	}
+}
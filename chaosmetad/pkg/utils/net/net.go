@@ -38,8 +38,32 @@ const (
 	ProtocolTCP6 = "tcp6"
 	ProtocolUDP  = "udp"
 	ProtocolUDP6 = "udp6"
+	ProtocolICMP = "icmp"
 )
 
+var ipProtocolNum = map[string]int{
+	ProtocolTCP:  6,
+	ProtocolUDP:  17,
+	ProtocolICMP: 1,
+}
+
+// Filter is the condition to classify packets to the target class, all the provided conditions must be matched.
+// Tos and Dscp are matched with the tos byte of ip header, Mark is the fw mark in MarkMask set for the packets of target processes
+type Filter struct {
+	SrcIp    string
+	DstIp    string
+	SrcPort  string
+	DstPort  string
+	Protocol string
+	Tos      string
+	Dscp     string
+	Mark     uint32
+}
+
+func (f *Filter) IsEmpty() bool {
+	return f.SrcIp == "" && f.DstIp == "" && f.SrcPort == "" && f.DstPort == "" && f.Protocol == "" && f.Tos == "" && f.Dscp == "" && f.Mark == 0
+}
+
 func getExistTCRootQdiscCmd(netInterface string) string {
 	return fmt.Sprintf("tc qdisc ls dev %s | grep -w '1: root' | grep -v grep | wc -l", netInterface)
 }
@@ -58,6 +82,17 @@ func getAddNetemQdiscCmd(netInterface, parent, fault string, args string) string
 	return fmt.Sprintf("tc qdisc add dev %s %s netem %s %s", netInterface, parent, fault, args)
 }
 
+// GetChangeNetemQdiscCmd change the args of the netem qdisc in place, the qdisc is found by its parent
+func GetChangeNetemQdiscCmd(netInterface, parent, fault string, args string) string {
+	if parent == "" {
+		parent = "root handle 1:"
+	} else {
+		parent = fmt.Sprintf("parent %s", parent)
+	}
+
+	return fmt.Sprintf("tc qdisc change dev %s %s netem %s %s", netInterface, parent, fault, args)
+}
+
 func getAddPrioQdiscCmd(netInterface, parent, name string) string {
 	if parent == "" {
 		parent = "root"
@@ -81,6 +116,11 @@ func getAddLimitClassCmd(netInterface, rate, mode string) string {
 	return fmt.Sprintf("tc class add dev %s parent 1: classid 1:%d htb rate %s", netInterface, subNum, rate)
 }
 
+// GetChangeLimitClassCmd change the rate of the limit class in place
+func GetChangeLimitClassCmd(netInterface, rate, mode string) string {
+	return strings.Replace(getAddLimitClassCmd(netInterface, rate, mode), "tc class add", "tc class change", 1)
+}
+
 func AddLimitClass(ctx context.Context, cr, cId, netInterface, rate, mode string) error {
 	_, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, getAddLimitClassCmd(netInterface, rate, mode), []string{namespace.NET})
 	return err
@@ -92,8 +132,8 @@ func AddHTBQdisc(ctx context.Context, cr, cId, netInterface string) error {
 	return err
 }
 
-func AddFilter(ctx context.Context, cr, cId, netInterface, target, srcIpListStr, dstIpListStr, srcPortListStr, dstPortListStr string) error {
-	cmd, err := getAddFilterCmd(ctx, netInterface, target, srcIpListStr, dstIpListStr, srcPortListStr, dstPortListStr)
+func AddFilter(ctx context.Context, cr, cId, netInterface, target string, filter *Filter) error {
+	cmd, err := getAddFilterCmd(ctx, netInterface, target, filter)
 	if err != nil {
 		return fmt.Errorf("get filter cmd error: %s", err.Error())
 	}
@@ -210,8 +250,77 @@ func getPortMask(mask int) string {
 	return fmt.Sprintf("0x%x", maskValue)
 }
 
-func getAddFilterCmd(ctx context.Context, netInterface, target, srcIpListStr, dstIpListStr, srcPortListStr, dstPortListStr string) (tcFilterStr string, err error) {
-	srcIpList, dstIpList, srcPortList, dstPortList, err := getStrList(srcIpListStr, dstIpListStr, srcPortListStr, dstPortListStr)
+// GetValidProtocol return the protocol number in ip header
+func GetValidProtocol(protocol string) (int, error) {
+	num, ok := ipProtocolNum[protocol]
+	if !ok {
+		return -1, fmt.Errorf("protocol only support: %s、%s、%s", ProtocolTCP, ProtocolUDP, ProtocolICMP)
+	}
+
+	return num, nil
+}
+
+// GetValidTos return the tos value, support decimal and hex value, eg: 16, 0x10
+func GetValidTos(tos string) (int, error) {
+	value, err := strconv.ParseUint(tos, 0, 8)
+	if err != nil {
+		return -1, fmt.Errorf("%s is not a valid tos value, should in [0,255]", tos)
+	}
+
+	return int(value), nil
+}
+
+// GetValidDscp return the dscp value, dscp is the high 6 bits of tos
+func GetValidDscp(dscp string) (int, error) {
+	value, err := strconv.ParseUint(dscp, 0, 6)
+	if err != nil {
+		return -1, fmt.Errorf("%s is not a valid dscp value, should in [0,63]", dscp)
+	}
+
+	return int(value), nil
+}
+
+// getCommonMatchArgs return the match args which are the same in all rules
+func getCommonMatchArgs(filter *Filter) (string, error) {
+	var args string
+	if filter.Protocol != "" {
+		num, err := GetValidProtocol(filter.Protocol)
+		if err != nil {
+			return "", err
+		}
+		args += fmt.Sprintf("match ip protocol %d 0xff ", num)
+	}
+
+	if filter.Tos != "" {
+		tos, err := GetValidTos(filter.Tos)
+		if err != nil {
+			return "", err
+		}
+		args += fmt.Sprintf("match ip tos 0x%x 0xff ", tos)
+	}
+
+	if filter.Dscp != "" {
+		dscp, err := GetValidDscp(filter.Dscp)
+		if err != nil {
+			return "", err
+		}
+		args += fmt.Sprintf("match ip tos 0x%x 0xfc ", dscp<<2)
+	}
+
+	if filter.Mark != 0 {
+		args += fmt.Sprintf("match mark 0x%x 0x%x ", filter.Mark, MarkMask)
+	}
+
+	return args, nil
+}
+
+func getAddFilterCmd(ctx context.Context, netInterface, target string, filter *Filter) (tcFilterStr string, err error) {
+	srcIpList, dstIpList, srcPortList, dstPortList, err := getStrList(filter.SrcIp, filter.DstIp, filter.SrcPort, filter.DstPort)
+	if err != nil {
+		return
+	}
+
+	commonArgs, err := getCommonMatchArgs(filter)
 	if err != nil {
 		return
 	}
@@ -219,6 +328,10 @@ func getAddFilterCmd(ctx context.Context, netInterface, target, srcIpListStr, ds
 	var si, di, sp, dp int
 	var ruleArr []string
 	var siLen, diLen, spLen, dpLen = len(srcIpList), len(dstIpList), len(srcPortList), len(dstPortList)
+	if siLen == 0 && diLen == 0 && spLen == 0 && dpLen == 0 && commonArgs != "" {
+		ruleArr = append(ruleArr, fmt.Sprintf("tc filter add dev %s parent 1: prio 1 protocol ip u32 %sflowid %s", netInterface, commonArgs, target))
+	}
+
 	for {
 		if si >= siLen && di >= diLen && sp >= spLen && dp >= dpLen {
 			break
@@ -249,6 +362,7 @@ func getAddFilterCmd(ctx context.Context, netInterface, target, srcIpListStr, ds
 				args += fmt.Sprintf("match ip dport %s %s ", portArr[0], portArr[1])
 			}
 
+			args += commonArgs
 			if args != "" {
 				ruleArr = append(ruleArr, fmt.Sprintf("tc filter add dev %s parent 1: prio 1 protocol ip u32 %sflowid %s", netInterface, args, target))
 				if len(ruleArr) > MaxRuleCount {
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package net
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+func Test_getAddFilterCmd(t *testing.T) {
+	tests := []struct {
+		name    string
+		filter  *Filter
+		want    string
+		wantErr bool
+	}{
+		{
+			name:   "ip and port",
+			filter: &Filter{DstIp: "10.0.0.1", DstPort: "80"},
+			want:   "tc filter add dev eth0 parent 1: prio 1 protocol ip u32 match ip dst 10.0.0.1 match ip dport 80 0xffff flowid 1:4",
+		},
+		{
+			name:   "protocol and dscp only",
+			filter: &Filter{Protocol: ProtocolUDP, Dscp: "46"},
+			want:   "tc filter add dev eth0 parent 1: prio 1 protocol ip u32 match ip protocol 17 0xff match ip tos 0xb8 0xfc flowid 1:4",
+		},
+		{
+			name:   "port with tos and mark",
+			filter: &Filter{SrcPort: "8080", Tos: "0x10", Mark: 0x120000},
+			want:   "tc filter add dev eth0 parent 1: prio 1 protocol ip u32 match ip sport 8080 0xffff match ip tos 0x10 0xff match mark 0x120000 0xff0000 flowid 1:4",
+		},
+		{
+			name:    "invalid protocol",
+			filter:  &Filter{Protocol: "sctp"},
+			wantErr: true,
+		},
+		{
+			name:    "invalid dscp",
+			filter:  &Filter{Dscp: "64"},
+			wantErr: true,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := getAddFilterCmd(context.Background(), "eth0", "1:4", tt.filter)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("getAddFilterCmd() error = %v, wantErr %v", err, tt.wantErr)
+				return
+			}
+			if got != tt.want {
+				t.Errorf("getAddFilterCmd() got = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetTcpMatchArgsList(t *testing.T) {
+	filter := &Filter{DstIp: "10.0.0.1,10.1.0.0/16", SrcPort: "8080", DstPort: "3306,12000/8"}
+	got, err := GetTcpMatchArgsList(filter, false)
+	if err != nil {
+		t.Fatalf("GetTcpMatchArgsList() error = %v", err)
+	}
+
+	want := []string{
+		"-p tcp -d 10.0.0.1,10.1.0.0/16 --sport 8080 --dport 3306",
+		"-p tcp -d 10.0.0.1,10.1.0.0/16 --sport 8080 --dport 11776:12031",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("GetTcpMatchArgsList() got = %v, want %v", got, want)
+	}
+
+	got, err = GetTcpMatchArgsList(filter, true)
+	if err != nil {
+		t.Fatalf("GetTcpMatchArgsList() reverse error = %v", err)
+	}
+
+	if got[0] != "-p tcp -s 10.0.0.1,10.1.0.0/16 --sport 3306 --dport 8080" {
+		t.Errorf("GetTcpMatchArgsList() reverse got = %v", got)
+	}
+}
+
+func Test_getSSFilter(t *testing.T) {
+	got, err := getSSFilter(&Filter{DstIp: "10.0.0.1", DstPort: "80,12000/8"})
+	if err != nil {
+		t.Fatalf("getSSFilter() error = %v", err)
+	}
+
+	want := "( dst 10.0.0.1 ) and ( dport = :80 or ( dport >= :11776 and dport <= :12031 ) )"
+	if got != want {
+		t.Errorf("getSSFilter() got = %v, want %v", got, want)
+	}
+}
+
+func TestGetMark(t *testing.T) {
+	for _, uid := range []string{"", "a", "abcdefghijklmnop", "0123456789abcdef"} {
+		if mark := GetMark(uid); mark == 0 || mark&^MarkMask != 0 {
+			t.Errorf("GetMark(%q) = %#x, expect non-zero in mask %#x", uid, mark, MarkMask)
+		}
+	}
+
+	if got := GetMarkStr(0x10000); got != "0x10000/0xff0000" {
+		t.Errorf("GetMarkStr() = %s", got)
+	}
+}
+
+func Test_getInsertRuleCmd(t *testing.T) {
+	got := getInsertRuleCmd("OUTPUT -t nat -p tcp --dport 80 -j REDIRECT --to-ports 40000")
+	want := "iptables -I OUTPUT 1 -t nat -p tcp --dport 80 -j REDIRECT --to-ports 40000"
+	if got != want {
+		t.Errorf("getInsertRuleCmd() got = %v, want %v", got, want)
+	}
+}
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package net
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/namespace"
+	"hash/fnv"
+	"strings"
+)
+
+const (
+	ProxyPortBase  = 40000
+	ProxyPortRange = 20000
+)
+
+// GetProxyPort return the default listen port of the proxy chosen by uid, in [ProxyPortBase, ProxyPortBase+ProxyPortRange)
+func GetProxyPort(uid string) int {
+	h := fnv.New32a()
+	_, _ = h.Write([]byte(uid))
+	return ProxyPortBase + int(h.Sum32()%ProxyPortRange)
+}
+
+// CheckPortFree check that no process listens on the tcp port in the net namespace of the container
+func CheckPortFree(ctx context.Context, cr, cId string, port int) error {
+	if !cmdexec.SupportCmd("ss") {
+		return fmt.Errorf("not support command \"ss\"")
+	}
+
+	re, err := cmdexec.ExecCommonWithNS(ctx, cr, cId, fmt.Sprintf("ss -Htln sport = :%d", port), []string{namespace.NET})
+	if err != nil {
+		return fmt.Errorf("check port error: %s", err.Error())
+	}
+
+	if strings.TrimSpace(re) != "" {
+		return fmt.Errorf("port[%d] is in use", port)
+	}
+
+	return nil
+}
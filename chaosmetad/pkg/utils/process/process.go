@@ -201,6 +201,15 @@ func CheckExistAndKillByKey(ctx context.Context, processKey string) error {
 	return nil
 }
 
+// KillByKeyWithErr undo by killing the processes of key, and return msg as error
+func KillByKeyWithErr(ctx context.Context, processKey, msg string) error {
+	if err := CheckExistAndKillByKey(ctx, processKey); err != nil {
+		log.GetLogger(ctx).Warnf("undo: kill process error: %s", err.Error())
+	}
+
+	return fmt.Errorf(msg)
+}
+
 func ExistProcessByKey(ctx context.Context, key string) (bool, error) {
 	re, err := cmdexec.RunBashCmdWithOutput(ctx, fmt.Sprintf("ps -ef | grep '%s' | grep -v grep | grep -v '%s inject' | grep -v '%s recover' | grep -v 'chaosmeta_execns ' | wc -l", key, utils.RootName, utils.RootName))
 	if err != nil {
@@ -0,0 +1,201 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package process
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient"
+	"golang.org/x/sys/unix"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// io scheduling class of ioprio
+const (
+	IoClassNone = iota
+	IoClassRealtime
+	IoClassBestEffort
+	IoClassIdle
+
+	MaxIoLevel = 7
+	MinNice    = -20
+	MaxNice    = 19
+
+	ioprioClassShift = 13
+	ioprioWhoProcess = 1
+)
+
+// Priority is the cpu and io scheduling priority of a thread
+type Priority struct {
+	Nice    int `json:"nice"`
+	IoClass int `json:"io_class"`
+	IoLevel int `json:"io_level"`
+}
+
+// GetHostPidList convert the pids in the pid namespace of container to the pids in the pid namespace of host
+func GetHostPidList(ctx context.Context, cr, cId string, pidList []int) ([]int, error) {
+	if cr == "" {
+		return pidList, nil
+	}
+
+	client, err := crclient.GetClient(ctx, cr)
+	if err != nil {
+		return nil, fmt.Errorf("get %s client error: %s", cr, err.Error())
+	}
+
+	initPid, err := client.GetPidById(ctx, cId)
+	if err != nil {
+		return nil, fmt.Errorf("get pid of container[%s] error: %s", cId, err.Error())
+	}
+
+	pidNs, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", initPid))
+	if err != nil {
+		return nil, fmt.Errorf("get pid namespace of container[%s] error: %s", cId, err.Error())
+	}
+
+	entries, err := os.ReadDir("/proc")
+	if err != nil {
+		return nil, fmt.Errorf("read /proc error: %s", err.Error())
+	}
+
+	hostPidMap := make(map[int]int)
+	for _, entry := range entries {
+		hostPid, err := strconv.Atoi(entry.Name())
+		if err != nil {
+			continue
+		}
+
+		if ns, err := os.Readlink(fmt.Sprintf("/proc/%d/ns/pid", hostPid)); err != nil || ns != pidNs {
+			continue
+		}
+
+		if nsPid, err := getNsPid(hostPid); err == nil {
+			hostPidMap[nsPid] = hostPid
+		}
+	}
+
+	var hostPidList []int
+	for _, pid := range pidList {
+		hostPid, ok := hostPidMap[pid]
+		if !ok {
+			return nil, fmt.Errorf("pid[%d] is not found in container[%s]", pid, cId)
+		}
+		hostPidList = append(hostPidList, hostPid)
+	}
+
+	return hostPidList, nil
+}
+
+// getNsPid return the pid in the innermost pid namespace of the process
+func getNsPid(pid int) (int, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/status", pid))
+	if err != nil {
+		return -1, err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(line, "NSpid:") {
+			fields := strings.Fields(line)
+			return strconv.Atoi(fields[len(fields)-1])
+		}
+	}
+
+	return -1, fmt.Errorf("NSpid not found in status of process[%d]", pid)
+}
+
+// GetThreadList return the thread ids of the process, in the pid namespace of the current process
+func GetThreadList(pid int) ([]int, error) {
+	entries, err := os.ReadDir(fmt.Sprintf("/proc/%d/task", pid))
+	if err != nil {
+		return nil, err
+	}
+
+	var tidList []int
+	for _, entry := range entries {
+		if tid, err := strconv.Atoi(entry.Name()); err == nil {
+			tidList = append(tidList, tid)
+		}
+	}
+	sort.Ints(tidList)
+
+	return tidList, nil
+}
+
+// GetPriority nice and ioprio are the attributes of each thread on linux
+func GetPriority(tid int) (*Priority, error) {
+	// the raw getpriority syscall return "20 - nice"
+	prio, err := unix.Getpriority(unix.PRIO_PROCESS, tid)
+	if err != nil {
+		return nil, fmt.Errorf("get nice error: %s", err.Error())
+	}
+
+	ioprio, _, errno := unix.Syscall(unix.SYS_IOPRIO_GET, ioprioWhoProcess, uintptr(tid), 0)
+	if errno != 0 {
+		return nil, fmt.Errorf("get ioprio error: %s", errno.Error())
+	}
+
+	return &Priority{
+		Nice:    20 - prio,
+		IoClass: int(ioprio >> ioprioClassShift),
+		IoLevel: int(ioprio & (1<<ioprioClassShift - 1)),
+	}, nil
+}
+
+func SetNice(tid, nice int) error {
+	return unix.Setpriority(unix.PRIO_PROCESS, tid, nice)
+}
+
+// SetIoPriority the level of class none must be 0, ioprio_get may return a non-zero one which is derived from nice
+func SetIoPriority(tid, class, level int) error {
+	if class == IoClassNone {
+		level = 0
+	}
+
+	if _, _, errno := unix.Syscall(unix.SYS_IOPRIO_SET, ioprioWhoProcess, uintptr(tid), uintptr(class<<ioprioClassShift|level)); errno != 0 {
+		return errno
+	}
+
+	return nil
+}
+
+func GetAffinity(tid int) ([]int, error) {
+	var set unix.CPUSet
+	if err := unix.SchedGetaffinity(tid, &set); err != nil {
+		return nil, err
+	}
+
+	var cpuList []int
+	for cpu := 0; cpu < len(set)*64; cpu++ {
+		if set.IsSet(cpu) {
+			cpuList = append(cpuList, cpu)
+		}
+	}
+
+	return cpuList, nil
+}
+
+func SetAffinity(tid int, cpuList []int) error {
+	var set unix.CPUSet
+	for _, cpu := range cpuList {
+		set.Set(cpu)
+	}
+
+	return unix.SchedSetaffinity(tid, &set)
+}
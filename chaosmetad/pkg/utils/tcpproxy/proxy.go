@@ -0,0 +1,165 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tcpproxy
+
+import (
+	"errors"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+const (
+	DirectionUp   = "up"
+	DirectionDown = "down"
+	DirectionBoth = "both"
+
+	bufSize = 32 * 1024
+)
+
+// Toxics durations are in milliseconds and StallAfter < 0 means no stall.
+// latency, bandwidth and stall are applied to Direction, close is applied to the whole connection
+type Toxics struct {
+	Latency    int64  `json:"latency"`
+	Bandwidth  int64  `json:"bandwidth"`
+	StallAfter int64  `json:"stall_after"`
+	CloseAfter int64  `json:"close_after"`
+	Direction  string `json:"direction"`
+}
+
+// Serve accept the connections of l and proxy them to upstream until l is closed.
+// the errors are ignored, because the proxy runs in background and nobody reads its output after started
+func Serve(l net.Listener, dialer *net.Dialer, upstream string, t *Toxics) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			if errors.Is(err, net.ErrClosed) {
+				return
+			}
+			time.Sleep(100 * time.Millisecond)
+			continue
+		}
+
+		go Proxy(conn, dialer, upstream, t)
+	}
+}
+
+// Proxy forward the data between client and a new connection to upstream, and close both when any side is closed
+func Proxy(client net.Conn, dialer *net.Dialer, upstream string, t *Toxics) {
+	server, err := dialer.Dial("tcp", upstream)
+	if err != nil {
+		_ = client.Close()
+		return
+	}
+
+	var (
+		once    sync.Once
+		done    = make(chan struct{})
+		closeFn = func() {
+			once.Do(func() {
+				close(done)
+				_ = client.Close()
+				_ = server.Close()
+			})
+		}
+	)
+
+	if t.CloseAfter > 0 {
+		timer := time.AfterFunc(time.Duration(t.CloseAfter)*time.Millisecond, closeFn)
+		defer timer.Stop()
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		pipe(server, client, t, t.Direction == DirectionUp || t.Direction == DirectionBoth, done)
+		closeFn()
+	}()
+	go func() {
+		defer wg.Done()
+		pipe(client, server, t, t.Direction == DirectionDown || t.Direction == DirectionBoth, done)
+		closeFn()
+	}()
+	wg.Wait()
+}
+
+// pipe copy from src to dst, the toxics are applied in order: latency, stall, bandwidth
+func pipe(dst, src net.Conn, t *Toxics, apply bool, done chan struct{}) {
+	var (
+		buf   = make([]byte, bufSize)
+		first = true
+		total int64
+	)
+
+	if apply && t.Bandwidth > 0 && t.Bandwidth < bufSize {
+		buf = buf[:t.Bandwidth]
+	}
+
+	for {
+		n, err := src.Read(buf)
+		if n > 0 {
+			data := buf[:n]
+			if apply {
+				if first && t.Latency > 0 {
+					if !sleep(time.Duration(t.Latency)*time.Millisecond, done) {
+						return
+					}
+				}
+
+				if t.StallAfter >= 0 && total+int64(n) > t.StallAfter {
+					data = data[:t.StallAfter-total]
+					if len(data) > 0 {
+						if _, err := dst.Write(data); err != nil {
+							return
+						}
+					}
+					// hold the connection without forwarding data until it is closed
+					_, _ = io.Copy(io.Discard, src)
+					<-done
+					return
+				}
+
+				if t.Bandwidth > 0 {
+					if !sleep(time.Duration(int64(n)*int64(time.Second)/t.Bandwidth), done) {
+						return
+					}
+				}
+			}
+			first = false
+
+			if _, err := dst.Write(data); err != nil {
+				return
+			}
+			total += int64(len(data))
+		}
+
+		if err != nil {
+			return
+		}
+	}
+}
+
+func sleep(d time.Duration, done chan struct{}) bool {
+	select {
+	case <-time.After(d):
+		return true
+	case <-done:
+		return false
+	}
+}
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package tcpproxy
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"testing"
+	"time"
+)
+
+// startProxy start an echo server as the upstream, and a proxy with the toxics in front of it
+func startProxy(t *testing.T, toxics *Toxics) net.Conn {
+	sl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen server error: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = sl.Close() })
+	go func() {
+		for {
+			conn, err := sl.Accept()
+			if err != nil {
+				return
+			}
+			go func() {
+				_, _ = io.Copy(conn, conn)
+				_ = conn.Close()
+			}()
+		}
+	}()
+
+	pl, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen proxy error: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = pl.Close() })
+	go Serve(pl, &net.Dialer{Timeout: time.Second}, sl.Addr().String(), toxics)
+
+	conn, err := net.Dial("tcp", pl.Addr().String())
+	if err != nil {
+		t.Fatalf("dial proxy error: %s", err.Error())
+	}
+	t.Cleanup(func() { _ = conn.Close() })
+
+	return conn
+}
+
+// echo write data and read the same length back, return the cost and the data read before deadline
+func echo(t *testing.T, conn net.Conn, data []byte, deadline time.Duration) (time.Duration, []byte) {
+	start := time.Now()
+	if _, err := conn.Write(data); err != nil {
+		t.Fatalf("write error: %s", err.Error())
+	}
+
+	_ = conn.SetReadDeadline(start.Add(deadline))
+	buf := make([]byte, len(data))
+	n, _ := io.ReadFull(conn, buf)
+
+	return time.Since(start), buf[:n]
+}
+
+func TestProxy_None(t *testing.T) {
+	conn := startProxy(t, &Toxics{StallAfter: -1, Direction: DirectionBoth})
+	data := bytes.Repeat([]byte("a"), 100*1024)
+	if cost, re := echo(t, conn, data, 2*time.Second); !bytes.Equal(re, data) || cost > time.Second {
+		t.Fatalf("expect %d bytes in 1s, get %d bytes in %s", len(data), len(re), cost)
+	}
+}
+
+func TestProxy_Latency(t *testing.T) {
+	for _, direction := range []string{DirectionUp, DirectionDown} {
+		conn := startProxy(t, &Toxics{Latency: 300, StallAfter: -1, Direction: direction})
+		if cost, re := echo(t, conn, []byte("hello"), 2*time.Second); string(re) != "hello" || cost < 300*time.Millisecond {
+			t.Fatalf("direction[%s]: expect delay 300ms, get %q in %s", direction, re, cost)
+		}
+
+		// only the first read is delayed
+		if cost, re := echo(t, conn, []byte("world"), 2*time.Second); string(re) != "world" || cost > 200*time.Millisecond {
+			t.Fatalf("direction[%s]: expect no delay after first read, get %q in %s", direction, re, cost)
+		}
+	}
+}
+
+func TestProxy_Bandwidth(t *testing.T) {
+	conn := startProxy(t, &Toxics{Bandwidth: 10 * 1024, StallAfter: -1, Direction: DirectionDown})
+	data := bytes.Repeat([]byte("a"), 5*1024)
+	if cost, re := echo(t, conn, data, 3*time.Second); !bytes.Equal(re, data) || cost < 400*time.Millisecond {
+		t.Fatalf("expect %d bytes in about 500ms, get %d bytes in %s", len(data), len(re), cost)
+	}
+}
+
+func TestProxy_Stall(t *testing.T) {
+	conn := startProxy(t, &Toxics{StallAfter: 4, Direction: DirectionDown})
+	if _, re := echo(t, conn, []byte("hello"), 500*time.Millisecond); string(re) != "hell" {
+		t.Fatalf("expect \"hell\" before stall, get %q", re)
+	}
+
+	// the connection is held instead of closed
+	if _, err := conn.Write([]byte("world")); err != nil {
+		t.Fatalf("expect connection held, get write error: %s", err.Error())
+	}
+}
+
+func TestProxy_CloseAfter(t *testing.T) {
+	conn := startProxy(t, &Toxics{CloseAfter: 200, StallAfter: -1, Direction: DirectionDown})
+	if _, re := echo(t, conn, []byte("hello"), time.Second); string(re) != "hello" {
+		t.Fatalf("expect \"hello\" before close, get %q", re)
+	}
+
+	start := time.Now()
+	_ = conn.SetReadDeadline(start.Add(2 * time.Second))
+	if _, err := conn.Read(make([]byte, 1)); err != io.EOF {
+		t.Fatalf("expect EOF after close, get: %v", err)
+	}
+
+	if cost := time.Since(start); cost > time.Second {
+		t.Fatalf("expect close in about 200ms, get %s", cost)
+	}
+}
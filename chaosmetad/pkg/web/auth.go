@@ -0,0 +1,173 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/web/handler"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/web/model"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	ScopeRead  = "read"
+	ScopeWrite = "write"
+
+	RoleAdmin     = "admin"
+	RoleReadOnly  = "readonly"
+	RoleAnonymous = "anonymous"
+
+	HeaderAuthorization = "Authorization"
+	HeaderTraceId       = "X-Trace-Id"
+	bearerPrefix        = "Bearer "
+
+	maxTraceBodyBytes = 1 << 20
+)
+
+// AuthConfig token "admin" can access all routes, token "readonly" can only access routes with scope "read".
+// If no token is configured, authentication is disabled
+type AuthConfig struct {
+	Token         string
+	ReadOnlyToken string
+}
+
+func (c *AuthConfig) IsEnabled() bool {
+	return c != nil && (c.Token != "" || c.ReadOnlyToken != "")
+}
+
+// getRole return the role of the request's bearer token
+func (c *AuthConfig) getRole(r *http.Request) (string, error) {
+	if !c.IsEnabled() {
+		return RoleAnonymous, nil
+	}
+
+	authStr := r.Header.Get(HeaderAuthorization)
+	if !strings.HasPrefix(authStr, bearerPrefix) {
+		return RoleAnonymous, fmt.Errorf("missing bearer token")
+	}
+
+	token := strings.TrimSpace(strings.TrimPrefix(authStr, bearerPrefix))
+	if c.Token != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.Token)) == 1 {
+		return RoleAdmin, nil
+	}
+
+	if c.ReadOnlyToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.ReadOnlyToken)) == 1 {
+		return RoleReadOnly, nil
+	}
+
+	return RoleAnonymous, fmt.Errorf("invalid bearer token")
+}
+
+func isRoleAllowed(role, scope string) bool {
+	switch role {
+	case RoleAdmin, RoleAnonymous:
+		return true
+	case RoleReadOnly:
+		return scope == ScopeRead
+	default:
+		return false
+	}
+}
+
+// getCaller return caller identity: [client cert common name/]role
+func getCaller(r *http.Request, role string) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		if cn := r.TLS.PeerCertificates[0].Subject.CommonName; cn != "" {
+			return fmt.Sprintf("%s/%s", cn, role)
+		}
+	}
+
+	return role
+}
+
+// getRequestTraceId get trace id from header first, and then from the "trace_id" of url query or json body
+func getRequestTraceId(r *http.Request) string {
+	if traceId := r.Header.Get(HeaderTraceId); traceId != "" {
+		return traceId
+	}
+
+	if traceId := r.URL.Query().Get("trace_id"); traceId != "" {
+		return traceId
+	}
+
+	if r.Body == nil || r.Method != http.MethodPost {
+		return ""
+	}
+
+	bodyBytes, err := io.ReadAll(io.LimitReader(r.Body, maxTraceBodyBytes))
+	if err != nil {
+		return ""
+	}
+	r.Body = io.NopCloser(io.MultiReader(bytes.NewReader(bodyBytes), r.Body))
+
+	var traceReq struct {
+		TraceId string `json:"trace_id"`
+	}
+	if err := json.Unmarshal(bodyBytes, &traceReq); err != nil {
+		return ""
+	}
+
+	return traceReq.TraceId
+}
+
+// Auth authenticate and authorize every request, and record an audit log with caller identity and trace id
+func Auth(ctx context.Context, inner http.Handler, route Route, conf *AuthConfig) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reqCtx := utils.GetCtxWithTraceId(ctx, getRequestTraceId(r))
+		logger := log.GetLogger(reqCtx)
+
+		role, err := conf.getRole(r)
+		caller := getCaller(r, role)
+		if err != nil {
+			logger.Warnf("[audit] caller: %s, remote: %s, %s %s %s, denied: %s", caller, r.RemoteAddr, r.Method, r.RequestURI, route.Name, err.Error())
+			writeAuthErr(reqCtx, w, http.StatusUnauthorized, err.Error())
+			return
+		}
+
+		if !isRoleAllowed(role, route.Scope) {
+			msg := fmt.Sprintf("role[%s] has no permission of scope[%s]", role, route.Scope)
+			logger.Warnf("[audit] caller: %s, remote: %s, %s %s %s, denied: %s", caller, r.RemoteAddr, r.Method, r.RequestURI, route.Name, msg)
+			writeAuthErr(reqCtx, w, http.StatusForbidden, msg)
+			return
+		}
+
+		logger.Infof("[audit] caller: %s, remote: %s, %s %s %s, allowed", caller, r.RemoteAddr, r.Method, r.RequestURI, route.Name)
+		inner.ServeHTTP(w, r)
+	})
+}
+
+func writeAuthErr(ctx context.Context, w http.ResponseWriter, status int, msg string) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", "Bearer")
+	}
+	w.WriteHeader(status)
+	handler.WriteResponse(ctx, w, &model.CommonResponse{
+		Code:    errutil.AuthErr,
+		Message: fmt.Sprintf("auth error: %s", msg),
+		TraceId: utils.GetTraceId(ctx),
+	})
+}
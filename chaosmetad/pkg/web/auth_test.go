@@ -0,0 +1,87 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package web
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAuth(t *testing.T) {
+	conf := &AuthConfig{
+		Token:         "admin-token",
+		ReadOnlyToken: "read-token",
+	}
+
+	tests := []struct {
+		name   string
+		conf   *AuthConfig
+		scope  string
+		token  string
+		status int
+	}{
+		{name: "disabled", conf: &AuthConfig{}, scope: ScopeWrite, token: "", status: http.StatusOK},
+		{name: "no token", conf: conf, scope: ScopeRead, token: "", status: http.StatusUnauthorized},
+		{name: "wrong token", conf: conf, scope: ScopeRead, token: "other", status: http.StatusUnauthorized},
+		{name: "admin write", conf: conf, scope: ScopeWrite, token: "admin-token", status: http.StatusOK},
+		{name: "admin read", conf: conf, scope: ScopeRead, token: "admin-token", status: http.StatusOK},
+		{name: "readonly read", conf: conf, scope: ScopeRead, token: "read-token", status: http.StatusOK},
+		{name: "readonly write", conf: conf, scope: ScopeWrite, token: "read-token", status: http.StatusForbidden},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			inner := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.WriteHeader(http.StatusOK)
+			})
+			h := Auth(context.Background(), inner, Route{Name: "test", Scope: tt.scope}, tt.conf)
+
+			req := httptest.NewRequest(http.MethodPost, "/v1/test", strings.NewReader(`{"trace_id":"t1"}`))
+			if tt.token != "" {
+				req.Header.Set(HeaderAuthorization, bearerPrefix+tt.token)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			if rec.Code != tt.status {
+				t.Errorf("Auth() status = %d, want %d", rec.Code, tt.status)
+			}
+		})
+	}
+}
+
+func Test_getRequestTraceId(t *testing.T) {
+	req := httptest.NewRequest(http.MethodPost, "/v1/test", strings.NewReader(`{"trace_id":"t1","uid":"u1"}`))
+	if got := getRequestTraceId(req); got != "t1" {
+		t.Errorf("getRequestTraceId() = %s, want t1", got)
+	}
+
+	body, _ := io.ReadAll(req.Body)
+	if string(body) != `{"trace_id":"t1","uid":"u1"}` {
+		t.Errorf("request body is not restored: %s", string(body))
+	}
+
+	req = httptest.NewRequest(http.MethodGet, "/v1/version", nil)
+	req.Header.Set(HeaderTraceId, "t2")
+	if got := getRequestTraceId(req); got != "t2" {
+		t.Errorf("getRequestTraceId() = %s, want t2", got)
+	}
+}
@@ -0,0 +1,47 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/web/model"
+	"net/http"
+)
+
+func CatalogGet(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+	w.WriteHeader(http.StatusOK)
+	ctx := context.Background()
+
+	catalog, err := injector.GetCatalog()
+	if err != nil {
+		WriteResponse(ctx, w, &model.CatalogResponse{
+			Code:    errutil.InternalErr,
+			Message: fmt.Sprintf("get catalog error: %s", err.Error()),
+		})
+		return
+	}
+
+	WriteResponse(ctx, w, &model.CatalogResponse{
+		Code:    errutil.NoErr,
+		Message: "success",
+		Data:    catalog,
+	})
+}
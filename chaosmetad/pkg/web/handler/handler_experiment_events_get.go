@@ -0,0 +1,95 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package handler
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/event"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/log"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/errutil"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/web/model"
+	"net/http"
+	"time"
+)
+
+const eventsHeartbeatInterval = 15 * time.Second
+
+// ExperimentEventsGet server-sent events of experiments, support query filter: uid, target, fault
+func ExperimentEventsGet(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	ctx := utils.GetCtxWithTraceId(context.Background(), query.Get("trace_id"))
+	logger := log.GetLogger(ctx)
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		w.Header().Set("Content-Type", "application/json; charset=UTF-8")
+		w.WriteHeader(http.StatusInternalServerError)
+		WriteResponse(ctx, w, &model.CommonResponse{
+			Code:    errutil.InternalErr,
+			Message: "streaming is not supported",
+			TraceId: utils.GetTraceId(ctx),
+		})
+		return
+	}
+
+	events, cancel := event.GetBus().Subscribe(&event.Filter{
+		Uid:    query.Get("uid"),
+		Target: query.Get("target"),
+		Fault:  query.Get("fault"),
+	})
+	defer cancel()
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("Connection", "keep-alive")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(eventsHeartbeatInterval)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-heartbeat.C:
+			if _, err := fmt.Fprint(w, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		case e, ok := <-events:
+			if !ok {
+				return
+			}
+
+			data, err := json.Marshal(e)
+			if err != nil {
+				logger.Errorf("event marshal error: %s", err.Error())
+				continue
+			}
+
+			if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data); err != nil {
+				logger.Debugf("write event error: %s", err.Error())
+				return
+			}
+		}
+
+		flusher.Flush()
+	}
+}
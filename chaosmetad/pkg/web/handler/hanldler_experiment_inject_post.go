@@ -64,6 +64,13 @@ func ExperimentInjectPost(w http.ResponseWriter, r *http.Request) {
 			}, i.GetArgs(), i.GetRuntime()); err != nil {
 				injectRes = getExperimentInjectPostResponse(ctx, errutil.BadArgsErr, fmt.Sprintf("args load error: %s", err.Error()), nil)
 			} else {
+				i.SetCommonArgs(&injector.BaseInfo{
+					PodNamespace:  injectReq.PodNamespace,
+					PodName:       injectReq.PodName,
+					PodUid:        injectReq.PodUid,
+					ContainerName: injectReq.ContainerName,
+					AllowOverlap:  injectReq.AllowOverlap,
+				})
 				code, msg := injector.ProcessInject(ctx, i)
 				if code == errutil.NoErr {
 					exp, err := i.OptionToExp(i.GetArgs(), i.GetRuntime())
@@ -24,6 +24,11 @@ type InjectRequest struct {
 	Args             string `json:"args"`
 	ContainerId      string `json:"container_id"`
 	ContainerRuntime string `json:"container_runtime"`
+	PodNamespace     string `json:"pod_namespace"`
+	PodName          string `json:"pod_name"`
+	PodUid           string `json:"pod_uid"`
+	ContainerName    string `json:"container_name"`
+	AllowOverlap     bool   `json:"allow_overlap"`
 	TraceId          string `json:"trace_id"`
 	Uid              string `json:"uid"`
 }
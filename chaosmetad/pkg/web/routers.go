@@ -25,6 +25,7 @@ import (
 	"strings"
 
 	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
 type Route struct {
@@ -32,11 +33,12 @@ type Route struct {
 	Method      string
 	Pattern     string
 	HandlerFunc http.HandlerFunc
+	Scope       string
 }
 
 type Routes []Route
 
-func NewRouter(ctx context.Context, isPprof bool) *mux.Router {
+func NewRouter(ctx context.Context, isPprof bool, authConf *AuthConfig) *mux.Router {
 	router := mux.NewRouter().StrictSlash(true)
 
 	if isPprof {
@@ -46,6 +48,7 @@ func NewRouter(ctx context.Context, isPprof bool) *mux.Router {
 	for _, route := range routes {
 		var handler http.Handler
 		handler = route.HandlerFunc
+		handler = Auth(ctx, handler, route, authConf)
 		handler = Logger(ctx, handler, route.Name)
 
 		router.
@@ -68,6 +71,7 @@ var routes = Routes{
 		"GET",
 		"/v1/",
 		Index,
+		ScopeRead,
 	},
 
 	Route{
@@ -75,6 +79,7 @@ var routes = Routes{
 		strings.ToUpper("Post"),
 		"/v1/experiment/inject",
 		handler.ExperimentInjectPost,
+		ScopeWrite,
 	},
 
 	Route{
@@ -82,6 +87,7 @@ var routes = Routes{
 		strings.ToUpper("Post"),
 		"/v1/experiment/query",
 		handler.ExperimentQueryPost,
+		ScopeRead,
 	},
 
 	Route{
@@ -89,6 +95,31 @@ var routes = Routes{
 		strings.ToUpper("Post"),
 		"/v1/experiment/recover",
 		handler.ExperimentRecoverPost,
+		ScopeWrite,
+	},
+
+	Route{
+		"ExperimentEventsGet",
+		strings.ToUpper("Get"),
+		"/v1/experiment/events",
+		handler.ExperimentEventsGet,
+		ScopeRead,
+	},
+
+	Route{
+		"CatalogGet",
+		strings.ToUpper("Get"),
+		"/v1/catalog",
+		handler.CatalogGet,
+		ScopeRead,
+	},
+
+	Route{
+		"MetricsGet",
+		strings.ToUpper("Get"),
+		"/metrics",
+		promhttp.Handler().ServeHTTP,
+		ScopeRead,
 	},
 
 	Route{
@@ -96,6 +127,7 @@ var routes = Routes{
 		strings.ToUpper("Get"),
 		"/v1/version",
 		handler.VersionGet,
+		ScopeRead,
 	},
 }
 
@@ -105,6 +137,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/",
 		pprof.Index,
+		ScopeWrite,
 	},
 
 	Route{
@@ -112,6 +145,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/profile",
 		pprof.Profile,
+		ScopeWrite,
 	},
 
 	Route{
@@ -119,6 +153,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/heap",
 		pprof.Handler("heap").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -126,6 +161,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/block",
 		pprof.Handler("block").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -133,6 +169,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/goroutine",
 		pprof.Handler("goroutine").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -140,6 +177,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/allocs",
 		pprof.Handler("allocs").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -147,6 +185,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/cmdline",
 		pprof.Cmdline,
+		ScopeWrite,
 	},
 
 	Route{
@@ -154,6 +193,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/threadcreate",
 		pprof.Handler("threadcreate").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -161,6 +201,7 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/mutex",
 		pprof.Handler("mutex").ServeHTTP,
+		ScopeWrite,
 	},
 
 	Route{
@@ -168,5 +209,6 @@ var pprofRoutes = Routes{
 		strings.ToUpper("Get"),
 		"/debug/pprof/trace",
 		pprof.Trace,
+		ScopeWrite,
 	},
 }
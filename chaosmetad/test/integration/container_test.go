@@ -0,0 +1,204 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/crclient"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/container"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/kernel"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+)
+
+// fakeDocker serves the docker api used by the container faults on the socket of docker
+type fakeDocker struct {
+	mutex      sync.Mutex
+	containers []fakeContainer
+	killed     []string
+}
+
+type fakeContainer struct {
+	Id     string            `json:"Id"`
+	Labels map[string]string `json:"Labels"`
+}
+
+func (d *fakeDocker) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	path := r.URL.Path[strings.Index(r.URL.Path, "/containers/"):]
+	items := strings.Split(strings.Trim(path, "/"), "/")
+	switch {
+	case path == "/containers/json":
+		filters := map[string]map[string]bool{}
+		_ = json.Unmarshal([]byte(r.URL.Query().Get("filters")), &filters)
+		re := make([]fakeContainer, 0)
+		for _, c := range d.containers {
+			if matchLabels(c.Labels, filters["label"]) {
+				re = append(re, c)
+			}
+		}
+		_ = json.NewEncoder(w).Encode(re)
+	case len(items) == 3 && items[2] == "json" && d.find(items[1]) != nil:
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{
+			"Id":         items[1],
+			"State":      map[string]interface{}{"Pid": os.Getpid(), "Running": true},
+			"HostConfig": map[string]interface{}{"Runtime": "runc"},
+		})
+	case len(items) == 3 && items[2] == "kill" && d.find(items[1]) != nil:
+		d.killed = append(d.killed, items[1])
+		w.WriteHeader(http.StatusNoContent)
+	default:
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"message":"no such container"}`))
+	}
+}
+
+func (d *fakeDocker) find(id string) *fakeContainer {
+	for i := range d.containers {
+		if d.containers[i].Id == id {
+			return &d.containers[i]
+		}
+	}
+
+	return nil
+}
+
+func (d *fakeDocker) getKilled() []string {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	return append([]string{}, d.killed...)
+}
+
+func matchLabels(labels map[string]string, filter map[string]bool) bool {
+	for kv := range filter {
+		items := strings.SplitN(kv, "=", 2)
+		if len(items) != 2 || labels[items[0]] != items[1] {
+			return false
+		}
+	}
+
+	return true
+}
+
+// startFakeDocker hides the runtime sockets of the host by a tmpfs, and serves a fake docker on the docker socket
+func startFakeDocker(t *testing.T, containers []fakeContainer) *fakeDocker {
+	t.Helper()
+	for _, dir := range []string{"/run", "/var/run"} {
+		if info, err := os.Lstat(dir); err != nil || info.Mode()&os.ModeSymlink != 0 {
+			continue
+		}
+
+		if err := syscall.Mount("tmpfs", dir, "tmpfs", 0, ""); err != nil {
+			t.Fatalf("mount tmpfs on %s error: %s", dir, err.Error())
+		}
+		dir := dir
+		t.Cleanup(func() { _ = syscall.Unmount(dir, syscall.MNT_DETACH) })
+	}
+
+	l, err := net.Listen("unix", crclient.GetSocketPath(crclient.CrDocker))
+	if err != nil {
+		t.Fatalf("listen docker socket error: %s", err.Error())
+	}
+
+	d := &fakeDocker{containers: containers}
+	s := &http.Server{Handler: d}
+	go func() { _ = s.Serve(l) }()
+	t.Cleanup(func() { _ = s.Close() })
+
+	return d
+}
+
+func TestContainerKillByPod(t *testing.T) {
+	requireSandbox(t)
+	d := startFakeDocker(t, []fakeContainer{
+		{Id: "sandbox01", Labels: map[string]string{crclient.LabelPodNamespace: "default", crclient.LabelPodName: "web-0", crclient.LabelContainerName: crclient.SandboxContainerName}},
+		{Id: "app01", Labels: map[string]string{crclient.LabelPodNamespace: "default", crclient.LabelPodName: "web-0", crclient.LabelContainerName: "app"}},
+		{Id: "other01", Labels: map[string]string{crclient.LabelPodNamespace: "default", crclient.LabelPodName: "web-1", crclient.LabelContainerName: "app"}},
+	})
+
+	for _, c := range []struct {
+		name   string
+		info   *injector.BaseInfo
+		expect string
+	}{
+		{"pod name", &injector.BaseInfo{PodName: "web-0"}, "app01"},
+		{"container id without runtime", &injector.BaseInfo{ContainerId: "other01"}, "other01"},
+	} {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			i, err := injector.NewInjector(container.TargetContainer, container.FaultContainerKill)
+			if err != nil {
+				t.Fatalf("new injector error: %s", err.Error())
+			}
+
+			i.SetCommonArgs(c.info)
+			i.SetDefault()
+			if err := i.Validator(ctx); err != nil {
+				t.Fatalf("validator error: %s", err.Error())
+			}
+
+			if info := i.GetInfo(); info.ContainerRuntime != crclient.CrDocker || info.ContainerId != c.expect {
+				t.Fatalf("expect container %s/%s, get: %s/%s", crclient.CrDocker, c.expect, info.ContainerRuntime, info.ContainerId)
+			}
+
+			if err := i.Inject(ctx); err != nil {
+				t.Fatalf("inject error: %s", err.Error())
+			}
+
+			if killed := d.getKilled(); len(killed) == 0 || killed[len(killed)-1] != c.expect {
+				t.Errorf("expect container %s killed, get: %v", c.expect, killed)
+			}
+		})
+	}
+
+	i, _ := injector.NewInjector(container.TargetContainer, container.FaultContainerKill)
+	i.SetDefault()
+	if err := i.Validator(context.Background()); err == nil || !strings.Contains(err.Error(), "pod information") {
+		t.Errorf("expect error without container id and pod information, get: %v", fmt.Sprint(err))
+	}
+}
+
+// TestNodeFaultByPod the node level faults must reject the container resolved from pod information
+func TestNodeFaultByPod(t *testing.T) {
+	requireSandbox(t)
+	startFakeDocker(t, []fakeContainer{
+		{Id: "app01", Labels: map[string]string{crclient.LabelPodNamespace: "default", crclient.LabelPodName: "web-0", crclient.LabelContainerName: "app"}},
+	})
+
+	for _, fault := range []string{kernel.FaultKernelFdfull, kernel.FaultKernelNproc} {
+		i, err := injector.NewInjector(kernel.TargetKernel, fault)
+		if err != nil {
+			t.Fatalf("new injector error: %s", err.Error())
+		}
+
+		i.SetCommonArgs(&injector.BaseInfo{PodName: "web-0"})
+		i.SetDefault()
+		if err := i.Validator(context.Background()); err == nil || !strings.Contains(err.Error(), "not support in container") {
+			t.Errorf("%s: expect not support in container, get: %v", fault, fmt.Sprint(err))
+		}
+	}
+}
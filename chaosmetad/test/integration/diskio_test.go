@@ -0,0 +1,210 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"fmt"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/diskio"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+const dmPrefix = "chaosmeta_diskio_"
+
+// requireDmTarget skips the test if the kernel does not support the device-mapper target
+func requireDmTarget(t *testing.T, target string) {
+	t.Helper()
+	re, err := shell("dmsetup targets")
+	if err != nil {
+		t.Skipf("device-mapper is not supported: %s", err.Error())
+	}
+
+	for _, line := range strings.Split(re, "\n") {
+		if strings.HasPrefix(line, target+" ") {
+			return
+		}
+	}
+
+	t.Skipf("device-mapper target %s is not supported", target)
+}
+
+// newLoopDevice creates a loop device backed by a temporary file
+func newLoopDevice(t *testing.T) string {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "disk.img")
+	if err := os.WriteFile(file, make([]byte, 16<<20), 0644); err != nil {
+		t.Fatalf("create backing file error: %s", err.Error())
+	}
+
+	dev, err := shell(fmt.Sprintf("losetup -f --show %s", file))
+	if err != nil {
+		t.Skipf("create loop device error: %s", err.Error())
+	}
+
+	dev = strings.TrimSpace(dev)
+	t.Cleanup(func() {
+		_, _ = shell(fmt.Sprintf("losetup -d %s", dev))
+	})
+
+	return dev
+}
+
+// getInjectedDm return the device created by the experiment
+func getInjectedDm() (string, error) {
+	re, err := shell(fmt.Sprintf("dmsetup ls | awk '{print $1}' | grep '^%s' || true", dmPrefix))
+	if err != nil {
+		return "", err
+	}
+
+	if name := strings.TrimSpace(re); name != "" {
+		return fmt.Sprintf("/dev/mapper/%s", name), nil
+	}
+
+	return "", nil
+}
+
+// ddCost return the cost of a direct I/O to the device
+func ddCost(dev string, write bool) (time.Duration, error) {
+	cmd := fmt.Sprintf("dd if=%s of=/dev/null bs=4k count=1 iflag=direct", dev)
+	if write {
+		cmd = fmt.Sprintf("dd if=/dev/zero of=%s bs=4k count=1 oflag=direct", dev)
+	}
+
+	start := time.Now()
+	_, err := shell(cmd)
+	return time.Since(start), err
+}
+
+func checkDdCost(getDev func() (string, error), write bool, min time.Duration, fail bool) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		dev, err := getDev()
+		if err != nil {
+			return err
+		}
+
+		if dev == "" {
+			return fmt.Errorf("injected device not found")
+		}
+
+		cost, err := ddCost(dev, write)
+		if fail {
+			if err == nil {
+				return fmt.Errorf("expect I/O of %s to fail", dev)
+			}
+			return nil
+		}
+
+		if err != nil {
+			return fmt.Errorf("I/O of %s error: %s", dev, err.Error())
+		}
+
+		if cost < min {
+			return fmt.Errorf("I/O of %s cost %s, expect at least %s", dev, cost, min)
+		}
+
+		return nil
+	}
+}
+
+func checkNoInjectedDm(t *testing.T) error {
+	dev, err := getInjectedDm()
+	if err != nil {
+		return err
+	}
+
+	if dev != "" {
+		return fmt.Errorf("device %s is not removed", dev)
+	}
+
+	return nil
+}
+
+func TestDiskIODelay(t *testing.T) {
+	requireSandbox(t)
+	requireDmTarget(t, "delay")
+
+	dev := newLoopDevice(t)
+	lvName := fmt.Sprintf("%s-lv", namePrefix)
+	lvDev := fmt.Sprintf("/dev/mapper/%s", lvName)
+
+	var cases = []testCase{
+		{
+			Name:   "delay without delay args",
+			Target: "diskio", Fault: "delay",
+			Args:  map[string]interface{}{"device": dev},
+			Error: true,
+		},
+		{
+			Name:   "delay write of loop device",
+			Target: "diskio", Fault: "delay",
+			Args:         map[string]interface{}{"device": dev, "write_delay": "200ms"},
+			Check:        checkDdCost(getInjectedDm, true, 200*time.Millisecond, false),
+			CheckRecover: checkNoInjectedDm,
+		},
+		{
+			Name:   "delay read of device-mapper device in place",
+			Target: "diskio", Fault: "delay",
+			Args: map[string]interface{}{"device": lvDev, "read_delay": "200ms"},
+			Prepare: func(t *testing.T) error {
+				if _, err := shell(fmt.Sprintf("dmsetup create %s --table '0 32768 linear %s 0'", lvName, dev)); err != nil {
+					return err
+				}
+
+				t.Cleanup(func() {
+					_, _ = shell(fmt.Sprintf("dmsetup remove %s", lvName))
+				})
+				return nil
+			},
+			Check: checkDdCost(func() (string, error) { return lvDev, nil }, false, 200*time.Millisecond, false),
+			CheckRecover: func(t *testing.T) error {
+				table, err := shell(fmt.Sprintf("dmsetup table %s", lvName))
+				if err != nil {
+					return err
+				}
+
+				if !strings.HasPrefix(strings.TrimSpace(table), "0 32768 linear ") {
+					return fmt.Errorf("table of %s is not restored: %s", lvName, table)
+				}
+
+				return checkNoInjectedDm(t)
+			},
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func TestDiskIODelayError(t *testing.T) {
+	requireSandbox(t)
+	requireDmTarget(t, "flakey")
+
+	dev := newLoopDevice(t)
+	var cases = []testCase{
+		{
+			Name:   "error write of loop device",
+			Target: "diskio", Fault: "delay",
+			Args:         map[string]interface{}{"device": dev, "mode": "error", "error_io": "write"},
+			Check:        checkDdCost(getInjectedDm, true, 0, true),
+			CheckRecover: checkNoInjectedDm,
+		},
+	}
+
+	runCases(t, cases)
+}
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"fmt"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/dns"
+	"os"
+	"strings"
+	"testing"
+)
+
+const (
+	testDomain   = "cmtest.chaosmeta.io"
+	testDomainIp = "10.199.0.10"
+	testServerIp = "10.199.0.53"
+)
+
+// fileSnapshot saves the content of file before inject, and checks it is restored after recover
+type fileSnapshot struct {
+	path    string
+	content string
+}
+
+func (s *fileSnapshot) save(t *testing.T) error {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	s.content = string(content)
+	return nil
+}
+
+func (s *fileSnapshot) checkRestored(t *testing.T) error {
+	content, err := os.ReadFile(s.path)
+	if err != nil {
+		return err
+	}
+
+	if string(content) != s.content {
+		return fmt.Errorf("%s is not restored, expect: %q, actual: %q", s.path, s.content, string(content))
+	}
+
+	return nil
+}
+
+func checkFileLine(path string, key string, exist bool) error {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range strings.Split(string(content), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), key) {
+			if !exist {
+				return fmt.Errorf("line start with \"%s\" still in %s", key, path)
+			}
+			return nil
+		}
+	}
+
+	if exist {
+		return fmt.Errorf("line start with \"%s\" not found in %s", key, path)
+	}
+
+	return nil
+}
+
+func appendLine(path, line string) error {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.WriteString(line + "\n")
+	return err
+}
+
+func checkResolve(domain, ip string) error {
+	if _, err := shell("which getent"); err != nil {
+		return nil
+	}
+
+	re, err := shell(fmt.Sprintf("getent hosts %s", domain))
+	if err != nil {
+		return fmt.Errorf("resolve %s error: %s", domain, err.Error())
+	}
+
+	if !strings.Contains(re, ip) {
+		return fmt.Errorf("%s resolved to %s, expect %s", domain, re, ip)
+	}
+
+	return nil
+}
+
+func TestDNSRecord(t *testing.T) {
+	requireSandbox(t)
+
+	hosts := &fileSnapshot{path: "/etc/hosts"}
+	var cases = []testCase{
+		{
+			Name:   "add without ip",
+			Target: "dns", Fault: "record",
+			Args:  map[string]interface{}{"domain": testDomain},
+			Error: true,
+		},
+		{
+			Name:   "add",
+			Target: "dns", Fault: "record",
+			Args:    map[string]interface{}{"domain": testDomain, "ip": testDomainIp},
+			Prepare: hosts.save,
+			Check: func(t *testing.T) error {
+				if err := checkFileLine(hosts.path, testDomainIp, true); err != nil {
+					return err
+				}
+				return checkResolve(testDomain, testDomainIp)
+			},
+			CheckRecover: hosts.checkRestored,
+		},
+		{
+			Name:   "delete",
+			Target: "dns", Fault: "record",
+			Args: map[string]interface{}{"domain": testDomain, "mode": "delete"},
+			Prepare: func(t *testing.T) error {
+				if err := appendLine(hosts.path, fmt.Sprintf("%s %s", testDomainIp, testDomain)); err != nil {
+					return err
+				}
+				return hosts.save(t)
+			},
+			Check: func(t *testing.T) error {
+				return checkFileLine(hosts.path, testDomainIp, false)
+			},
+			CheckRecover: hosts.checkRestored,
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func TestDNSServer(t *testing.T) {
+	requireSandbox(t)
+
+	resolv := &fileSnapshot{path: "/etc/resolv.conf"}
+	var cases = []testCase{
+		{
+			Name:   "add without ip",
+			Target: "dns", Fault: "server",
+			Args:  map[string]interface{}{},
+			Error: true,
+		},
+		{
+			Name:   "add",
+			Target: "dns", Fault: "server",
+			Args:    map[string]interface{}{"ip": testServerIp},
+			Prepare: resolv.save,
+			Check: func(t *testing.T) error {
+				return checkFileLine(resolv.path, fmt.Sprintf("nameserver %s", testServerIp), true)
+			},
+			CheckRecover: resolv.checkRestored,
+		},
+		{
+			Name:   "delete",
+			Target: "dns", Fault: "server",
+			Args: map[string]interface{}{"ip": testServerIp, "mode": "delete"},
+			Prepare: func(t *testing.T) error {
+				if err := appendLine(resolv.path, fmt.Sprintf("nameserver %s", testServerIp)); err != nil {
+					return err
+				}
+				return resolv.save(t)
+			},
+			Check: func(t *testing.T) error {
+				return checkFileLine(resolv.path, fmt.Sprintf("nameserver %s", testServerIp), false)
+			},
+			CheckRecover: resolv.checkRestored,
+		},
+	}
+
+	runCases(t, cases)
+}
@@ -0,0 +1,21 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+// Package integration runs the Inject -> verify -> Recover -> verify cycle of injectors in-process.
+// The test binary re-executes itself in throwaway mount, network and pid namespaces, so the
+// faults which modify host resources (tc rules, /etc/hosts, cgroups and so on) never touch the real host.
+// It needs root on linux, run with: go test ./test/integration/ -v
+package integration
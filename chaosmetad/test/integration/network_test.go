@@ -0,0 +1,214 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"fmt"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/network"
+	"regexp"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+func checkQdisc(dev string, keys ...string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		re, err := shell(fmt.Sprintf("tc qdisc show dev %s", dev))
+		if err != nil {
+			return err
+		}
+
+		for _, key := range keys {
+			if !strings.Contains(re, key) {
+				return fmt.Errorf("qdisc of %s not contains \"%s\": %s", dev, key, re)
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkNoRootQdisc(dev string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		re, err := shell(fmt.Sprintf("tc qdisc show dev %s", dev))
+		if err != nil {
+			return err
+		}
+
+		for _, qdisc := range []string{"netem", "prio", "htb"} {
+			if strings.Contains(re, qdisc) {
+				return fmt.Errorf("tc rule of %s is not cleared: %s", dev, re)
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkFilter(dev string, key string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		if err := checkQdisc(dev, "prio")(t); err != nil {
+			return err
+		}
+
+		re, err := shell(fmt.Sprintf("tc filter show dev %s", dev))
+		if err != nil {
+			return err
+		}
+
+		if !strings.Contains(re, key) {
+			return fmt.Errorf("filter of %s not contains \"%s\": %s", dev, key, re)
+		}
+
+		return nil
+	}
+}
+
+// getPingRtt return the average rtt(ms) of ping
+func getPingRtt(ip string) (float64, error) {
+	re, err := shell(fmt.Sprintf("ping -c 3 -i 0.2 -W 3 %s", ip))
+	if err != nil {
+		return -1, fmt.Errorf("ping %s error: %s", ip, err.Error())
+	}
+
+	match := regexp.MustCompile(`= [\d.]+/([\d.]+)/`).FindStringSubmatch(re)
+	if len(match) != 2 {
+		return -1, fmt.Errorf("unexpected ping output: %s", re)
+	}
+
+	return strconv.ParseFloat(match[1], 64)
+}
+
+func checkRtt(ip string, minMs, maxMs float64) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		if _, err := shell("which ping"); err != nil {
+			t.Log("ping not found, skip rtt check")
+			return nil
+		}
+
+		rtt, err := getPingRtt(ip)
+		if err != nil {
+			return err
+		}
+
+		if rtt < minMs || rtt > maxMs {
+			return fmt.Errorf("rtt[%.3fms] of %s not in [%.0fms, %.0fms]", rtt, ip, minMs, maxMs)
+		}
+
+		return nil
+	}
+}
+
+func TestNetworkNetem(t *testing.T) {
+	requireSandbox(t)
+	requireQdisc(t, "netem")
+	dev, peerIp := newVethPair(t, 1)
+
+	var cases = []testCase{
+		{
+			Name:   "delay without interface",
+			Target: "network", Fault: "delay",
+			Args:  map[string]interface{}{"latency": "100ms"},
+			Error: true,
+		},
+		{
+			Name:   "delay",
+			Target: "network", Fault: "delay",
+			Args: map[string]interface{}{"interface": dev, "latency": "100ms"},
+			Check: func(t *testing.T) error {
+				if err := checkQdisc(dev, "netem", "delay 100")(t); err != nil {
+					return err
+				}
+				return checkRtt(peerIp, 100, 1000)(t)
+			},
+			CheckRecover: func(t *testing.T) error {
+				if err := checkNoRootQdisc(dev)(t); err != nil {
+					return err
+				}
+				return checkRtt(peerIp, 0, 100)(t)
+			},
+		},
+		{
+			Name:   "delay with dst ip filter",
+			Target: "network", Fault: "delay",
+			Args:         map[string]interface{}{"interface": dev, "latency": "100ms", "dst_ip": peerIp},
+			Check:        checkFilter(dev, "match"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+		{
+			Name:   "loss",
+			Target: "network", Fault: "loss",
+			Args:         map[string]interface{}{"interface": dev, "percent": 50},
+			Check:        checkQdisc(dev, "netem", "loss 50%"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+		{
+			Name:   "loss with dst port filter",
+			Target: "network", Fault: "loss",
+			Args:         map[string]interface{}{"interface": dev, "percent": 50, "dst_port": "8080"},
+			Check:        checkFilter(dev, "match"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+		{
+			Name:   "corrupt",
+			Target: "network", Fault: "corrupt",
+			Args:         map[string]interface{}{"interface": dev, "percent": 30},
+			Check:        checkQdisc(dev, "netem", "corrupt 30%"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+		{
+			Name:   "duplicate",
+			Target: "network", Fault: "duplicate",
+			Args:         map[string]interface{}{"interface": dev, "percent": 30},
+			Check:        checkQdisc(dev, "netem", "duplicate 30%"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+		{
+			Name:   "reorder",
+			Target: "network", Fault: "reorder",
+			Args:         map[string]interface{}{"interface": dev, "latency": "10ms", "gap": 3},
+			Check:        checkQdisc(dev, "netem", "reorder 100%", "gap 3"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func TestNetworkLimit(t *testing.T) {
+	requireSandbox(t)
+	requireQdisc(t, "htb")
+	dev, _ := newVethPair(t, 2)
+
+	var cases = []testCase{
+		{
+			Name:   "limit without rate",
+			Target: "network", Fault: "limit",
+			Args:  map[string]interface{}{"interface": dev},
+			Error: true,
+		},
+		{
+			Name:   "limit",
+			Target: "network", Fault: "limit",
+			Args:         map[string]interface{}{"interface": dev, "rate": "1mbit"},
+			Check:        checkQdisc(dev, "htb"),
+			CheckRecover: checkNoRootQdisc(dev),
+		},
+	}
+
+	runCases(t, cases)
+}
@@ -0,0 +1,302 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"fmt"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/diskio"
+	_ "github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector/process"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/process"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// getProcessState return the state field of /proc/[pid]/stat, eg: S, T, Z
+func getProcessState(pid int) (string, error) {
+	content, err := os.ReadFile(fmt.Sprintf("/proc/%d/stat", pid))
+	if err != nil {
+		return "", err
+	}
+
+	fields := strings.Fields(string(content[strings.LastIndex(string(content), ")")+1:]))
+	if len(fields) == 0 {
+		return "", fmt.Errorf("unexpected stat: %s", string(content))
+	}
+
+	return fields[0], nil
+}
+
+func checkProcessState(pid int, states ...string) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		var state string
+		for retry := 0; retry < 10; retry++ {
+			var err error
+			if state, err = getProcessState(pid); err != nil {
+				return err
+			}
+
+			for _, s := range states {
+				if state == s {
+					return nil
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+		}
+
+		return fmt.Errorf("state of process[%d] is %s, expect: %v", pid, state, states)
+	}
+}
+
+func TestProcess(t *testing.T) {
+	requireSandbox(t)
+
+	stopPid := startProcess(t, "sleep", "1001").Process.Pid
+	stopKeyPid := startProcess(t, "sleep", "1002").Process.Pid
+	killPid := startProcess(t, "sleep", "1003").Process.Pid
+	killKeyPid := startProcess(t, "sleep", "1004").Process.Pid
+
+	var cases = []testCase{
+		{
+			Name:   "stop not exist pid",
+			Target: "process", Fault: "stop",
+			Args:  map[string]interface{}{"pid": 99999},
+			Error: true,
+		},
+		{
+			Name:   "stop by pid",
+			Target: "process", Fault: "stop",
+			Args:         map[string]interface{}{"pid": stopPid},
+			Check:        checkProcessState(stopPid, "T"),
+			CheckRecover: checkProcessState(stopPid, "S", "R"),
+		},
+		{
+			Name:   "stop by key",
+			Target: "process", Fault: "stop",
+			Args:         map[string]interface{}{"key": "sleep 1002"},
+			Check:        checkProcessState(stopKeyPid, "T"),
+			CheckRecover: checkProcessState(stopKeyPid, "S", "R"),
+		},
+		{
+			Name:   "kill by pid",
+			Target: "process", Fault: "kill",
+			Args:  map[string]interface{}{"pid": killPid},
+			Check: checkProcessState(killPid, "Z"),
+		},
+		{
+			Name:   "kill by key with signal",
+			Target: "process", Fault: "kill",
+			Args:  map[string]interface{}{"key": "sleep 1004", "signal": 15},
+			Check: checkProcessState(killKeyPid, "Z"),
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func TestDiskIOLimit(t *testing.T) {
+	requireSandbox(t)
+
+	dev, err := shell("lsblk -a | grep disk | awk '{print $2}' | head -n 1")
+	dev = strings.TrimSpace(dev)
+	if err != nil || dev == "" {
+		t.Skip("no disk device found")
+	}
+
+	pid := startProcess(t, "sleep", "1005").Process.Pid
+	tempCgroup := newCgroup(t, "blkio", pid)
+	tempCgroupName := strings.TrimPrefix(tempCgroup, "/sys/fs/cgroup/blkio")
+
+	checkCgroup := func(key string) func(t *testing.T) error {
+		return func(t *testing.T) error {
+			content, err := os.ReadFile(fmt.Sprintf("/proc/%d/cgroup", pid))
+			if err != nil {
+				return err
+			}
+
+			for _, line := range strings.Split(string(content), "\n") {
+				if strings.Contains(line, ":blkio:") {
+					if !strings.Contains(line, key) {
+						return fmt.Errorf("blkio cgroup of process[%d] is %s, expect %s", pid, line, key)
+					}
+					return nil
+				}
+			}
+
+			return fmt.Errorf("blkio cgroup of process[%d] not found", pid)
+		}
+	}
+
+	var cases = []testCase{
+		{
+			Name:   "limit without bytes",
+			Target: "diskio", Fault: "limit",
+			Args:  map[string]interface{}{"pid_list": fmt.Sprintf("%d", pid), "dev_list": dev},
+			Error: true,
+		},
+		{
+			Name:   "limit read bytes",
+			Target: "diskio", Fault: "limit",
+			Args:         map[string]interface{}{"pid_list": fmt.Sprintf("%d", pid), "dev_list": dev, "read_bytes": "1MB"},
+			Check:        checkCgroup("chaosmeta_blkio"),
+			CheckRecover: checkCgroup(tempCgroupName),
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func checkPriority(pid int, expect process.Priority) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		tidList, err := process.GetThreadList(pid)
+		if err != nil {
+			return err
+		}
+
+		for _, tid := range tidList {
+			p, err := process.GetPriority(tid)
+			if err != nil {
+				return err
+			}
+
+			if *p != expect {
+				return fmt.Errorf("priority of thread[%d] is %+v, expect: %+v", tid, *p, expect)
+			}
+		}
+
+		return nil
+	}
+}
+
+func checkNice(pid int, expect int) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		p, err := process.GetPriority(pid)
+		if err != nil {
+			return err
+		}
+
+		if p.Nice != expect {
+			return fmt.Errorf("nice of process[%d] is %d, expect: %d", pid, p.Nice, expect)
+		}
+
+		return nil
+	}
+}
+
+func TestProcessPriority(t *testing.T) {
+	requireSandbox(t)
+
+	pid := startProcess(t, "sleep", "1006").Process.Pid
+	// start from a non-zero nice, so that setting nice to 0 is covered
+	if err := process.SetNice(pid, 5); err != nil {
+		t.Fatalf("set nice of process[%d] error: %s", pid, err.Error())
+	}
+
+	origin, err := process.GetPriority(pid)
+	if err != nil {
+		t.Fatalf("get priority of process[%d] error: %s", pid, err.Error())
+	}
+
+	var cases = []testCase{
+		{
+			Name:   "priority without args",
+			Target: "process", Fault: "priority",
+			Args:  map[string]interface{}{"pid": pid},
+			Error: true,
+		},
+		{
+			Name:   "priority with invalid nice",
+			Target: "process", Fault: "priority",
+			Args:  map[string]interface{}{"pid": pid, "nice": 20},
+			Error: true,
+		},
+		{
+			Name:   "priority nice and io class",
+			Target: "process", Fault: "priority",
+			Args:         map[string]interface{}{"key": "sleep 1006", "nice": 10, "io_class": "best-effort", "io_level": 6},
+			Check:        checkPriority(pid, process.Priority{Nice: 10, IoClass: process.IoClassBestEffort, IoLevel: 6}),
+			CheckRecover: checkPriority(pid, *origin),
+		},
+		{
+			Name:   "priority nice 0",
+			Target: "process", Fault: "priority",
+			Args:         map[string]interface{}{"pid": pid, "nice": 0},
+			Check:        checkNice(pid, 0),
+			CheckRecover: checkPriority(pid, *origin),
+		},
+		{
+			Name:   "priority idle io class",
+			Target: "process", Fault: "priority",
+			Args:         map[string]interface{}{"pid": pid, "io_class": "idle"},
+			Check:        checkPriority(pid, process.Priority{Nice: origin.Nice, IoClass: process.IoClassIdle}),
+			CheckRecover: checkPriority(pid, *origin),
+		},
+	}
+
+	runCases(t, cases)
+}
+
+func checkAffinity(pid int, expect []int) func(t *testing.T) error {
+	return func(t *testing.T) error {
+		tidList, err := process.GetThreadList(pid)
+		if err != nil {
+			return err
+		}
+
+		for _, tid := range tidList {
+			cpuList, err := process.GetAffinity(tid)
+			if err != nil {
+				return err
+			}
+
+			if fmt.Sprint(cpuList) != fmt.Sprint(expect) {
+				return fmt.Errorf("affinity of thread[%d] is %v, expect: %v", tid, cpuList, expect)
+			}
+		}
+
+		return nil
+	}
+}
+
+func TestProcessCpuset(t *testing.T) {
+	requireSandbox(t)
+
+	pid := startProcess(t, "sleep", "1007").Process.Pid
+	origin, err := process.GetAffinity(pid)
+	if err != nil {
+		t.Fatalf("get affinity of process[%d] error: %s", pid, err.Error())
+	}
+
+	var cases = []testCase{
+		{
+			Name:   "cpuset with offline cpu",
+			Target: "process", Fault: "cpuset",
+			Args:  map[string]interface{}{"pid": pid, "cpu_list": "100000"},
+			Error: true,
+		},
+		{
+			Name:   "cpuset to the first cpu",
+			Target: "process", Fault: "cpuset",
+			Args:         map[string]interface{}{"pid": pid, "cpu_list": fmt.Sprintf("%d", origin[0])},
+			Check:        checkAffinity(pid, origin[:1]),
+			CheckRecover: checkAffinity(pid, origin),
+		},
+	}
+
+	runCases(t, cases)
+}
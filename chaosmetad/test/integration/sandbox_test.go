@@ -0,0 +1,309 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package integration
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/injector"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/cmdexec"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+)
+
+const (
+	sandboxEnv = "CHAOSMETA_TEST_SANDBOX"
+	namePrefix = "cmtest"
+)
+
+var (
+	inSandbox  bool
+	sandboxErr string
+)
+
+type testCase struct {
+	Name         string
+	Target       string
+	Fault        string
+	Args         map[string]interface{}
+	Error        bool
+	Prepare      func(t *testing.T) error
+	Check        func(t *testing.T) error
+	CheckRecover func(t *testing.T) error
+}
+
+func TestMain(m *testing.M) {
+	flag.Parse()
+
+	if os.Getenv(sandboxEnv) == "" {
+		if runtime.GOOS != "linux" || os.Geteuid() != 0 || testing.Short() {
+			sandboxErr = "integration tests need root on linux and not in short mode"
+			os.Exit(m.Run())
+		}
+
+		os.Exit(runInSandbox())
+	}
+
+	if err := setupSandbox(); err != nil {
+		fmt.Fprintf(os.Stderr, "setup sandbox error: %s\n", err.Error())
+		os.Exit(1)
+	}
+	inSandbox = true
+
+	os.Exit(m.Run())
+}
+
+// runInSandbox re-executes the test binary in new namespaces and return its exit code
+func runInSandbox() int {
+	cmd := exec.Command("/proc/self/exe", os.Args[1:]...)
+	cmd.Env = append(os.Environ(), fmt.Sprintf("%s=1", sandboxEnv))
+	cmd.Stdin, cmd.Stdout, cmd.Stderr = os.Stdin, os.Stdout, os.Stderr
+	cmd.SysProcAttr = &syscall.SysProcAttr{
+		Cloneflags: syscall.CLONE_NEWNS | syscall.CLONE_NEWNET | syscall.CLONE_NEWPID,
+		Pdeathsig:  syscall.SIGKILL,
+	}
+
+	if err := cmd.Run(); err != nil {
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitErr.ExitCode()
+		}
+
+		fmt.Fprintf(os.Stderr, "run test in sandbox error: %s\n", err.Error())
+		return 1
+	}
+
+	return 0
+}
+
+// setupSandbox makes the mounts private, mounts a new proc for the pid namespace, brings up lo
+// and replaces the dns config files with temporary copies
+func setupSandbox() error {
+	if err := syscall.Mount("", "/", "", syscall.MS_REC|syscall.MS_PRIVATE, ""); err != nil {
+		return fmt.Errorf("make mount private error: %s", err.Error())
+	}
+
+	if err := syscall.Mount("proc", "/proc", "proc", 0, ""); err != nil {
+		return fmt.Errorf("mount proc error: %s", err.Error())
+	}
+
+	if _, err := shell("ip link set lo up"); err != nil {
+		return err
+	}
+
+	tmpDir, err := os.MkdirTemp("", namePrefix)
+	if err != nil {
+		return fmt.Errorf("create temp dir error: %s", err.Error())
+	}
+
+	for _, file := range []string{"/etc/hosts", "/etc/resolv.conf"} {
+		if err := bindTempCopy(tmpDir, file); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func bindTempCopy(tmpDir, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil && !os.IsNotExist(err) {
+		return fmt.Errorf("read %s error: %s", file, err.Error())
+	}
+
+	if os.IsNotExist(err) {
+		if err := os.WriteFile(file, nil, 0644); err != nil {
+			return fmt.Errorf("create %s error: %s", file, err.Error())
+		}
+	}
+
+	tmpFile := filepath.Join(tmpDir, filepath.Base(file))
+	if err := os.WriteFile(tmpFile, content, 0644); err != nil {
+		return fmt.Errorf("write %s error: %s", tmpFile, err.Error())
+	}
+
+	if err := syscall.Mount(tmpFile, file, "", syscall.MS_BIND, ""); err != nil {
+		return fmt.Errorf("bind %s to %s error: %s", tmpFile, file, err.Error())
+	}
+
+	return nil
+}
+
+func requireSandbox(t *testing.T) {
+	t.Helper()
+	if !inSandbox {
+		t.Skip(sandboxErr)
+	}
+}
+
+func shell(cmd string) (string, error) {
+	return cmdexec.RunBashCmdWithOutput(context.Background(), cmd)
+}
+
+// requireQdisc skips the test if the kernel does not support the qdisc
+func requireQdisc(t *testing.T, qdisc string) {
+	t.Helper()
+	dev := fmt.Sprintf("%s-probe", namePrefix)
+	peer := fmt.Sprintf("%s-probe1", namePrefix)
+	defer shell(fmt.Sprintf("ip link del %s", dev))
+
+	if _, err := shell(fmt.Sprintf("ip link add %s type veth peer name %s && tc qdisc add dev %s root %s", dev, peer, dev, qdisc)); err != nil {
+		t.Skipf("qdisc %s is not supported: %s", qdisc, err.Error())
+	}
+}
+
+// newVethPair creates a veth pair, the peer is moved into a new network namespace.
+// return the local interface and the ip of peer
+func newVethPair(t *testing.T, index int) (string, string) {
+	t.Helper()
+	var (
+		ns      = fmt.Sprintf("%s-ns%d", namePrefix, index)
+		local   = fmt.Sprintf("%s-veth%d", namePrefix, index)
+		peer    = fmt.Sprintf("%s-peer%d", namePrefix, index)
+		localIp = fmt.Sprintf("10.199.%d.1", index)
+		peerIp  = fmt.Sprintf("10.199.%d.2", index)
+	)
+
+	cmd := fmt.Sprintf("ip netns add %s && ip link add %s type veth peer name %s netns %s && "+
+		"ip addr add %s/24 dev %s && ip link set %s up && "+
+		"ip netns exec %s ip addr add %s/24 dev %s && ip netns exec %s ip link set %s up && ip netns exec %s ip link set lo up",
+		ns, local, peer, ns, localIp, local, local, ns, peerIp, peer, ns, peer, ns)
+	if _, err := shell(cmd); err != nil {
+		t.Fatalf("create veth pair error: %s", err.Error())
+	}
+
+	t.Cleanup(func() {
+		_, _ = shell(fmt.Sprintf("ip link del %s; ip netns del %s", local, ns))
+	})
+
+	return local, peerIp
+}
+
+// newCgroup creates a temporary cgroup of subSys and moves pid into it
+func newCgroup(t *testing.T, subSys string, pid int) string {
+	t.Helper()
+	path := fmt.Sprintf("/sys/fs/cgroup/%s/%s-%d", subSys, namePrefix, time.Now().UnixNano())
+	if err := os.Mkdir(path, 0755); err != nil {
+		t.Skipf("create cgroup[%s] error: %s", path, err.Error())
+	}
+
+	if err := os.WriteFile(filepath.Join(path, "cgroup.procs"), []byte(fmt.Sprintf("%d", pid)), 0644); err != nil {
+		_ = os.Remove(path)
+		t.Fatalf("move process[%d] to cgroup[%s] error: %s", pid, path, err.Error())
+	}
+
+	t.Cleanup(func() {
+		_ = os.WriteFile(fmt.Sprintf("/sys/fs/cgroup/%s/cgroup.procs", subSys), []byte(fmt.Sprintf("%d", pid)), 0644)
+		_ = os.Remove(path)
+	})
+
+	return path
+}
+
+// startProcess starts a background process and kills it when test finished
+func startProcess(t *testing.T, name string, args ...string) *exec.Cmd {
+	t.Helper()
+	cmd := exec.Command(name, args...)
+	if err := cmd.Start(); err != nil {
+		t.Fatalf("start process[%s] error: %s", name, err.Error())
+	}
+
+	t.Cleanup(func() {
+		_ = cmd.Process.Kill()
+		_, _ = cmd.Process.Wait()
+	})
+
+	return cmd
+}
+
+// runCases runs Inject -> Check -> Recover -> CheckRecover in-process for each case
+func runCases(t *testing.T, cases []testCase) {
+	for _, c := range cases {
+		c := c
+		t.Run(c.Name, func(t *testing.T) {
+			runCase(t, c)
+		})
+	}
+}
+
+func runCase(t *testing.T, c testCase) {
+	if c.Prepare != nil {
+		if err := c.Prepare(t); err != nil {
+			t.Fatalf("prepare error: %s", err.Error())
+		}
+	}
+
+	ctx := utils.GetCtxWithTraceId(context.Background(), fmt.Sprintf("%s-%s", namePrefix, strings.ReplaceAll(t.Name(), "/", "-")))
+	i, err := injector.NewInjector(c.Target, c.Fault)
+	if err != nil {
+		t.Fatalf("new injector error: %s", err.Error())
+	}
+
+	argsByte, _ := json.Marshal(c.Args)
+	if err := json.Unmarshal(argsByte, i.GetArgs()); err != nil {
+		t.Fatalf("load args error: %s", err.Error())
+	}
+
+	i.SetDefault()
+	err = i.Validator(ctx)
+	if err == nil {
+		if err = i.Inject(ctx); err == nil {
+			i.GetInfo().Status = utils.StatusSuccess
+			t.Cleanup(func() {
+				if i.GetInfo().Status == utils.StatusSuccess {
+					_ = i.Recover(ctx)
+				}
+			})
+		}
+	}
+
+	if c.Error {
+		if err == nil {
+			t.Fatalf("expect error but inject success")
+		}
+		return
+	}
+
+	if err != nil {
+		t.Fatalf("inject error: %s", err.Error())
+	}
+
+	if c.Check != nil {
+		if err := c.Check(t); err != nil {
+			t.Errorf("check inject error: %s", err.Error())
+		}
+	}
+
+	if err := i.Recover(ctx); err != nil {
+		t.Fatalf("recover error: %s", err.Error())
+	}
+	i.GetInfo().Status = utils.StatusDestroyed
+
+	if c.CheckRecover != nil {
+		if err := c.CheckRecover(t); err != nil {
+			t.Errorf("check recover error: %s", err.Error())
+		}
+	}
+}
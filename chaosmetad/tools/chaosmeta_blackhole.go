@@ -0,0 +1,98 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/tools/common"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	modeFail = "fail"
+	modeHang = "hang"
+)
+
+var (
+	holdConns []net.Conn
+	holdMutex sync.Mutex
+)
+
+// [uid] [mode] [ip] [ports] [timeout]
+func main() {
+	args := os.Args
+	if len(args) < 6 {
+		common.ExitWithErr("must provide 5 args: uid、mode、ip、ports、timeout")
+	}
+
+	mode, ip, portsStr, t := args[2], args[3], args[4], args[5]
+	if mode != modeFail && mode != modeHang {
+		common.ExitWithErr(fmt.Sprintf("mode only support: %s、%s", modeFail, modeHang))
+	}
+
+	if net.ParseIP(ip) == nil {
+		common.ExitWithErr(fmt.Sprintf("ip[%s] is invalid", ip))
+	}
+
+	timeout, err := strconv.Atoi(t)
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("timeout value is not a valid int, error: %s", err.Error()))
+	}
+
+	for _, portStr := range strings.Split(portsStr, ",") {
+		port, err := strconv.Atoi(portStr)
+		if err != nil || port <= 0 {
+			common.ExitWithErr(fmt.Sprintf("port[%s] is invalid", portStr))
+		}
+
+		l, err := net.Listen("tcp", net.JoinHostPort(ip, portStr))
+		if err != nil {
+			common.ExitWithErr(fmt.Sprintf("listen on %s:%d error: %s", ip, port, err.Error()))
+		}
+
+		go serve(l, mode)
+	}
+
+	fmt.Println("[success]inject success")
+
+	common.SleepWait(timeout)
+}
+
+// serve fail: reset the connection immediately, hang: accept the connection and never response
+func serve(l net.Listener, mode string) {
+	for {
+		conn, err := l.Accept()
+		if err != nil {
+			continue
+		}
+
+		if mode == modeFail {
+			if tcpConn, ok := conn.(*net.TCPConn); ok {
+				_ = tcpConn.SetLinger(0)
+			}
+			_ = conn.Close()
+		} else {
+			holdMutex.Lock()
+			holdConns = append(holdConns, conn)
+			holdMutex.Unlock()
+		}
+	}
+}
@@ -0,0 +1,104 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/tools/common"
+	"os"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+const (
+	lockTypeFlock     = "flock"
+	lockTypeFcntl     = "fcntl"
+	lockModeShared    = "shared"
+	lockModeExclusive = "exclusive"
+
+	retryInterval = time.Millisecond * 100
+)
+
+// [uid] [path] [type] [mode] [start] [length] [wait] [timeout]
+func main() {
+	args := os.Args
+	if len(args) < 9 {
+		common.ExitWithErr("must provide 8 args: uid、path、type、mode、start、length、wait、timeout")
+	}
+
+	path, lockType, mode := args[2], args[3], args[4]
+	var nums = make([]int64, 4)
+	for i, name := range []string{"start", "length", "wait", "timeout"} {
+		value, err := strconv.ParseInt(args[5+i], 10, 64)
+		if err != nil {
+			common.ExitWithErr(fmt.Sprintf("%s value is not a valid int, error: %s", name, err.Error()))
+		}
+		nums[i] = value
+	}
+	start, length, wait, timeout := nums[0], nums[1], nums[2], nums[3]
+
+	flag := os.O_RDWR
+	if mode == lockModeShared {
+		flag = os.O_RDONLY
+	}
+
+	f, err := os.OpenFile(path, flag, 0)
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("open file[%s] error: %s", path, err.Error()))
+	}
+
+	var lockFunc func() error
+	switch lockType {
+	case lockTypeFlock:
+		how := syscall.LOCK_EX
+		if mode == lockModeShared {
+			how = syscall.LOCK_SH
+		}
+		lockFunc = func() error {
+			return syscall.Flock(int(f.Fd()), how|syscall.LOCK_NB)
+		}
+	case lockTypeFcntl:
+		lk := &syscall.Flock_t{Type: syscall.F_WRLCK, Whence: 0, Start: start, Len: length}
+		if mode == lockModeShared {
+			lk.Type = syscall.F_RDLCK
+		}
+		lockFunc = func() error {
+			return syscall.FcntlFlock(f.Fd(), syscall.F_SETLK, lk)
+		}
+	default:
+		common.ExitWithErr(fmt.Sprintf("not support lock type: %s", lockType))
+	}
+
+	// lock in non-blocking mode and retry until wait seconds, so that the caller will not wait forever
+	deadline := time.Now().Add(time.Second * time.Duration(wait))
+	for {
+		err = lockFunc()
+		if err == nil {
+			break
+		}
+
+		if (err != syscall.EWOULDBLOCK && err != syscall.EAGAIN && err != syscall.EACCES) || time.Now().After(deadline) {
+			common.ExitWithErr(fmt.Sprintf("lock file[%s] error: %s", path, err.Error()))
+		}
+		time.Sleep(retryInterval)
+	}
+
+	fmt.Println("[success]inject success")
+
+	common.SleepWait(int(timeout))
+}
@@ -0,0 +1,82 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/grpcproxy"
+	"github.com/traas-stack/chaosmeta/chaosmetad/tools/common"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+)
+
+// [uid] [listen port] [server port] [rule(base64 json)] [timeout]
+func main() {
+	args := os.Args
+	if len(args) < 6 {
+		common.ExitWithErr("must provide 5 args: uid、listen port、server port、rule、timeout")
+	}
+
+	port := args[2]
+	serverPort, err := strconv.Atoi(args[3])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("server port value is not a valid int, error: %s", err.Error()))
+	}
+
+	timeout, err := strconv.Atoi(args[5])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("timeout value is not a valid int, error: %s", err.Error()))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(args[4])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("decode rule error: %s", err.Error()))
+	}
+
+	var rule grpcproxy.Rule
+	if err := json.Unmarshal(data, &rule); err != nil {
+		common.ExitWithErr(fmt.Sprintf("rule is not a valid json: %s", err.Error()))
+	}
+
+	// listen all the addresses, the redirected connections are accepted by the address of the incoming interface
+	l, err := net.Listen("tcp", fmt.Sprintf(":%s", port))
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("listen error: %s", err.Error()))
+	}
+
+	// the reader of stdout exits after "[success]", nothing is written after it and SIGPIPE is ignored,
+	// otherwise the proxy is killed and the redirect rule is left without proxy
+	signal.Ignore(syscall.SIGPIPE)
+	log.SetOutput(io.Discard)
+	fmt.Println("[success]inject success")
+
+	go func() {
+		s := &http.Server{Handler: grpcproxy.NewHandler(serverPort, &rule), ErrorLog: log.New(io.Discard, "", 0)}
+		_ = s.Serve(l)
+		os.Exit(1)
+	}()
+
+	common.SleepWait(timeout)
+}
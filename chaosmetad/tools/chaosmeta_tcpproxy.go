@@ -0,0 +1,88 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/pkg/utils/tcpproxy"
+	"github.com/traas-stack/chaosmeta/chaosmetad/tools/common"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+)
+
+// [uid] [listen port] [upstream ip:port] [mark] [toxics(base64 json)] [timeout]
+func main() {
+	args := os.Args
+	if len(args) < 7 {
+		common.ExitWithErr("must provide 6 args: uid、listen port、upstream、mark、toxics、timeout")
+	}
+
+	port, upstream := args[2], args[3]
+	mark, err := strconv.ParseUint(args[4], 0, 32)
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("mark value is not a valid int, error: %s", err.Error()))
+	}
+
+	timeout, err := strconv.Atoi(args[6])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("timeout value is not a valid int, error: %s", err.Error()))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(args[5])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("decode toxics error: %s", err.Error()))
+	}
+
+	var t tcpproxy.Toxics
+	if err := json.Unmarshal(data, &t); err != nil {
+		common.ExitWithErr(fmt.Sprintf("toxics is not a valid json: %s", err.Error()))
+	}
+
+	l, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%s", port))
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("listen error: %s", err.Error()))
+	}
+
+	// the connections to upstream are marked, so that they are not redirected to the proxy again
+	dialer := &net.Dialer{
+		Timeout: 10 * time.Second,
+		Control: func(network, address string, c syscall.RawConn) error {
+			var setErr error
+			if err := c.Control(func(fd uintptr) {
+				setErr = syscall.SetsockoptInt(int(fd), syscall.SOL_SOCKET, syscall.SO_MARK, int(mark))
+			}); err != nil {
+				return err
+			}
+			return setErr
+		},
+	}
+
+	// the reader of stdout exits after "[success]", nothing is written after it and SIGPIPE is ignored,
+	// otherwise the proxy is killed and the redirect rule is left without proxy
+	signal.Ignore(syscall.SIGPIPE)
+	fmt.Println("[success]inject success")
+
+	go tcpproxy.Serve(l, dialer, upstream, &t)
+
+	common.SleepWait(timeout)
+}
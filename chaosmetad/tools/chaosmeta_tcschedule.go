@@ -0,0 +1,116 @@
+/*
+ * Copyright 2022-2023 Chaos Meta Authors.
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package main
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"github.com/traas-stack/chaosmeta/chaosmetad/tools/common"
+	"os"
+	"os/exec"
+	"os/signal"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+const valueHolder = "{value}"
+
+type step struct {
+	Offset int64  `json:"offset"`
+	Value  string `json:"value"`
+}
+
+type timeline struct {
+	Commands []string `json:"commands"`
+	Steps    []step   `json:"steps"`
+	Period   int64    `json:"period"`
+}
+
+// [uid] [timeline(base64 json)] [timeout]
+func main() {
+	args := os.Args
+	if len(args) < 4 {
+		common.ExitWithErr("must provide 3 args: uid、timeline、timeout")
+	}
+
+	timeout, err := strconv.Atoi(args[3])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("timeout value is not a valid int, error: %s", err.Error()))
+	}
+
+	data, err := base64.StdEncoding.DecodeString(args[2])
+	if err != nil {
+		common.ExitWithErr(fmt.Sprintf("decode timeline error: %s", err.Error()))
+	}
+
+	var t timeline
+	if err := json.Unmarshal(data, &t); err != nil {
+		common.ExitWithErr(fmt.Sprintf("timeline is not a valid json: %s", err.Error()))
+	}
+
+	if len(t.Commands) == 0 || len(t.Steps) == 0 {
+		common.ExitWithErr("commands and steps of timeline must provide")
+	}
+
+	// the first step is applied before reporting success, so that the schedule is active when inject returns
+	if t.Steps[0].Offset == 0 {
+		if err := apply(t.Commands, t.Steps[0].Value); err != nil {
+			common.ExitWithErr(err.Error())
+		}
+	}
+
+	// the reader of stdout exits after "[success]", nothing is written after it and SIGPIPE is ignored,
+	// otherwise the supervisor is killed and the schedule stops at the current value
+	signal.Ignore(syscall.SIGPIPE)
+	fmt.Println("[success]inject success")
+
+	go run(&t)
+	common.SleepWait(timeout)
+}
+
+func run(t *timeline) {
+	start := time.Now()
+	for cycle := int64(0); ; cycle++ {
+		for i, s := range t.Steps {
+			if cycle == 0 && i == 0 && s.Offset == 0 {
+				continue
+			}
+
+			// a failed step is skipped, the next step applies its value again
+			time.Sleep(time.Until(start.Add(time.Duration(cycle*t.Period+s.Offset) * time.Second)))
+			_ = apply(t.Commands, s.Value)
+		}
+
+		if t.Period <= 0 {
+			return
+		}
+	}
+}
+
+func apply(commands []string, value string) error {
+	for _, c := range commands {
+		c = strings.ReplaceAll(c, valueHolder, value)
+		if out, err := exec.Command("/bin/bash", "-c", c).CombinedOutput(); err != nil {
+			return fmt.Errorf("exec cmd[%s] error: %s, output: %s", c, err.Error(), string(out))
+		}
+	}
+
+	return nil
+}